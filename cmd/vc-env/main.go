@@ -2,6 +2,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -18,7 +19,12 @@ func main() {
 	// Inject version into commands package
 	commands.Version = Version
 
-	args := os.Args[1:]
+	args, offline := extractOfflineFlag(os.Args[1:])
+	if offline {
+		// Propagate via the environment so every package (and any vc-env
+		// subprocess) sees the same setting.
+		os.Setenv("VCENV_OFFLINE", "1")
+	}
 
 	if len(args) == 0 {
 		commands.Help()
@@ -68,18 +74,101 @@ func main() {
 
 	case "install":
 		version := ""
-		silent := false
-		for _, arg := range args[1:] {
+		var pinnedDirs []string
+		from, url, sha256sum, name, source := "", "", "", "", ""
+		silent, allPinned := false, false
+		for i := 1; i < len(args); i++ {
+			arg := args[i]
 			if arg == "-s" || arg == "--silent" {
 				silent = true
+			} else if arg == "--all-pinned" {
+				allPinned = true
 			} else if arg == "-h" || arg == "--help" {
 				commands.InstallHelp()
 				os.Exit(0)
-			} else if version == "" && !strings.HasPrefix(arg, "-") {
-				version = arg
+			} else if v, ok := flagValue(args, &i, "--from"); ok {
+				from = v
+			} else if v, ok := flagValue(args, &i, "--url"); ok {
+				url = v
+			} else if v, ok := flagValue(args, &i, "--sha256"); ok {
+				sha256sum = v
+			} else if v, ok := flagValue(args, &i, "--source"); ok {
+				source = v
+			} else if v, ok := flagValue(args, &i, "--as"); ok {
+				name = v
+			} else if !strings.HasPrefix(arg, "-") {
+				if version == "" {
+					version = arg
+				}
+				pinnedDirs = append(pinnedDirs, arg)
+			}
+		}
+		if name != "" && from == "" && url == "" && source == "" {
+			err = fmt.Errorf("--as requires --from, --url or --source. Usage: vc-env install --from FILE --as NAME")
+		} else if allPinned {
+			err = commands.InstallAllPinned(pinnedDirs, silent)
+		} else if source != "" {
+			err = commands.InstallSource(source, name, silent)
+		} else if from != "" || url != "" {
+			err = commands.InstallFrom(from, url, sha256sum, name, silent)
+		} else {
+			err = commands.Install(version, silent)
+		}
+
+	case "scan":
+		var dirs []string
+		for _, arg := range args[1:] {
+			if arg == "-h" || arg == "--help" {
+				commands.ScanHelp()
+				os.Exit(0)
+			} else if !strings.HasPrefix(arg, "-") {
+				dirs = append(dirs, arg)
+			}
+		}
+		err = commands.Scan(dirs)
+
+	case "check":
+		var paths, platforms []string
+		format := ""
+		for i := 1; i < len(args); i++ {
+			arg := args[i]
+			if arg == "-h" || arg == "--help" {
+				commands.CheckHelp()
+				os.Exit(0)
+			} else if v, ok := flagValue(args, &i, "--format"); ok {
+				format = v
+			} else if v, ok := flagValue(args, &i, "--platform"); ok {
+				platforms = splitList(v)
+			} else if !strings.HasPrefix(arg, "-") {
+				paths = append(paths, arg)
+			}
+		}
+		err = commands.Check(paths, format, platforms)
+
+	case "bump":
+		var paths []string
+		mode, to := commands.BumpLatest, ""
+		recursive, install := false, false
+		for i := 1; i < len(args); i++ {
+			arg := args[i]
+			if arg == "-h" || arg == "--help" {
+				commands.BumpHelp()
+				os.Exit(0)
+			} else if arg == "--patch" {
+				mode = commands.BumpPatch
+			} else if arg == "--minor" {
+				mode = commands.BumpMinor
+			} else if arg == "-r" || arg == "--recursive" {
+				recursive = true
+			} else if arg == "--install" {
+				install = true
+			} else if v, ok := flagValue(args, &i, "--to"); ok {
+				to = v
+			} else if !strings.HasPrefix(arg, "-") {
+				paths = append(paths, arg)
 			}
 		}
-		err = commands.Install(version, silent)
+		err = commands.Bump(paths, mode, to, recursive, install)
 
 	case "uninstall":
 		version := ""
@@ -97,20 +186,109 @@ func main() {
 
 	case "local":
 		version := ""
-		if len(args) > 1 {
-			version = args[1]
+		toolVersions := false
+		for _, arg := range args[1:] {
+			if arg == "--tool-versions" {
+				toolVersions = true
+			} else if version == "" && !strings.HasPrefix(arg, "-") {
+				version = arg
+			}
 		}
-		err = commands.Local(version)
+		err = commands.Local(version, toolVersions)
 
 	case "global":
-		version := ""
-		if len(args) > 1 {
-			version = args[1]
+		version, channelURL := "", ""
+		useChannel, autoInstall := false, false
+		for i := 1; i < len(args); i++ {
+			arg := args[i]
+			if arg == "--auto-install" {
+				autoInstall = true
+			} else if v, ok := flagValue(args, &i, "--channel"); ok {
+				channelURL, useChannel = v, true
+			} else if version == "" && !strings.HasPrefix(arg, "-") {
+				version = arg
+			}
+		}
+		if useChannel {
+			err = commands.GlobalChannel(channelURL, autoInstall)
+		} else {
+			err = commands.Global(version)
 		}
-		err = commands.Global(version)
+
+	case "alias":
+		var positional []string
+		for _, arg := range args[1:] {
+			if arg == "-h" || arg == "--help" {
+				commands.AliasHelp()
+				os.Exit(0)
+			} else if !strings.HasPrefix(arg, "-") {
+				positional = append(positional, arg)
+			}
+		}
+		sub := ""
+		if len(positional) > 0 {
+			sub, positional = positional[0], positional[1:]
+		}
+		arg := func(n int) string {
+			if n < len(positional) {
+				return positional[n]
+			}
+			return ""
+		}
+		switch sub {
+		case "set":
+			err = commands.AliasSet(arg(0), arg(1))
+		case "list":
+			err = commands.AliasList()
+		case "rm":
+			err = commands.AliasRemove(arg(0))
+		default:
+			commands.AliasHelp()
+			os.Exit(1)
+		}
+
+	case "audit":
+		for _, arg := range args[1:] {
+			if arg == "-h" || arg == "--help" {
+				commands.AuditHelp()
+				os.Exit(0)
+			}
+		}
+		err = commands.Audit()
+
+	case "outdated":
+		var paths []string
+		ignorePrerelease := false
+		for _, arg := range args[1:] {
+			if arg == "-h" || arg == "--help" {
+				commands.OutdatedHelp()
+				os.Exit(0)
+			} else if arg == "--ignore-prerelease" {
+				ignorePrerelease = true
+			} else if !strings.HasPrefix(arg, "-") {
+				paths = append(paths, arg)
+			}
+		}
+		err = commands.Outdated(paths, ignorePrerelease)
 
 	case "which":
-		err = commands.Which()
+		if len(args) > 1 && args[1] == "--shim" {
+			err = commands.WhichShim()
+		} else {
+			err = commands.Which()
+		}
+
+	case "resolve":
+		explain := false
+		for _, arg := range args[1:] {
+			if arg == "-h" || arg == "--help" {
+				commands.ResolveHelp()
+				os.Exit(0)
+			} else if arg == "--explain" {
+				explain = true
+			}
+		}
+		err = commands.Resolve(explain)
 
 	case "upgrade":
 		err = commands.Upgrade()
@@ -127,6 +305,117 @@ func main() {
 	case "status":
 		err = commands.Status()
 
+	case "cache":
+		sub := ""
+		releases, downloads := false, false
+		for _, arg := range args[1:] {
+			switch arg {
+			case "-h", "--help":
+				commands.CacheHelp()
+				os.Exit(0)
+			case "--releases":
+				releases = true
+			case "--downloads":
+				downloads = true
+			default:
+				if sub == "" && !strings.HasPrefix(arg, "-") {
+					sub = arg
+				}
+			}
+		}
+		switch sub {
+		case "status":
+			err = commands.CacheStatus()
+		case "clear":
+			err = commands.CacheClear(releases, downloads)
+		default:
+			commands.CacheHelp()
+			os.Exit(1)
+		}
+
+	case "bundle":
+		sub := ""
+		file := ""
+		output := ""
+		var versions, platforms []string
+		for i := 1; i < len(args); i++ {
+			arg := args[i]
+			if arg == "-h" || arg == "--help" {
+				commands.BundleHelp()
+				os.Exit(0)
+			} else if v, ok := flagValue(args, &i, "--versions"); ok {
+				versions = splitList(v)
+			} else if v, ok := flagValue(args, &i, "--platform"); ok {
+				platforms = splitList(v)
+			} else if v, ok := flagValue(args, &i, "-o", "--output"); ok {
+				output = v
+			} else if sub == "" && !strings.HasPrefix(arg, "-") {
+				sub = arg
+			} else if file == "" && !strings.HasPrefix(arg, "-") {
+				file = arg
+			}
+		}
+		switch sub {
+		case "create":
+			err = commands.BundleCreate(versions, platforms, output)
+		case "install":
+			err = commands.BundleInstall(file)
+		default:
+			commands.BundleHelp()
+			os.Exit(1)
+		}
+
+	case "mirror":
+		sub := ""
+		dir, constraint, since, addr := "", "", "", ""
+		var platforms []string
+		includePrerelease := false
+		for i := 1; i < len(args); i++ {
+			arg := args[i]
+			if arg == "-h" || arg == "--help" {
+				commands.MirrorHelp()
+				os.Exit(0)
+			} else if arg == "--prerelease" {
+				includePrerelease = true
+			} else if v, ok := flagValue(args, &i, "--dir"); ok {
+				dir = v
+			} else if v, ok := flagValue(args, &i, "--versions"); ok {
+				constraint = v
+			} else if v, ok := flagValue(args, &i, "--since"); ok {
+				since = v
+			} else if v, ok := flagValue(args, &i, "--addr"); ok {
+				addr = v
+			} else if v, ok := flagValue(args, &i, "--platform"); ok {
+				platforms = splitList(v)
+			} else if sub == "" && !strings.HasPrefix(arg, "-") {
+				sub = arg
+			}
+		}
+		switch sub {
+		case "sync":
+			err = commands.MirrorSync(dir, constraint, since, platforms, includePrerelease)
+		case "serve":
+			err = commands.MirrorServe(dir, addr)
+		default:
+			commands.MirrorHelp()
+			os.Exit(1)
+		}
+
+	case "import":
+		var paths []string
+		removeOriginal := false
+		for _, arg := range args[1:] {
+			if arg == "-h" || arg == "--help" {
+				commands.ImportHelp()
+				os.Exit(0)
+			} else if arg == "--remove-original" {
+				removeOriginal = true
+			} else if !strings.HasPrefix(arg, "-") {
+				paths = append(paths, arg)
+			}
+		}
+		err = commands.Import(paths, removeOriginal)
+
 	case "exec":
 		version := ""
 		execArgs := []string{}
@@ -146,6 +435,66 @@ func main() {
 
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		var exitErr *commands.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		os.Exit(1)
 	}
 }
+
+// extractOfflineFlag removes the global --offline flag from args and reports
+// whether it was present.  The flag may appear before or after the command
+// name; for exec, only occurrences before the command are consumed because
+// the remaining arguments belong to vcluster.
+func extractOfflineFlag(args []string) ([]string, bool) {
+	offline := false
+	for len(args) > 0 && args[0] == "--offline" {
+		offline = true
+		args = args[1:]
+	}
+	if len(args) == 0 || args[0] == "exec" {
+		return args, offline
+	}
+
+	filtered := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "--offline" {
+			offline = true
+			continue
+		}
+		filtered = append(filtered, arg)
+	}
+	return filtered, offline
+}
+
+// flagValue checks whether args[*i] is one of the given flags and returns its
+// value, accepting both "--flag value" and "--flag=value".  When the value is
+// a separate argument, *i is advanced past it.
+func flagValue(args []string, i *int, names ...string) (string, bool) {
+	arg := args[*i]
+	for _, name := range names {
+		if arg == name {
+			if *i+1 < len(args) {
+				*i++
+				return args[*i], true
+			}
+			return "", true
+		}
+		if strings.HasPrefix(arg, name+"=") {
+			return strings.TrimPrefix(arg, name+"="), true
+		}
+	}
+	return "", false
+}
+
+// splitList splits a comma-separated flag value, dropping empty elements.
+func splitList(value string) []string {
+	var out []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,266 @@
+// Package archive extracts the vcluster executable from release assets that
+// are packaged as .tar.gz, .tgz or .zip files.
+//
+// Extraction happens in memory: only the selected executable is returned,
+// nothing is written to disk.  Archives containing entries with absolute
+// paths or ".." components are rejected as a whole, so a crafted archive
+// cannot be used to smuggle files outside the version directory.  Entry and
+// archive sizes are bounded to guard against decompression bombs.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxEntrySize bounds the decompressed size of a single entry.  vcluster
+	// binaries are well below this.
+	maxEntrySize = 1 << 30
+
+	// maxTotalSize bounds the sum of the decompressed sizes of all entries.
+	maxTotalSize = 4 << 30
+)
+
+// File is an executable extracted from an archive.
+type File struct {
+	// Name is the path of the entry inside the archive.
+	Name string
+	// Mode holds the permission bits recorded in the archive.
+	Mode os.FileMode
+	Data []byte
+}
+
+// binaryNames are the entry base names recognised as the vcluster executable
+// when no explicit path is configured.
+var binaryNames = []string{"vcluster", "vcluster.exe"}
+
+// IsArchive reports whether name has an archive extension handled by
+// ExtractBinary.
+func IsArchive(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz") || strings.HasSuffix(lower, ".zip")
+}
+
+// ExtractBinary returns the vcluster executable from the archive name whose
+// content is data.  When binaryPath is set, the entry at that path inside the
+// archive is used; otherwise the shallowest regular file named vcluster (or
+// vcluster.exe) is.  Permission bits are preserved; entries without any
+// executable bit are given 0755.
+func ExtractBinary(name string, data []byte, binaryPath string) (File, error) {
+	var entries []entry
+	var err error
+	switch lower := strings.ToLower(name); {
+	case strings.HasSuffix(lower, ".zip"):
+		entries, err = readZip(data)
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		entries, err = readTarGz(data)
+	default:
+		return File{}, fmt.Errorf("archive: unsupported format %s", name)
+	}
+	if err != nil {
+		return File{}, err
+	}
+
+	e, err := selectBinary(entries, binaryPath)
+	if err != nil {
+		return File{}, fmt.Errorf("archive: %s: %w", name, err)
+	}
+	data, err = e.open()
+	if err != nil {
+		return File{}, fmt.Errorf("archive: failed to read %s from %s: %w", e.name, name, err)
+	}
+
+	mode := e.mode.Perm()
+	if mode&0o111 == 0 {
+		mode = 0o755
+	}
+	return File{Name: e.name, Mode: mode, Data: data}, nil
+}
+
+// entry is a regular file of an archive.  Its content is only decompressed
+// when opened.
+type entry struct {
+	name string
+	mode os.FileMode
+	open func() ([]byte, error)
+}
+
+// selectBinary picks the executable among entries.
+func selectBinary(entries []entry, binaryPath string) (entry, error) {
+	if binaryPath != "" {
+		want, err := cleanName(binaryPath)
+		if err != nil {
+			return entry{}, fmt.Errorf("invalid binary path %q: %w", binaryPath, err)
+		}
+		for _, e := range entries {
+			if e.name == want {
+				return e, nil
+			}
+		}
+		return entry{}, fmt.Errorf("no file %s in archive", want)
+	}
+
+	var candidates []entry
+	for _, e := range entries {
+		for _, n := range binaryNames {
+			if path.Base(e.name) == n {
+				candidates = append(candidates, e)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return entry{}, fmt.Errorf("no vcluster executable in archive; set VCENV_ARCHIVE_BINARY_PATH to its path inside the archive")
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return strings.Count(candidates[i].name, "/") < strings.Count(candidates[j].name, "/")
+	})
+	return candidates[0], nil
+}
+
+// cleanName normalises an entry name and rejects names that would escape the
+// extraction root.
+func cleanName(name string) (string, error) {
+	name = strings.ReplaceAll(name, `\`, "/")
+	if strings.HasPrefix(name, "/") || (len(name) > 1 && name[1] == ':') {
+		return "", fmt.Errorf("absolute path")
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("path escapes the archive root")
+		}
+	}
+	return path.Clean(name), nil
+}
+
+// readTarGz lists the regular files of a gzip-compressed tar archive.  Entry
+// contents are skipped while listing; as tar is sequential, opening an entry
+// decompresses the archive again up to that entry.
+func readTarGz(data []byte) ([]entry, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("archive: invalid gzip stream: %w", err)
+	}
+	defer gz.Close()
+
+	var entries []entry
+	var total int64
+	tr := tar.NewReader(gz)
+	for index := 0; ; index++ {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("archive: invalid tar archive: %w", err)
+		}
+		name, err := cleanName(hdr.Name)
+		if err != nil {
+			return nil, fmt.Errorf("archive: unsafe entry %q: %w", hdr.Name, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if total, err = addSize(total, hdr.Name, hdr.Size); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{
+			name: name,
+			mode: os.FileMode(hdr.Mode),
+			open: func() ([]byte, error) { return readTarEntry(data, index) },
+		})
+	}
+	return entries, nil
+}
+
+// readTarEntry returns the content of the index-th entry of a gzip-compressed
+// tar archive.
+func readTarEntry(data []byte, index int) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for i := 0; i <= index; i++ {
+		if _, err := tr.Next(); err != nil {
+			return nil, err
+		}
+	}
+	return readLimited(tr)
+}
+
+// readZip lists the regular files of a zip archive.
+func readZip(data []byte) ([]entry, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("archive: invalid zip archive: %w", err)
+	}
+
+	var entries []entry
+	var total int64
+	for _, f := range zr.File {
+		name, err := cleanName(f.Name)
+		if err != nil {
+			return nil, fmt.Errorf("archive: unsafe entry %q: %w", f.Name, err)
+		}
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		size := int64(f.UncompressedSize64)
+		if f.UncompressedSize64 > maxTotalSize {
+			size = maxTotalSize + 1
+		}
+		if total, err = addSize(total, f.Name, size); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{
+			name: name,
+			mode: f.Mode(),
+			open: func() ([]byte, error) {
+				rc, err := f.Open()
+				if err != nil {
+					return nil, err
+				}
+				defer rc.Close()
+				return readLimited(rc)
+			},
+		})
+	}
+	return entries, nil
+}
+
+// addSize adds the declared size of entry name to total and fails if either
+// exceeds its limit.
+func addSize(total int64, name string, size int64) (int64, error) {
+	if size > maxEntrySize {
+		return 0, fmt.Errorf("archive: entry %q is too large", name)
+	}
+	total += size
+	if total > maxTotalSize {
+		return 0, fmt.Errorf("archive: archive content is too large")
+	}
+	return total, nil
+}
+
+// readLimited reads r to the end, failing if it holds more than maxEntrySize
+// bytes, whatever size the archive declared.
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxEntrySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxEntrySize {
+		return nil, fmt.Errorf("entry is too large")
+	}
+	return data, nil
+}
@@ -0,0 +1,237 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// testEntry describes an archive entry for the builders below.
+type testEntry struct {
+	name string
+	mode int64
+	data string
+}
+
+func makeTarGz(t *testing.T, entries []testEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: e.mode, Size: int64(len(e.data)), Typeflag: tar.TypeReg}
+		if strings.HasSuffix(e.name, "/") {
+			hdr.Typeflag, hdr.Size = tar.TypeDir, 0
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func makeZip(t *testing.T, entries []testEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		hdr.SetMode(os.FileMode(e.mode))
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(e.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestIsArchive(t *testing.T) {
+	for name, want := range map[string]bool{
+		"vcluster_0.21.1_linux_amd64.tar.gz": true,
+		"vcluster.TGZ":                       true,
+		"vcluster-windows-amd64.zip":         true,
+		"vcluster-linux-amd64":               false,
+		"checksums.txt":                      false,
+	} {
+		if got := IsArchive(name); got != want {
+			t.Errorf("IsArchive(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestExtractBinary_TarGz(t *testing.T) {
+	data := makeTarGz(t, []testEntry{
+		{name: "./"},
+		{name: "./LICENSE", mode: 0o644, data: "license"},
+		{name: "./bin/tools/vcluster", mode: 0o755, data: "nested"},
+		{name: "./vcluster", mode: 0o750, data: "top-level binary"},
+	})
+
+	f, err := ExtractBinary("vcluster.tar.gz", data, "")
+	if err != nil {
+		t.Fatalf("ExtractBinary: %v", err)
+	}
+	if f.Name != "vcluster" || string(f.Data) != "top-level binary" {
+		t.Fatalf("expected the shallowest vcluster entry, got %s %q", f.Name, f.Data)
+	}
+	if f.Mode != 0o750 {
+		t.Fatalf("expected mode 0750 to be preserved, got %o", f.Mode)
+	}
+
+	f, err = ExtractBinary("vcluster.tgz", data, "bin/tools/vcluster")
+	if err != nil {
+		t.Fatalf("ExtractBinary with path: %v", err)
+	}
+	if string(f.Data) != "nested" {
+		t.Fatalf("expected configured entry, got %q", f.Data)
+	}
+}
+
+func TestExtractBinary_Zip(t *testing.T) {
+	data := makeZip(t, []testEntry{
+		{name: "README.md", mode: 0o644, data: "readme"},
+		{name: "vcluster-1.0/vcluster.exe", mode: 0o644, data: "windows binary"},
+	})
+
+	f, err := ExtractBinary("vcluster.zip", data, "")
+	if err != nil {
+		t.Fatalf("ExtractBinary: %v", err)
+	}
+	if string(f.Data) != "windows binary" {
+		t.Fatalf("unexpected content %q", f.Data)
+	}
+	if f.Mode != 0o755 {
+		t.Fatalf("expected non-executable entry to get 0755, got %o", f.Mode)
+	}
+}
+
+// makeOversizedTarGz returns a tar.gz whose vcluster entry declares size
+// bytes.  Only the header is written, so the archive stays small.
+func makeOversizedTarGz(t *testing.T, size int64) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: "vcluster", Mode: 0o755, Size: size, Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// makeOversizedZip returns a zip with count entries that each declare size
+// uncompressed bytes but hold no data.
+func makeOversizedZip(t *testing.T, count int, size uint64) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i := range count {
+		hdr := &zip.FileHeader{Name: fmt.Sprintf("bin%d/vcluster", i), Method: zip.Store, UncompressedSize64: size}
+		hdr.SetMode(0o755)
+		if _, err := zw.CreateRaw(hdr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractBinary_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		archive    string
+		data       []byte
+		binaryPath string
+		wantErr    string
+	}{
+		{
+			name:    "path traversal",
+			archive: "evil.tar.gz",
+			data:    makeTarGz(t, []testEntry{{name: "../../etc/passwd", mode: 0o644, data: "x"}, {name: "vcluster", mode: 0o755, data: "ok"}}),
+			wantErr: "unsafe entry",
+		},
+		{
+			name:    "absolute path in zip",
+			archive: "evil.zip",
+			data:    makeZip(t, []testEntry{{name: "/usr/local/bin/vcluster", mode: 0o755, data: "x"}}),
+			wantErr: "unsafe entry",
+		},
+		{
+			name:    "no binary",
+			archive: "empty.tar.gz",
+			data:    makeTarGz(t, []testEntry{{name: "README.md", mode: 0o644, data: "x"}}),
+			wantErr: "VCENV_ARCHIVE_BINARY_PATH",
+		},
+		{
+			name:       "configured path missing",
+			archive:    "vcluster.zip",
+			data:       makeZip(t, []testEntry{{name: "vcluster", mode: 0o755, data: "x"}}),
+			binaryPath: "bin/vcluster",
+			wantErr:    "no file bin/vcluster",
+		},
+		{
+			name:       "configured path escapes",
+			archive:    "vcluster.zip",
+			data:       makeZip(t, []testEntry{{name: "vcluster", mode: 0o755, data: "x"}}),
+			binaryPath: "../vcluster",
+			wantErr:    "invalid binary path",
+		},
+		{
+			name:    "oversized tar entry",
+			archive: "vcluster.tar.gz",
+			data:    makeOversizedTarGz(t, maxEntrySize+1),
+			wantErr: "too large",
+		},
+		{
+			name:    "oversized zip entry",
+			archive: "vcluster.zip",
+			data:    makeOversizedZip(t, 1, 1<<62),
+			wantErr: "too large",
+		},
+		{
+			name:    "oversized zip archive",
+			archive: "vcluster.zip",
+			data:    makeOversizedZip(t, 5, maxEntrySize),
+			wantErr: "archive content is too large",
+		},
+		{
+			name:    "corrupt gzip",
+			archive: "vcluster.tar.gz",
+			data:    []byte("not gzip"),
+			wantErr: "invalid gzip",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ExtractBinary(tt.archive, tt.data, tt.binaryPath)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+// Package bundle reads and writes offline bundles: single tar.gz archives
+// that carry vcluster binaries, their release checksums, a manifest and a
+// snapshot of the release list, so that airgapped machines can install
+// versions with the same integrity checks as a network install.
+//
+// # Layout
+//
+//	manifest.json
+//	artifacts/<version>/<os>-<arch>/<asset name>
+//	artifacts/<version>/<os>-<arch>/checksums.txt
+//	cache/releases.json
+//
+// Every artifact is recorded in the manifest with its SHA-256 digest, and is
+// additionally checked against the checksums.txt bundled with it, which is
+// kept per platform as the checksums an artifact was verified against may
+// differ between platforms.  Read rejects a bundle if either check fails.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/github"
+	"github.com/user/vc-env/internal/platform"
+	"github.com/user/vc-env/internal/semver"
+)
+
+const (
+	// FormatVersion is the bundle format written by this version of vc-env.
+	FormatVersion = 1
+
+	manifestPath = "manifest.json"
+	releasesPath = "cache/releases.json"
+
+	// maxEntrySize bounds a single archive member to guard against
+	// decompression bombs.  vcluster binaries are well below this.
+	maxEntrySize = 1 << 30
+)
+
+// Item is one vcluster binary for one platform.
+type Item struct {
+	Version   string
+	Platform  platform.Info
+	Name      string
+	Data      []byte
+	Checksums []byte
+}
+
+// Releases is the release-list snapshot stored in a bundle.
+type Releases struct {
+	FetchedAt          time.Time `json:"fetched_at"`
+	Versions           []string  `json:"versions"`
+	PrereleaseVersions []string  `json:"prerelease_versions"`
+}
+
+// Manifest describes the content of a bundle.
+type Manifest struct {
+	FormatVersion int             `json:"format_version"`
+	CreatedAt     time.Time       `json:"created_at"`
+	CreatedBy     string          `json:"created_by"`
+	Artifacts     []ManifestEntry `json:"artifacts"`
+}
+
+// ManifestEntry records a single artifact in the manifest.
+type ManifestEntry struct {
+	Version string `json:"version"`
+	OS      string `json:"os"`
+	Arch    string `json:"arch"`
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Digest  string `json:"digest"`
+	Size    int64  `json:"size"`
+}
+
+// Contents is a verified, fully read bundle.
+type Contents struct {
+	Manifest Manifest
+	Items    []Item
+	Releases *Releases
+}
+
+// artifactPath returns the archive path of an item.
+func artifactPath(version string, info platform.Info, name string) string {
+	return path.Join("artifacts", version, platform.Key(info), name)
+}
+
+// checksumsPath returns the archive path of the checksums.txt of an item.
+func checksumsPath(version string, info platform.Info) string {
+	return path.Join("artifacts", version, platform.Key(info), "checksums.txt")
+}
+
+// legacyChecksumsPath returns the archive path of the single checksums.txt
+// per version written by earlier versions of vc-env.
+func legacyChecksumsPath(version string) string {
+	return path.Join("artifacts", version, "checksums.txt")
+}
+
+// Write writes a bundle containing items and the optional release snapshot
+// to w.  Every item must verify against its own checksums.
+func Write(w io.Writer, items []Item, releases *Releases, createdBy string) error {
+	manifest := Manifest{
+		FormatVersion: FormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		CreatedBy:     createdBy,
+	}
+	files := map[string][]byte{}
+
+	for _, it := range items {
+		if err := verifyChecksum(it); err != nil {
+			return err
+		}
+		p := artifactPath(it.Version, it.Platform, it.Name)
+		files[p] = it.Data
+		files[checksumsPath(it.Version, it.Platform)] = it.Checksums
+		manifest.Artifacts = append(manifest.Artifacts, ManifestEntry{
+			Version: it.Version,
+			OS:      it.Platform.OS,
+			Arch:    it.Platform.Arch,
+			Name:    it.Name,
+			Path:    p,
+			Digest:  cache.Digest(it.Data),
+			Size:    int64(len(it.Data)),
+		})
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("bundle: failed to marshal manifest: %w", err)
+	}
+	files[manifestPath] = manifestData
+
+	if releases != nil {
+		data, err := json.MarshalIndent(releases, "", "  ")
+		if err != nil {
+			return fmt.Errorf("bundle: failed to marshal release snapshot: %w", err)
+		}
+		files[releasesPath] = data
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	// Write the manifest first so readers can inspect it cheaply, then the
+	// remaining files in a stable order for reproducible archives.
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if name != manifestPath {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	names = append([]string{manifestPath}, names...)
+
+	for _, name := range names {
+		data := files[name]
+		mode := int64(0o644)
+		if strings.HasPrefix(name, "artifacts/") && !strings.HasSuffix(name, "checksums.txt") {
+			mode = 0o755
+		}
+		hdr := &tar.Header{
+			Name:    name,
+			Mode:    mode,
+			Size:    int64(len(data)),
+			ModTime: manifest.CreatedAt,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("bundle: failed to write %s: %w", name, err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("bundle: failed to write %s: %w", name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("bundle: failed to finalize archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("bundle: failed to finalize archive: %w", err)
+	}
+	return nil
+}
+
+// Read reads and verifies a bundle.  It fails if the archive contains
+// unexpected or unsafe paths, if an artifact's digest differs from the
+// manifest, or if an artifact does not match its bundled checksums.txt.
+func Read(r io.Reader) (*Contents, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("bundle: not a gzip archive: %w", err)
+	}
+	defer gz.Close()
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("bundle: failed to read archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("bundle: unexpected entry %q", hdr.Name)
+		}
+		name := path.Clean(hdr.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("bundle: unsafe path %q", hdr.Name)
+		}
+		if hdr.Size > maxEntrySize {
+			return nil, fmt.Errorf("bundle: entry %q is too large", hdr.Name)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxEntrySize))
+		if err != nil {
+			return nil, fmt.Errorf("bundle: failed to read %s: %w", name, err)
+		}
+		files[name] = data
+	}
+
+	manifestData, ok := files[manifestPath]
+	if !ok {
+		return nil, fmt.Errorf("bundle: manifest.json is missing")
+	}
+	var contents Contents
+	if err := json.Unmarshal(manifestData, &contents.Manifest); err != nil {
+		return nil, fmt.Errorf("bundle: invalid manifest: %w", err)
+	}
+	if contents.Manifest.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("bundle: unsupported format version %d", contents.Manifest.FormatVersion)
+	}
+
+	for _, e := range contents.Manifest.Artifacts {
+		// The version, platform and name become paths under $VCENV_ROOT on
+		// install, so they are validated before any path is built.
+		if !semver.IsValid(e.Version) {
+			return nil, fmt.Errorf("bundle: manifest entry has invalid version %q", e.Version)
+		}
+		info, err := platform.Parse(e.OS + "/" + e.Arch)
+		if err != nil {
+			return nil, fmt.Errorf("bundle: manifest entry for %s has invalid platform: %w", e.Version, err)
+		}
+		if e.Name == "" || e.Name == "." || e.Name == ".." || strings.ContainsAny(e.Name, `/\`) {
+			return nil, fmt.Errorf("bundle: manifest entry for %s has invalid name %q", e.Version, e.Name)
+		}
+		if e.Path != artifactPath(e.Version, info, e.Name) {
+			return nil, fmt.Errorf("bundle: manifest entry for %s %s has unexpected path %q", e.Version, platform.Key(info), e.Path)
+		}
+		data, ok := files[e.Path]
+		if !ok {
+			return nil, fmt.Errorf("bundle: %s is listed in the manifest but missing", e.Path)
+		}
+		if digest := cache.Digest(data); digest != e.Digest {
+			return nil, fmt.Errorf("bundle: digest mismatch for %s: manifest %s, content %s", e.Path, e.Digest, digest)
+		}
+		checksums, ok := files[checksumsPath(e.Version, info)]
+		if !ok {
+			checksums = files[legacyChecksumsPath(e.Version)]
+		}
+		it := Item{
+			Version:   e.Version,
+			Platform:  info,
+			Name:      e.Name,
+			Data:      data,
+			Checksums: checksums,
+		}
+		if err := verifyChecksum(it); err != nil {
+			return nil, err
+		}
+		contents.Items = append(contents.Items, it)
+	}
+
+	if data, ok := files[releasesPath]; ok {
+		var rel Releases
+		if err := json.Unmarshal(data, &rel); err != nil {
+			return nil, fmt.Errorf("bundle: invalid release snapshot: %w", err)
+		}
+		contents.Releases = &rel
+	}
+
+	return &contents, nil
+}
+
+// verifyChecksum checks an item against the upstream checksums.txt.
+func verifyChecksum(it Item) error {
+	if len(it.Checksums) == 0 {
+		return fmt.Errorf("bundle: no checksums available for vcluster %s %s", it.Version, platform.Key(it.Platform))
+	}
+	expected, err := github.FindChecksum(string(it.Checksums), it.Name)
+	if err != nil {
+		return fmt.Errorf("bundle: vcluster %s: %w", it.Version, err)
+	}
+	actual := cache.SHA256(it.Data)
+	if _, err := hex.DecodeString(expected); err != nil || !strings.EqualFold(expected, actual) {
+		return fmt.Errorf("bundle: checksum mismatch for vcluster %s %s: expected %s, got %s", it.Version, platform.Key(it.Platform), expected, actual)
+	}
+	return nil
+}
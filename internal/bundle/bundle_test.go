@@ -0,0 +1,224 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/user/vc-env/internal/platform"
+)
+
+// newItem returns an item whose checksums.txt matches its data.
+func newItem(version string, info platform.Info, data string) Item {
+	name := platform.BinaryName(info)
+	sum := sha256.Sum256([]byte(data))
+	return Item{
+		Version:   version,
+		Platform:  info,
+		Name:      name,
+		Data:      []byte(data),
+		Checksums: []byte(fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), name)),
+	}
+}
+
+func TestWriteRead_RoundTrip(t *testing.T) {
+	items := []Item{
+		newItem("0.21.1", platform.Info{OS: "linux", Arch: "amd64"}, "linux binary"),
+		newItem("0.21.1", platform.Info{OS: "darwin", Arch: "arm64"}, "darwin binary"),
+	}
+	// Both platforms share one checksums.txt upstream.
+	items[0].Checksums = append(items[0].Checksums, items[1].Checksums...)
+	items[1].Checksums = items[0].Checksums
+
+	releases := &Releases{Versions: []string{"0.21.1"}, PrereleaseVersions: []string{"0.21.1"}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, items, releases, "test"); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	contents, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(contents.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(contents.Items))
+	}
+	if string(contents.Items[0].Data) != "linux binary" {
+		t.Fatalf("unexpected item data %q", contents.Items[0].Data)
+	}
+	if contents.Releases == nil || contents.Releases.Versions[0] != "0.21.1" {
+		t.Fatalf("expected release snapshot, got %+v", contents.Releases)
+	}
+	if contents.Manifest.CreatedBy != "test" {
+		t.Fatalf("expected created_by test, got %q", contents.Manifest.CreatedBy)
+	}
+}
+
+func TestWriteRead_PerPlatformChecksums(t *testing.T) {
+	// Each item only carries the checksum of its own binary, as when it
+	// was verified against the API digest alone.
+	items := []Item{
+		newItem("0.21.1", platform.Info{OS: "linux", Arch: "amd64"}, "amd64 binary"),
+		newItem("0.21.1", platform.Info{OS: "linux", Arch: "arm64"}, "arm64 binary"),
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, items, nil, "test"); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	contents, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(contents.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(contents.Items))
+	}
+	for i, it := range contents.Items {
+		if string(it.Checksums) != string(items[i].Checksums) {
+			t.Fatalf("unexpected checksums for %s: %q", it.Name, it.Checksums)
+		}
+	}
+}
+
+func TestRead_LegacyChecksums(t *testing.T) {
+	var buf bytes.Buffer
+	it := newItem("0.21.1", platform.Info{OS: "linux", Arch: "amd64"}, "binary")
+	if err := Write(&buf, []Item{it}, nil, "test"); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	legacy := rewrite(t, buf.Bytes(), func(hdr *tar.Header, data []byte) []byte {
+		if strings.HasSuffix(hdr.Name, "checksums.txt") {
+			hdr.Name = legacyChecksumsPath(it.Version)
+		}
+		return data
+	})
+	if _, err := Read(bytes.NewReader(legacy)); err != nil {
+		t.Fatalf("expected a bundle with one checksums.txt per version to be read, got %v", err)
+	}
+}
+
+func TestWrite_RejectsUnverifiedItem(t *testing.T) {
+	it := newItem("0.21.1", platform.Info{OS: "linux", Arch: "amd64"}, "binary")
+	it.Data = []byte("tampered")
+
+	var buf bytes.Buffer
+	if err := Write(&buf, []Item{it}, nil, "test"); err == nil {
+		t.Fatal("expected checksum error")
+	}
+}
+
+// rewrite re-packs a bundle, applying fn to every entry.
+func rewrite(t *testing.T, src []byte, fn func(hdr *tar.Header, data []byte) []byte) []byte {
+	t.Helper()
+	gr, err := gzip.NewReader(bytes.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gr)
+
+	var out bytes.Buffer
+	gw := gzip.NewWriter(&out)
+	tw := tar.NewWriter(gw)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		var data bytes.Buffer
+		_, _ = data.ReadFrom(tr)
+		b := fn(hdr, data.Bytes())
+		hdr.Size = int64(len(b))
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = tw.Write(b)
+	}
+	_ = tw.Close()
+	_ = gw.Close()
+	return out.Bytes()
+}
+
+func TestRead_DetectsTampering(t *testing.T) {
+	var buf bytes.Buffer
+	it := newItem("0.21.1", platform.Info{OS: "linux", Arch: "amd64"}, "binary")
+	if err := Write(&buf, []Item{it}, nil, "test"); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	tampered := rewrite(t, buf.Bytes(), func(hdr *tar.Header, data []byte) []byte {
+		if strings.HasSuffix(hdr.Name, "vcluster-linux-amd64") {
+			return []byte("evil")
+		}
+		return data
+	})
+
+	_, err := Read(bytes.NewReader(tampered))
+	if err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Fatalf("expected digest mismatch, got %v", err)
+	}
+}
+
+func TestRead_RejectsUnsafePaths(t *testing.T) {
+	var buf bytes.Buffer
+	it := newItem("0.21.1", platform.Info{OS: "linux", Arch: "amd64"}, "binary")
+	if err := Write(&buf, []Item{it}, nil, "test"); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	unsafe := rewrite(t, buf.Bytes(), func(hdr *tar.Header, data []byte) []byte {
+		if hdr.Name == "artifacts/0.21.1/linux-amd64/checksums.txt" {
+			hdr.Name = "../../etc/passwd"
+		}
+		return data
+	})
+
+	_, err := Read(bytes.NewReader(unsafe))
+	if err == nil || !strings.Contains(err.Error(), "unsafe path") {
+		t.Fatalf("expected unsafe path error, got %v", err)
+	}
+}
+
+func TestRead_RejectsUnsafeVersions(t *testing.T) {
+	var buf bytes.Buffer
+	it := newItem("0.21.1", platform.Info{OS: "linux", Arch: "amd64"}, "binary")
+	if err := Write(&buf, []Item{it}, nil, "test"); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	// The crafted version keeps every archive path consistent with the
+	// manifest, but would install into $VCENV_ROOT/cache.
+	const version = "x/../../cache"
+	malicious := rewrite(t, buf.Bytes(), func(hdr *tar.Header, data []byte) []byte {
+		switch {
+		case hdr.Name == "manifest.json":
+			var m Manifest
+			if err := json.Unmarshal(data, &m); err != nil {
+				t.Fatal(err)
+			}
+			m.Artifacts[0].Version = version
+			m.Artifacts[0].Path = artifactPath(version, it.Platform, it.Name)
+			data, _ = json.Marshal(m)
+		case strings.HasSuffix(hdr.Name, "checksums.txt"):
+			hdr.Name = checksumsPath(version, it.Platform)
+		case strings.HasSuffix(hdr.Name, it.Name):
+			hdr.Name = artifactPath(version, it.Platform, it.Name)
+		}
+		return data
+	})
+	if p := artifactPath(version, it.Platform, it.Name); path.IsAbs(p) || strings.HasPrefix(p, "../") {
+		t.Fatalf("test bundle must pass the archive path checks, got %q", p)
+	}
+
+	_, err := Read(bytes.NewReader(malicious))
+	if err == nil || !strings.Contains(err.Error(), "invalid version") {
+		t.Fatalf("expected an invalid version error, got %v", err)
+	}
+}
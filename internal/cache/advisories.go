@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// advisoriesFileName is the name of the JSON file stored under
+// $VCENV_ROOT/cache/ that holds the security advisories of vcluster.
+const advisoriesFileName = "advisories.json"
+
+// advisoriesEntry is the on-disk JSON structure for the advisories file.
+type advisoriesEntry struct {
+	// FetchedAt is the UTC timestamp of the last successful fetch.
+	FetchedAt time.Time `json:"fetched_at"`
+
+	// Advisories holds the published advisories as returned by the GitHub
+	// API.  The cache does not interpret them.
+	Advisories json.RawMessage `json:"advisories"`
+}
+
+// AdvisoryCache manages the on-disk security advisory cache.  It follows the
+// release cache: entries are fresh for the same TTL (VCENV_CACHE_TTL), and a
+// stale entry remains available as a fallback.
+type AdvisoryCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// NewAdvisoryCache creates an AdvisoryCache that stores its file in dir.  If
+// dir is empty nothing is persisted.
+func NewAdvisoryCache(dir string) *AdvisoryCache {
+	return &AdvisoryCache{dir: dir, ttl: parseTTL()}
+}
+
+// path returns the full path to the cache file.
+func (c *AdvisoryCache) path() string {
+	return filepath.Join(c.dir, advisoriesFileName)
+}
+
+// Load returns the cached advisories, as JSON, regardless of their age, and
+// whether they are still fresh.  ok is false when there is no usable cache.
+func (c *AdvisoryCache) Load() (advisories json.RawMessage, fresh bool, ok bool) {
+	if c.dir == "" {
+		return nil, false, false
+	}
+	data, err := os.ReadFile(c.path())
+	if err != nil {
+		return nil, false, false
+	}
+	var e advisoriesEntry
+	if err := json.Unmarshal(data, &e); err != nil || len(e.Advisories) == 0 {
+		return nil, false, false
+	}
+	return e.Advisories, time.Since(e.FetchedAt) <= c.ttl, true
+}
+
+// Save writes the advisories, a JSON document, to the disk cache
+// atomically.
+func (c *AdvisoryCache) Save(advisories json.RawMessage) error {
+	if c.dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("cache: failed to create directory %s: %w", c.dir, err)
+	}
+	if len(advisories) == 0 {
+		advisories = json.RawMessage("[]")
+	}
+	data, err := json.MarshalIndent(advisoriesEntry{FetchedAt: time.Now().UTC(), Advisories: advisories}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cache: failed to marshal advisories: %w", err)
+	}
+	return WriteFileAtomic(c.path(), data, 0o644)
+}
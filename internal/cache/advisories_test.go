@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAdvisoryCache(t *testing.T) {
+	t.Setenv("VCENV_CACHE_TTL", "1h")
+	dir := t.TempDir()
+	c := NewAdvisoryCache(dir)
+	if _, _, ok := c.Load(); ok {
+		t.Fatal("expected a miss before the first save")
+	}
+	if err := c.Save(json.RawMessage(`[{"ghsa_id":"GHSA-1"}]`)); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	data, fresh, ok := c.Load()
+	var advisories []map[string]string
+	if !ok || !fresh || json.Unmarshal(data, &advisories) != nil || len(advisories) != 1 || advisories[0]["ghsa_id"] != "GHSA-1" {
+		t.Fatalf("unexpected load %s fresh=%v ok=%v", data, fresh, ok)
+	}
+
+	t.Setenv("VCENV_CACHE_TTL", "0s")
+	if _, fresh, ok := NewAdvisoryCache(dir).Load(); !ok || fresh {
+		t.Fatalf("expected a stale entry, got fresh=%v ok=%v", fresh, ok)
+	}
+
+	if err := NewAdvisoryCache("").Save(nil); err != nil {
+		t.Fatalf("Save without a directory: %v", err)
+	}
+}
@@ -28,6 +28,12 @@
 //   - The age of the cache exceeds the TTL (default 1 h, overridable via
 //     the VCENV_CACHE_TTL environment variable).
 //
+// # Network state
+//
+// network.go additionally records consecutive connection failures in
+// $VCENV_ROOT/cache/network.json so that vc-env can switch to offline mode
+// automatically instead of waiting for a timeout on every invocation.
+//
 // # Thread safety
 //
 // Each vc-env invocation is a separate OS process, so no in-process mutex is
@@ -92,8 +98,7 @@ func New(dir string) *Cache {
 }
 
 // NewWithTTL creates a Cache with an explicit TTL, bypassing the environment
-// variable.  This is primarily useful in tests and for the stale-cache reader
-// pattern (pass a very large TTL to read regardless of age).
+// variable.  This is primarily useful in tests.
 func NewWithTTL(dir string, ttl time.Duration) *Cache {
 	return &Cache{dir: dir, ttl: ttl}
 }
@@ -128,6 +133,18 @@ func (c *Cache) path() string {
 //
 // The returned slices are copies; the caller may modify them freely.
 func (c *Cache) Load() (versions []string, prereleaseVersions []string, ok bool) {
+	return c.load(false)
+}
+
+// LoadStale is like Load but ignores the TTL: it returns the stored version
+// lists if the cache file exists and is parseable, regardless of its age.
+func (c *Cache) LoadStale() (versions []string, prereleaseVersions []string, ok bool) {
+	return c.load(true)
+}
+
+// load reads the cache file, treating it as a miss when it is stale unless
+// anyAge is set.
+func (c *Cache) load(anyAge bool) (versions []string, prereleaseVersions []string, ok bool) {
 	if c.dir == "" {
 		return nil, nil, false
 	}
@@ -145,7 +162,7 @@ func (c *Cache) Load() (versions []string, prereleaseVersions []string, ok bool)
 		return nil, nil, false
 	}
 
-	if time.Since(e.FetchedAt) > c.ttl {
+	if !anyAge && time.Since(e.FetchedAt) > c.ttl {
 		// Cache is stale.
 		return nil, nil, false
 	}
@@ -164,6 +181,13 @@ func (c *Cache) Load() (versions []string, prereleaseVersions []string, ok bool)
 // Errors are non-fatal: a failed save means the next invocation will simply
 // perform another fetch.
 func (c *Cache) Save(versions []string, prereleaseVersions []string) error {
+	return c.SaveFetchedAt(versions, prereleaseVersions, time.Now().UTC())
+}
+
+// SaveFetchedAt is like Save but records fetchedAt instead of the current
+// time.  It is used when importing a snapshot taken elsewhere so that the
+// imported data ages (and is refreshed) like any other cache entry.
+func (c *Cache) SaveFetchedAt(versions []string, prereleaseVersions []string, fetchedAt time.Time) error {
 	if c.dir == "" {
 		return nil
 	}
@@ -173,7 +197,7 @@ func (c *Cache) Save(versions []string, prereleaseVersions []string) error {
 	}
 
 	e := entry{
-		FetchedAt:          time.Now().UTC(),
+		FetchedAt:          fetchedAt.UTC(),
 		Versions:           versions,
 		PrereleaseVersions: prereleaseVersions,
 	}
@@ -183,29 +207,75 @@ func (c *Cache) Save(versions []string, prereleaseVersions []string) error {
 		return fmt.Errorf("cache: failed to marshal entry: %w", err)
 	}
 
-	// Write to a temp file in the same directory, then rename for atomicity.
-	tmp, err := os.CreateTemp(c.dir, ".releases-*.json.tmp")
+	return WriteFileAtomic(c.path(), data, 0o644)
+}
+
+// WriteFileAtomic writes data to path via a temp file in the same directory
+// and a rename, creating parent directories as needed.  The rename is atomic
+// on POSIX systems, so concurrent readers either see the previous content or
+// the new content, never a partial write.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
 	if err != nil {
-		return fmt.Errorf("cache: failed to create temp file: %w", err)
+		return fmt.Errorf("failed to create temp file: %w", err)
 	}
 	tmpName := tmp.Name()
 
 	if _, err := tmp.Write(data); err != nil {
 		_ = tmp.Close()
 		_ = os.Remove(tmpName)
-		return fmt.Errorf("cache: failed to write temp file: %w", err)
+		return fmt.Errorf("failed to write %s: %w", path, err)
 	}
 	if err := tmp.Close(); err != nil {
 		_ = os.Remove(tmpName)
-		return fmt.Errorf("cache: failed to close temp file: %w", err)
+		return fmt.Errorf("failed to write %s: %w", path, err)
 	}
-
-	// Atomic rename — on POSIX this is guaranteed to be atomic.
-	if err := os.Rename(tmpName, c.path()); err != nil {
+	if err := os.Chmod(tmpName, perm); err != nil {
 		_ = os.Remove(tmpName)
-		return fmt.Errorf("cache: failed to rename temp file: %w", err)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// TTL returns the maximum age of a cache entry before it is considered stale.
+func (c *Cache) TTL() time.Duration {
+	return c.ttl
+}
+
+// FetchedAt returns the timestamp of the last successful fetch recorded in
+// the cache file, regardless of the TTL.  It returns false when the cache is
+// missing or corrupt.
+func (c *Cache) FetchedAt() (time.Time, bool) {
+	if c.dir == "" {
+		return time.Time{}, false
+	}
+	data, err := os.ReadFile(c.path())
+	if err != nil {
+		return time.Time{}, false
 	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return time.Time{}, false
+	}
+	return e.FetchedAt, true
+}
 
+// Clear removes the cache file.  A missing file is not an error.
+func (c *Cache) Clear() error {
+	if c.dir == "" {
+		return nil
+	}
+	if err := os.Remove(c.path()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("cache: failed to remove %s: %w", c.path(), err)
+	}
 	return nil
 }
 
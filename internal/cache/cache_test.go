@@ -77,6 +77,23 @@ func TestLoad_StaleCache(t *testing.T) {
 	}
 }
 
+func TestLoadStale_StaleCache(t *testing.T) {
+	dir := t.TempDir()
+	writeCacheFile(t, dir, entry{
+		FetchedAt:          time.Now().UTC().Add(-2 * time.Hour),
+		Versions:           []string{"0.22.0"},
+		PrereleaseVersions: []string{"0.23.0-alpha.1", "0.22.0"},
+	})
+
+	v, pv, ok := New(dir).LoadStale()
+	if !ok {
+		t.Fatal("expected a stale cache to be read, got miss")
+	}
+	if len(v) != 1 || v[0] != "0.22.0" || len(pv) != 2 {
+		t.Fatalf("unexpected versions %v %v", v, pv)
+	}
+}
+
 func TestLoad_CorruptFile(t *testing.T) {
 	dir := t.TempDir()
 	if err := os.MkdirAll(dir, 0o755); err != nil {
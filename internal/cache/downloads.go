@@ -0,0 +1,372 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// downloadMetaFileName records the artifact name and digest next to each
+	// stored artifact.
+	downloadMetaFileName = "meta.json"
+
+	// downloadChecksumsFileName is the release's checksums.txt, kept so the
+	// artifact can be re-verified (and bundled) without network access.
+	downloadChecksumsFileName = "checksums.txt"
+
+	// defaultDownloadCacheSize caps the total size of the download store.
+	// vcluster binaries are ~60-100 MB each, so 1 GiB holds roughly a dozen.
+	defaultDownloadCacheSize = 1 << 30
+)
+
+// downloadMeta is the on-disk JSON structure describing a stored artifact.
+type downloadMeta struct {
+	// Name is the release asset name, e.g. "vcluster-linux-amd64".
+	Name string `json:"name"`
+
+	// Digest is the SHA-256 of the artifact, formatted as "sha256:<hex>".
+	Digest string `json:"digest"`
+
+	// Size is the artifact size in bytes.
+	Size int64 `json:"size"`
+
+	// StoredAt is the UTC timestamp at which the artifact was stored.
+	StoredAt time.Time `json:"stored_at"`
+}
+
+// Artifact is a verified release artifact together with the checksums file
+// it was verified against.
+type Artifact struct {
+	// Name is the release asset name.
+	Name string
+
+	// Data is the artifact content.
+	Data []byte
+
+	// Checksums is the content of the release's checksums.txt.
+	Checksums []byte
+}
+
+// DownloadStore is a size-capped, least-recently-used store of verified
+// release artifacts laid out as <dir>/<version>/<os>-<arch>/.
+//
+// Every entry is keyed by the artifact's SHA-256 digest: Get re-hashes the
+// stored file and treats a mismatch as a miss, so a corrupted entry can
+// never be installed.  The modification time of meta.json doubles as the
+// last-used timestamp for eviction.
+type DownloadStore struct {
+	// dir is the root directory of the store
+	// (typically $VCENV_ROOT/cache/downloads).
+	dir string
+
+	// maxBytes is the size cap enforced after every Put.
+	maxBytes int64
+}
+
+// NewDownloadStore creates a DownloadStore rooted at dir.  If dir is empty
+// the store is disabled: Get always misses and Put is a no-op.
+// The size cap is read from VCENV_DOWNLOAD_CACHE_SIZE (default 1GB).
+func NewDownloadStore(dir string) *DownloadStore {
+	return &DownloadStore{dir: dir, maxBytes: parseDownloadCacheSize()}
+}
+
+// NewDownloadStoreWithSize creates a DownloadStore with an explicit size cap,
+// bypassing the environment variable.
+func NewDownloadStoreWithSize(dir string, maxBytes int64) *DownloadStore {
+	return &DownloadStore{dir: dir, maxBytes: maxBytes}
+}
+
+// Dir returns the root directory of the store.
+func (s *DownloadStore) Dir() string {
+	return s.dir
+}
+
+// MaxBytes returns the configured size cap.
+func (s *DownloadStore) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+// parseDownloadCacheSize reads VCENV_DOWNLOAD_CACHE_SIZE, falling back to
+// defaultDownloadCacheSize on any error.
+func parseDownloadCacheSize() int64 {
+	raw := os.Getenv("VCENV_DOWNLOAD_CACHE_SIZE")
+	if raw == "" {
+		return defaultDownloadCacheSize
+	}
+	n, err := ParseSize(raw)
+	if err != nil {
+		return defaultDownloadCacheSize
+	}
+	return n
+}
+
+// ParseSize parses a byte size such as "512MB", "2G" or "1048576".
+// Units are binary (1KB = 1024 bytes).
+func ParseSize(raw string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(raw))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "IB"), "B")
+
+	multiplier := int64(1)
+	switch {
+	case strings.HasSuffix(s, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(s, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(s, "G"):
+		multiplier = 1 << 30
+	}
+	if multiplier != 1 {
+		s = s[:len(s)-1]
+	}
+
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", raw)
+	}
+	return n * multiplier, nil
+}
+
+// entryDir returns the directory holding the artifact for a version and
+// platform key (e.g. "linux-amd64").
+func (s *DownloadStore) entryDir(version, platformKey string) string {
+	return filepath.Join(s.dir, version, platformKey)
+}
+
+// Get returns the stored artifact for version and platformKey.  It returns
+// false when the entry is missing or its content no longer matches the
+// recorded digest.  A hit refreshes the entry's last-used timestamp.
+func (s *DownloadStore) Get(version, platformKey string) (Artifact, bool) {
+	if s.dir == "" {
+		return Artifact{}, false
+	}
+
+	dir := s.entryDir(version, platformKey)
+	metaData, err := os.ReadFile(filepath.Join(dir, downloadMetaFileName))
+	if err != nil {
+		return Artifact{}, false
+	}
+	var meta downloadMeta
+	if err := json.Unmarshal(metaData, &meta); err != nil || meta.Name == "" {
+		return Artifact{}, false
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, filepath.Base(meta.Name)))
+	if err != nil {
+		return Artifact{}, false
+	}
+	if Digest(data) != meta.Digest {
+		return Artifact{}, false
+	}
+	checksums, _ := os.ReadFile(filepath.Join(dir, downloadChecksumsFileName))
+
+	now := time.Now()
+	_ = os.Chtimes(filepath.Join(dir, downloadMetaFileName), now, now)
+
+	return Artifact{Name: meta.Name, Data: data, Checksums: checksums}, true
+}
+
+// Put stores a verified artifact, replacing any existing entry for the same
+// version and platform, and then evicts least-recently-used entries until
+// the store fits within its size cap.
+func (s *DownloadStore) Put(version, platformKey string, a Artifact) error {
+	if s.dir == "" {
+		return nil
+	}
+
+	parent := filepath.Join(s.dir, version)
+	if err := os.MkdirAll(parent, 0o755); err != nil {
+		return fmt.Errorf("cache: failed to create directory %s: %w", parent, err)
+	}
+
+	// Assemble the entry in a temp directory, then rename it into place so a
+	// concurrent Get never observes a half-written entry.
+	tmpDir, err := os.MkdirTemp(parent, ".download-*")
+	if err != nil {
+		return fmt.Errorf("cache: failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	meta := downloadMeta{
+		Name:     filepath.Base(a.Name),
+		Digest:   Digest(a.Data),
+		Size:     int64(len(a.Data)),
+		StoredAt: time.Now().UTC(),
+	}
+	metaData, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cache: failed to marshal download metadata: %w", err)
+	}
+
+	files := map[string][]byte{
+		meta.Name:            a.Data,
+		downloadMetaFileName: metaData,
+	}
+	if len(a.Checksums) > 0 {
+		files[downloadChecksumsFileName] = a.Checksums
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), data, 0o644); err != nil {
+			return fmt.Errorf("cache: failed to write %s: %w", name, err)
+		}
+	}
+
+	target := s.entryDir(version, platformKey)
+	if err := os.RemoveAll(target); err != nil {
+		return fmt.Errorf("cache: failed to replace %s: %w", target, err)
+	}
+	if err := os.Rename(tmpDir, target); err != nil {
+		return fmt.Errorf("cache: failed to store download: %w", err)
+	}
+
+	return s.evict()
+}
+
+// DownloadEntry describes one stored artifact.
+type DownloadEntry struct {
+	Version     string
+	PlatformKey string
+	Name        string
+	Digest      string
+	Size        int64
+	LastUsed    time.Time
+	dir         string
+}
+
+// Entries lists every artifact in the store, least recently used first.
+func (s *DownloadStore) Entries() ([]DownloadEntry, error) {
+	if s.dir == "" {
+		return nil, nil
+	}
+
+	versionDirs, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cache: failed to read %s: %w", s.dir, err)
+	}
+
+	var entries []DownloadEntry
+	for _, v := range versionDirs {
+		if !v.IsDir() || strings.HasPrefix(v.Name(), ".") {
+			continue
+		}
+		platformDirs, err := os.ReadDir(filepath.Join(s.dir, v.Name()))
+		if err != nil {
+			continue
+		}
+		for _, p := range platformDirs {
+			if !p.IsDir() || strings.HasPrefix(p.Name(), ".") {
+				continue
+			}
+			dir := s.entryDir(v.Name(), p.Name())
+			metaPath := filepath.Join(dir, downloadMetaFileName)
+			info, err := os.Stat(metaPath)
+			if err != nil {
+				continue
+			}
+			var meta downloadMeta
+			if data, err := os.ReadFile(metaPath); err == nil {
+				_ = json.Unmarshal(data, &meta)
+			}
+			entries = append(entries, DownloadEntry{
+				Version:     v.Name(),
+				PlatformKey: p.Name(),
+				Name:        meta.Name,
+				Digest:      meta.Digest,
+				Size:        meta.Size,
+				LastUsed:    info.ModTime(),
+				dir:         dir,
+			})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastUsed.Before(entries[j].LastUsed)
+	})
+	return entries, nil
+}
+
+// Usage returns the number of stored artifacts and their total size.
+func (s *DownloadStore) Usage() (count int, size int64, err error) {
+	entries, err := s.Entries()
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, e := range entries {
+		size += e.Size
+	}
+	return len(entries), size, nil
+}
+
+// evict removes least-recently-used entries until the total size is within
+// the cap.  The most recently stored entry is never evicted, even if it alone
+// exceeds the cap.
+func (s *DownloadStore) evict() error {
+	entries, err := s.Entries()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, e := range entries {
+		total += e.Size
+	}
+
+	for i := 0; i < len(entries)-1 && total > s.maxBytes; i++ {
+		if err := os.RemoveAll(entries[i].dir); err != nil {
+			return fmt.Errorf("cache: failed to evict %s: %w", entries[i].dir, err)
+		}
+		total -= entries[i].Size
+		// Drop the version directory once its last platform is gone.
+		_ = os.Remove(filepath.Dir(entries[i].dir))
+	}
+	return nil
+}
+
+// Clear removes every stored artifact.
+func (s *DownloadStore) Clear() error {
+	if s.dir == "" {
+		return nil
+	}
+	if err := os.RemoveAll(s.dir); err != nil {
+		return fmt.Errorf("cache: failed to clear %s: %w", s.dir, err)
+	}
+	return nil
+}
+
+// digestPrefix is the algorithm prefix of a SHA-256 digest.
+const digestPrefix = "sha256:"
+
+// SHA256 returns the hex-encoded SHA-256 of data, as listed in checksums
+// files.
+func SHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Digest returns the SHA-256 digest of data formatted as "sha256:<hex>",
+// matching the format GitHub uses for release asset digests.
+func Digest(data []byte) string {
+	return FormatDigest(SHA256(data))
+}
+
+// FormatDigest formats a hex-encoded SHA-256 as a "sha256:<hex>" digest.
+func FormatDigest(sum string) string {
+	return digestPrefix + sum
+}
+
+// ParseDigest returns the lower-case hex SHA-256 held by a "sha256:<hex>"
+// digest.  ok is false when digest is not a SHA-256 digest.
+func ParseDigest(digest string) (sum string, ok bool) {
+	sum, ok = strings.CutPrefix(digest, digestPrefix)
+	return strings.ToLower(sum), ok && sum != ""
+}
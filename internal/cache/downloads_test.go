@@ -0,0 +1,168 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownloadStore_RoundTrip(t *testing.T) {
+	s := NewDownloadStoreWithSize(t.TempDir(), 1<<20)
+	a := Artifact{Name: "vcluster-linux-amd64", Data: []byte("binary"), Checksums: []byte("abc  vcluster-linux-amd64\n")}
+
+	if err := s.Put("0.21.1", "linux-amd64", a); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	got, ok := s.Get("0.21.1", "linux-amd64")
+	if !ok {
+		t.Fatal("expected hit after Put")
+	}
+	if got.Name != a.Name || string(got.Data) != string(a.Data) || string(got.Checksums) != string(a.Checksums) {
+		t.Fatalf("round trip mismatch: got %+v", got)
+	}
+
+	if _, ok := s.Get("0.21.1", "darwin-arm64"); ok {
+		t.Fatal("expected miss for other platform")
+	}
+}
+
+func TestDownloadStore_CorruptedEntryIsMiss(t *testing.T) {
+	dir := t.TempDir()
+	s := NewDownloadStoreWithSize(dir, 1<<20)
+	a := Artifact{Name: "vcluster-linux-amd64", Data: []byte("binary")}
+	if err := s.Put("0.21.1", "linux-amd64", a); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	path := filepath.Join(dir, "0.21.1", "linux-amd64", "vcluster-linux-amd64")
+	if err := os.WriteFile(path, []byte("tampered"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := s.Get("0.21.1", "linux-amd64"); ok {
+		t.Fatal("expected miss when digest does not match")
+	}
+}
+
+func TestDownloadStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	s := NewDownloadStoreWithSize(dir, 10)
+
+	put := func(version string) {
+		t.Helper()
+		if err := s.Put(version, "linux-amd64", Artifact{Name: "vcluster-linux-amd64", Data: []byte("12345")}); err != nil {
+			t.Fatalf("Put %s: %v", version, err)
+		}
+	}
+
+	put("0.20.0")
+	put("0.21.0")
+
+	// Make 0.20.0 the most recently used entry.
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "0.21.0", "linux-amd64", downloadMetaFileName), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Get("0.20.0", "linux-amd64"); !ok {
+		t.Fatal("expected hit for 0.20.0")
+	}
+
+	put("0.22.0")
+
+	if _, ok := s.Get("0.21.0", "linux-amd64"); ok {
+		t.Fatal("expected least recently used entry 0.21.0 to be evicted")
+	}
+	if _, ok := s.Get("0.20.0", "linux-amd64"); !ok {
+		t.Fatal("expected recently used entry 0.20.0 to survive")
+	}
+	if _, ok := s.Get("0.22.0", "linux-amd64"); !ok {
+		t.Fatal("expected newest entry 0.22.0 to survive")
+	}
+
+	count, size, err := s.Usage()
+	if err != nil {
+		t.Fatalf("Usage: %v", err)
+	}
+	if count != 2 || size != 10 {
+		t.Fatalf("expected 2 entries / 10 bytes, got %d / %d", count, size)
+	}
+}
+
+func TestDownloadStore_Clear(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "downloads")
+	s := NewDownloadStoreWithSize(dir, 1<<20)
+	if err := s.Put("0.21.1", "linux-amd64", Artifact{Name: "vcluster-linux-amd64", Data: []byte("x")}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := s.Clear(); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	if count, _, _ := s.Usage(); count != 0 {
+		t.Fatalf("expected empty store after Clear, got %d entries", count)
+	}
+}
+
+func TestDownloadStore_EmptyDir_NoOp(t *testing.T) {
+	s := NewDownloadStore("")
+	if err := s.Put("0.21.1", "linux-amd64", Artifact{Name: "vcluster-linux-amd64", Data: []byte("x")}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, ok := s.Get("0.21.1", "linux-amd64"); ok {
+		t.Fatal("expected disabled store to always miss")
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected int64
+		wantErr  bool
+	}{
+		{"1048576", 1 << 20, false},
+		{"512MB", 512 << 20, false},
+		{"2G", 2 << 30, false},
+		{"1GiB", 1 << 30, false},
+		{"10k", 10 << 10, false},
+		{"lots", 0, true},
+		{"-1", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParseSize(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q", tt.raw)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Fatalf("ParseSize(%q) = %d, want %d", tt.raw, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDigest(t *testing.T) {
+	const sum = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	if got := SHA256([]byte("hello")); got != sum {
+		t.Fatalf("SHA256 = %q, want %q", got, sum)
+	}
+	if got := Digest([]byte("hello")); got != "sha256:"+sum {
+		t.Fatalf("Digest = %q", got)
+	}
+	if got, ok := ParseDigest("sha256:" + strings.ToUpper(sum)); !ok || got != sum {
+		t.Fatalf("ParseDigest = %q, %v", got, ok)
+	}
+	for _, digest := range []string{"", sum, "sha512:" + sum, "sha256:"} {
+		if _, ok := ParseDigest(digest); ok {
+			t.Errorf("expected %q to be rejected", digest)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// networkFileName is the name of the JSON file stored under
+	// $VCENV_ROOT/cache/ that tracks recent connection failures.
+	networkFileName = "network.json"
+
+	// offlineThreshold is the number of consecutive connection failures
+	// after which vc-env switches to offline mode automatically.
+	offlineThreshold = 3
+
+	// defaultOfflineBackoff is how long automatic offline mode lasts once
+	// tripped.  Overridable via the VCENV_OFFLINE_BACKOFF environment
+	// variable.
+	defaultOfflineBackoff = 10 * time.Minute
+)
+
+// networkEntry is the on-disk JSON structure for the network state file.
+type networkEntry struct {
+	// Failures is the number of consecutive connection failures.
+	Failures int `json:"failures"`
+
+	// LastFailure is the UTC timestamp of the most recent failure.
+	LastFailure time.Time `json:"last_failure"`
+
+	// OfflineUntil is set once Failures reaches the threshold.  Until this
+	// moment every command behaves as if --offline had been passed.
+	OfflineUntil time.Time `json:"offline_until,omitempty"`
+}
+
+// NetworkState records connection failures across process invocations so
+// that a machine without connectivity (e.g. a laptop on a plane) does not
+// wait for an HTTP timeout on every call.
+type NetworkState struct {
+	// dir is the directory that holds the state file
+	// (typically $VCENV_ROOT/cache).
+	dir string
+
+	// backoff is how long automatic offline mode lasts once tripped.
+	backoff time.Duration
+}
+
+// NewNetworkState creates a NetworkState that stores its file in dir.
+// If dir is empty no state is persisted and automatic offline mode never
+// triggers.  The backoff is read from VCENV_OFFLINE_BACKOFF (default 10 m).
+func NewNetworkState(dir string) *NetworkState {
+	backoff := defaultOfflineBackoff
+	if raw := os.Getenv("VCENV_OFFLINE_BACKOFF"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil {
+			backoff = d
+		}
+	}
+	return &NetworkState{dir: dir, backoff: backoff}
+}
+
+// path returns the full path to the state file.
+func (n *NetworkState) path() string {
+	return filepath.Join(n.dir, networkFileName)
+}
+
+// load reads the state file, returning a zero entry when it is missing or
+// unreadable.
+func (n *NetworkState) load() networkEntry {
+	var e networkEntry
+	if n.dir == "" {
+		return e
+	}
+	data, err := os.ReadFile(n.path())
+	if err != nil {
+		return e
+	}
+	if err := json.Unmarshal(data, &e); err != nil {
+		return networkEntry{}
+	}
+	return e
+}
+
+// OfflineUntil returns the moment automatic offline mode ends and true if it
+// is currently in effect.
+func (n *NetworkState) OfflineUntil() (time.Time, bool) {
+	e := n.load()
+	if e.OfflineUntil.IsZero() || time.Now().After(e.OfflineUntil) {
+		return time.Time{}, false
+	}
+	return e.OfflineUntil, true
+}
+
+// RecordFailure registers a connection failure.  Failures older than the
+// backoff window do not count towards the threshold.  It returns true when
+// this failure switched vc-env into automatic offline mode.
+func (n *NetworkState) RecordFailure() (bool, error) {
+	if n.dir == "" {
+		return false, nil
+	}
+
+	now := time.Now().UTC()
+	e := n.load()
+	if now.Sub(e.LastFailure) > n.backoff {
+		e.Failures = 0
+	}
+	e.Failures++
+	e.LastFailure = now
+
+	tripped := false
+	if e.Failures >= offlineThreshold {
+		e.OfflineUntil = now.Add(n.backoff)
+		e.Failures = 0
+		tripped = true
+	}
+
+	if err := os.MkdirAll(n.dir, 0o755); err != nil {
+		return tripped, fmt.Errorf("cache: failed to create directory %s: %w", n.dir, err)
+	}
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return tripped, fmt.Errorf("cache: failed to marshal network state: %w", err)
+	}
+	return tripped, WriteFileAtomic(n.path(), data, 0o644)
+}
+
+// RecordSuccess clears any recorded failures after a successful request.
+func (n *NetworkState) RecordSuccess() error {
+	if n.dir == "" {
+		return nil
+	}
+	if err := os.Remove(n.path()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("cache: failed to clear network state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNetworkState_TripsAfterThreshold(t *testing.T) {
+	n := NewNetworkState(t.TempDir())
+
+	for i := 1; i < offlineThreshold; i++ {
+		tripped, err := n.RecordFailure()
+		if err != nil {
+			t.Fatalf("RecordFailure: %v", err)
+		}
+		if tripped {
+			t.Fatalf("tripped after %d failures, threshold is %d", i, offlineThreshold)
+		}
+	}
+	if _, ok := n.OfflineUntil(); ok {
+		t.Fatal("expected online before threshold is reached")
+	}
+
+	tripped, err := n.RecordFailure()
+	if err != nil {
+		t.Fatalf("RecordFailure: %v", err)
+	}
+	if !tripped {
+		t.Fatal("expected threshold failure to trip offline mode")
+	}
+	until, ok := n.OfflineUntil()
+	if !ok {
+		t.Fatal("expected offline mode after threshold")
+	}
+	if until.Before(time.Now()) {
+		t.Fatalf("expected offline-until in the future, got %v", until)
+	}
+}
+
+func TestNetworkState_SuccessResets(t *testing.T) {
+	n := NewNetworkState(t.TempDir())
+	for i := 0; i < offlineThreshold; i++ {
+		if _, err := n.RecordFailure(); err != nil {
+			t.Fatalf("RecordFailure: %v", err)
+		}
+	}
+	if err := n.RecordSuccess(); err != nil {
+		t.Fatalf("RecordSuccess: %v", err)
+	}
+	if _, ok := n.OfflineUntil(); ok {
+		t.Fatal("expected success to clear offline mode")
+	}
+}
+
+func TestNetworkState_BackoffExpires(t *testing.T) {
+	t.Setenv("VCENV_OFFLINE_BACKOFF", "1ns")
+	n := NewNetworkState(t.TempDir())
+	for i := 0; i < offlineThreshold; i++ {
+		if _, err := n.RecordFailure(); err != nil {
+			t.Fatalf("RecordFailure: %v", err)
+		}
+	}
+	time.Sleep(time.Millisecond)
+	if _, ok := n.OfflineUntil(); ok {
+		t.Fatal("expected offline mode to expire after backoff")
+	}
+}
+
+func TestNetworkState_EmptyDir_NoOp(t *testing.T) {
+	n := NewNetworkState("")
+	for i := 0; i < offlineThreshold; i++ {
+		tripped, err := n.RecordFailure()
+		if err != nil || tripped {
+			t.Fatalf("expected no-op, got tripped=%v err=%v", tripped, err)
+		}
+	}
+	if _, ok := n.OfflineUntil(); ok {
+		t.Fatal("expected memory-only state never to go offline")
+	}
+}
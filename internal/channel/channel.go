@@ -0,0 +1,197 @@
+// Package channel resolves the vcluster version published by a release
+// channel: a plain-text file served over HTTP(S) whose first non-comment
+// line names a version, e.g. https://intranet/vcluster/stable.txt.
+//
+// Platform teams publish such a file to roll the company default forward
+// centrally.  vc-env uses it as the global version when configured with
+// "vc-env global --channel URL".
+//
+// # Caching
+//
+// The last version fetched is kept in $VCENV_ROOT/cache/channel.json and
+// served while it is younger than the TTL (default 1 h, overridable via
+// VCENV_CHANNEL_TTL).  Once stale it is fetched again; if that fails, the
+// stale version is used so that an unreachable intranet never leaves the
+// shim without a version.  Offline, the cached version is used regardless
+// of its age.
+package channel
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/semver"
+)
+
+const (
+	// cacheFileName is the name of the JSON file stored under
+	// $VCENV_ROOT/cache/.
+	cacheFileName = "channel.json"
+
+	// defaultTTL is how long a fetched version is served before the
+	// channel is fetched again.
+	defaultTTL = time.Hour
+
+	// fetchTimeout bounds a channel fetch, which may happen while the shim
+	// resolves the version.
+	fetchTimeout = 5 * time.Second
+
+	// maxChannelSize bounds the response read from the channel URL.
+	maxChannelSize = 64 << 10
+)
+
+// entry is the on-disk JSON structure for the cache file.
+type entry struct {
+	// URL is the channel the version was fetched from.  An entry for
+	// another URL is ignored.
+	URL string `json:"url"`
+
+	// Version is the version the channel named.
+	Version string `json:"version"`
+
+	// FetchedAt is the UTC timestamp of the fetch.
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Channel fetches and caches the version named by a channel URL.
+type Channel struct {
+	// URL is the address of the channel file.
+	URL string
+
+	// HTTPClient is used for fetches; it defaults to a client with a short
+	// timeout.
+	HTTPClient *http.Client
+
+	// dir is the directory that holds the cache file
+	// (typically $VCENV_ROOT/cache).  If empty nothing is cached.
+	dir string
+
+	// ttl is the maximum age of a cached version before it is fetched again.
+	ttl time.Duration
+}
+
+// New creates a Channel for url that caches in dir.  The TTL is read from
+// the VCENV_CHANNEL_TTL environment variable (default 1 h).
+func New(url, dir string) *Channel {
+	ttl := defaultTTL
+	if raw := os.Getenv("VCENV_CHANNEL_TTL"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil {
+			ttl = d
+		}
+	}
+	return &Channel{
+		URL:        url,
+		HTTPClient: &http.Client{Timeout: fetchTimeout},
+		dir:        dir,
+		ttl:        ttl,
+	}
+}
+
+// Version returns the version the channel names.  A fresh cached version is
+// returned without a fetch; offline, any cached version is.  When a fetch
+// fails the stale cached version is returned, if there is one.
+func (c *Channel) Version(offline bool) (string, error) {
+	cached, ok := c.load()
+	if ok && (offline || time.Since(cached.FetchedAt) <= c.ttl) {
+		return cached.Version, nil
+	}
+	if offline {
+		return "", fmt.Errorf("channel %s has not been fetched yet and vc-env is offline", c.URL)
+	}
+
+	v, err := c.Refresh()
+	if err != nil {
+		if ok {
+			return cached.Version, nil
+		}
+		return "", err
+	}
+	return v, nil
+}
+
+// Refresh fetches the channel, bypassing the cache, and caches the result.
+func (c *Channel) Refresh() (string, error) {
+	resp, err := c.HTTPClient.Get(c.URL)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch channel %s: %w", c.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch channel %s: HTTP %d", c.URL, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxChannelSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read channel %s: %w", c.URL, err)
+	}
+	v, err := ParseVersion(data)
+	if err != nil {
+		return "", fmt.Errorf("channel %s: %w", c.URL, err)
+	}
+	// A failed save only means the next invocation fetches again.
+	_ = c.save(entry{URL: c.URL, Version: v, FetchedAt: time.Now().UTC()})
+	return v, nil
+}
+
+// FetchedAt returns when the cached version of the channel was fetched.
+func (c *Channel) FetchedAt() (time.Time, bool) {
+	e, ok := c.load()
+	return e.FetchedAt, ok
+}
+
+// ParseVersion returns the version named by the content of a channel file:
+// the first field of its first line that is not blank or a "#" comment.  A
+// leading "v" is removed.
+func ParseVersion(data []byte) (string, error) {
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if !semver.IsValid(fields[0]) {
+			return "", fmt.Errorf("%q is not a version", fields[0])
+		}
+		return strings.TrimPrefix(fields[0], "v"), nil
+	}
+	return "", fmt.Errorf("no version found")
+}
+
+// path returns the full path to the cache file.
+func (c *Channel) path() string {
+	return filepath.Join(c.dir, cacheFileName)
+}
+
+// load reads the cached version of the channel, regardless of its age.
+func (c *Channel) load() (entry, bool) {
+	var e entry
+	if c.dir == "" {
+		return e, false
+	}
+	data, err := os.ReadFile(c.path())
+	if err != nil {
+		return e, false
+	}
+	if err := json.Unmarshal(data, &e); err != nil || e.URL != c.URL || e.Version == "" {
+		return entry{}, false
+	}
+	return e, true
+}
+
+// save writes e to the cache file atomically.
+func (c *Channel) save(e entry) error {
+	if c.dir == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	return cache.WriteFileAtomic(c.path(), data, 0o644)
+}
@@ -0,0 +1,76 @@
+package channel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    string
+		wantErr bool
+	}{
+		{"0.22.0\n", "0.22.0", false},
+		{"# stable channel\n\nv0.21.3  # rolled out 2026-10-01\n0.20.0\n", "0.21.3", false},
+		{"", "", true},
+		{"<html>not found</html>\n", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseVersion([]byte(tt.data))
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseVersion(%q) = %q, %v; want %q (error %v)", tt.data, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestChannelVersion(t *testing.T) {
+	var hits atomic.Int32
+	version := "0.22.0"
+	failing := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(version + "\n"))
+	}))
+	defer server.Close()
+
+	t.Setenv("VCENV_CHANNEL_TTL", "1h")
+	dir := t.TempDir()
+	c := New(server.URL+"/stable.txt", dir)
+
+	if _, err := c.Version(true); err == nil {
+		t.Fatal("expected an error offline without a cached version")
+	}
+	if v, err := c.Version(false); err != nil || v != "0.22.0" {
+		t.Fatalf("Version() = %q, %v", v, err)
+	}
+
+	// The cached version is served while fresh.
+	version = "0.23.0"
+	if v, _ := New(c.URL, dir).Version(false); v != "0.22.0" || hits.Load() != 1 {
+		t.Fatalf("expected the cached version without a fetch, got %q after %d fetches", v, hits.Load())
+	}
+
+	// Once stale, the channel is fetched again.
+	t.Setenv("VCENV_CHANNEL_TTL", "0s")
+	if v, _ := New(c.URL, dir).Version(false); v != "0.23.0" {
+		t.Fatalf("expected the refreshed version, got %q", v)
+	}
+
+	// A failing fetch falls back to the stale version.
+	failing = true
+	if v, err := New(c.URL, dir).Version(false); err != nil || v != "0.23.0" {
+		t.Fatalf("expected the stale version, got %q (%v)", v, err)
+	}
+
+	// The cache belongs to one URL.
+	if _, err := New(server.URL+"/beta.txt", dir).Version(true); err == nil {
+		t.Fatal("expected the cache of another channel to be ignored")
+	}
+}
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/user/vc-env/internal/config"
+)
+
+// AliasHelp prints the help message for the alias command.
+func AliasHelp() {
+	fmt.Println(`Usage: vc-env alias <subcommand> [arguments]
+
+Manage named aliases for vcluster versions, stored in $VCENV_ROOT/aliases.
+
+Subcommands:
+  set NAME VERSION  Point the alias NAME at VERSION
+  list              List all aliases and their versions
+  rm NAME           Remove the alias NAME
+
+Flags:
+  -h, --help        Show this help message
+
+An alias can be used wherever a version is expected: "vc-env local prod",
+"vc-env exec prod ...", VCENV_VERSION, and inside .vcluster-version files.
+Moving an alias to another version switches every project that uses it.`)
+}
+
+// AliasSet points the alias name at version.  A warning is printed when the
+// version is not installed, since the alias cannot be used until it is.
+func AliasSet(name, version string) error {
+	if err := config.RequireInit(); err != nil {
+		return err
+	}
+	if name == "" || version == "" {
+		return fmt.Errorf("usage: vc-env alias set NAME VERSION")
+	}
+	if err := config.WriteAlias(name, version); err != nil {
+		return err
+	}
+	if version != config.SystemVersion {
+		if installed, err := config.IsVersionInstalled(version); err == nil && !installed {
+			fmt.Fprintf(os.Stderr, "warning: version %s is not installed; install it with: vc-env install %s\n", version, version)
+		}
+	}
+	return nil
+}
+
+// AliasList prints all aliases and the versions they stand for.
+func AliasList() error {
+	if err := config.RequireInit(); err != nil {
+		return err
+	}
+	aliases, err := config.ListAliases()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	for _, a := range aliases {
+		fmt.Fprintf(w, "%s\t-> %s\n", a.Name, a.Version)
+	}
+	return w.Flush()
+}
+
+// AliasRemove deletes the alias name.
+func AliasRemove(name string) error {
+	if err := config.RequireInit(); err != nil {
+		return err
+	}
+	if name == "" {
+		return fmt.Errorf("usage: vc-env alias rm NAME")
+	}
+	return config.RemoveAlias(name)
+}
+
+// requireInstalled returns an error unless version, or the version it stands
+// for if it is an alias, is installed.  "system" is resolved at run time and
+// may legitimately be missing on this machine.
+func requireInstalled(version string) error {
+	target := config.ExpandAlias(version)
+	if target == config.SystemVersion {
+		return nil
+	}
+	installed, err := config.IsVersionInstalled(target)
+	if err != nil {
+		return err
+	}
+	if !installed {
+		if target != version {
+			return fmt.Errorf("version %s (alias %s) not installed", target, version)
+		}
+		return fmt.Errorf("version %s not installed", version)
+	}
+	return nil
+}
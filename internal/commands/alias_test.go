@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAlias(t *testing.T) {
+	setup := func(t *testing.T) string {
+		t.Helper()
+		tmpDir := t.TempDir()
+		t.Setenv("VCENV_ROOT", tmpDir)
+		t.Setenv("VCENV_VERSION", "")
+		t.Setenv("VCENV_RESOLVE_ORDER", "")
+		versionDir := filepath.Join(tmpDir, "versions", "0.31.0")
+		if err := os.MkdirAll(versionDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(versionDir, "vcluster"), []byte("binary"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		return tmpDir
+	}
+
+	t.Run("set, list and rm", func(t *testing.T) {
+		setup(t)
+		if err := AliasSet("prod", "0.31.0"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := AliasSet("next", "0.32.0"); err != nil {
+			t.Fatalf("an alias may point at a version that is not installed yet: %v", err)
+		}
+		if err := AliasSet("0.30.0", "0.31.0"); err == nil {
+			t.Fatal("expected a version-like alias name to be rejected")
+		}
+
+		output := captureStdout(t, func() {
+			if err := AliasList(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+		if !strings.Contains(output, "next  -> 0.32.0\nprod  -> 0.31.0") {
+			t.Fatalf("unexpected list output %q", output)
+		}
+
+		if err := AliasRemove("next"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := AliasRemove("next"); err == nil || !strings.Contains(err.Error(), "does not exist") {
+			t.Fatalf("expected a missing alias error, got %v", err)
+		}
+	})
+
+	t.Run("local writes the alias and status shows its version", func(t *testing.T) {
+		setup(t)
+		if err := AliasSet("prod", "0.31.0"); err != nil {
+			t.Fatal(err)
+		}
+		workDir := t.TempDir()
+		origDir, _ := os.Getwd()
+		if err := os.Chdir(workDir); err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = os.Chdir(origDir) }()
+
+		if err := Local("prod", false); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(workDir, ".vcluster-version"))
+		if err != nil || strings.TrimSpace(string(data)) != "prod" {
+			t.Fatalf("expected the alias in .vcluster-version, got %q (%v)", data, err)
+		}
+
+		output := captureStdout(t, func() {
+			if err := Status(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+		for _, want := range []string{"0.31.0 (set by .vcluster-version file via alias prod)", "Aliases (1):", "prod -> 0.31.0"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("status lacks %q:\n%s", want, output)
+			}
+		}
+	})
+
+	t.Run("rejects an alias for a missing version", func(t *testing.T) {
+		setup(t)
+		if err := AliasSet("next", "0.32.0"); err != nil {
+			t.Fatal(err)
+		}
+		if err := Global("next"); err == nil || !strings.Contains(err.Error(), "version 0.32.0 (alias next) not installed") {
+			t.Fatalf("expected a not installed error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,272 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/config"
+	"github.com/user/vc-env/internal/github"
+	"github.com/user/vc-env/internal/semver"
+)
+
+// AuditHelp prints the help message for the audit command.
+func AuditHelp() {
+	fmt.Println(`Usage: vc-env audit
+
+List installed and pinned vcluster versions that are affected by a published
+security advisory of loft-sh/vcluster, with the version that fixes each.
+
+Pinned versions are those named by the version files in effect for the
+current directory, the global version and aliases.
+
+Advisories are cached at $VCENV_ROOT/cache/advisories.json for VCENV_CACHE_TTL
+(default 1h), like the release list.  In offline mode the cached advisories
+are used.  "list" and "status" flag vulnerable installs using the cache only.
+
+An advisory whose affected version range cannot be parsed is reported with a
+warning, as no version can be checked against it.
+
+Exits with status 1 when an affected version is found.
+
+Flags:
+  -h, --help  Show this help message`)
+}
+
+// Audit reports installed and pinned versions affected by security
+// advisories.
+func Audit() error {
+	return auditWithClient(github.NewClient())
+}
+
+// auditWithClient is the testable core of Audit.
+func auditWithClient(client *github.Client) error {
+	if err := config.RequireInit(); err != nil {
+		return err
+	}
+	advisories, err := getAdvisories(client)
+	if err != nil {
+		return err
+	}
+	for _, a := range advisories {
+		for _, r := range a.InvalidRanges() {
+			fmt.Fprintf(os.Stderr, "warning: %s has a version range that cannot be checked: %q\n", a.ID(), r)
+		}
+	}
+
+	targets := auditTargets()
+	versions := targets.versions()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	affected := 0
+	for _, v := range versions {
+		matched := false
+		for _, a := range advisories {
+			vuln, ok := a.Affects(v)
+			if !ok {
+				continue
+			}
+			if affected == 0 && !matched {
+				fmt.Fprintln(w, "VERSION\tADVISORY\tSEVERITY\tFIXED IN\tUSED BY")
+			}
+			matched = true
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", v, a.ID(), orDash(a.Severity), orDash(vuln.PatchedVersions), strings.Join(targets[v], ", "))
+		}
+		if matched {
+			affected++
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if affected == 0 {
+		fmt.Printf("No known security advisories affect the %d installed or pinned versions.\n", len(versions))
+		return nil
+	}
+	return fmt.Errorf("%d installed or pinned version(s) affected by security advisories", affected)
+}
+
+// versionUses maps versions to the places they are used from, such as
+// "installed" or "global version file".
+type versionUses map[string][]string
+
+// add records that version is used from source.  Aliases are expanded, and
+// "system" is ignored.
+func (u versionUses) add(version, source string) {
+	version = config.ExpandAlias(version)
+	if version == "" || version == config.SystemVersion {
+		return
+	}
+	for _, s := range u[version] {
+		if s == source {
+			return
+		}
+	}
+	u[version] = append(u[version], source)
+}
+
+// addInstalled records the installed versions.
+func (u versionUses) addInstalled() {
+	root, _ := config.GetVCEnvRoot()
+	if entries, err := os.ReadDir(filepath.Join(root, "versions")); err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() {
+				u.add(entry.Name(), "installed")
+			}
+		}
+	}
+}
+
+// addGlobal records the versions listed in the global version file.
+func (u versionUses) addGlobal() {
+	root, _ := config.GetVCEnvRoot()
+	if versions, err := config.ReadGlobalVersions(root); err == nil {
+		for _, v := range versions {
+			u.add(v, "global version file")
+		}
+	}
+}
+
+// versions returns the recorded versions, newest first.
+func (u versionUses) versions() []string {
+	versions := make([]string, 0, len(u))
+	for v := range u {
+		versions = append(versions, v)
+	}
+	return semver.SortDescending(versions)
+}
+
+// auditTargets returns the installed and pinned versions, each with the
+// places it is used from.
+func auditTargets() versionUses {
+	targets := versionUses{}
+	targets.addInstalled()
+	if r, err := config.Resolve(); err == nil && r.Provider != config.ProviderRemote {
+		candidates := r.Candidates
+		if len(candidates) == 0 {
+			candidates = []string{r.Version}
+		}
+		for _, v := range candidates {
+			targets.add(v, r.Description)
+		}
+	}
+	targets.addGlobal()
+	if aliases, err := config.ListAliases(); err == nil {
+		for _, a := range aliases {
+			targets.add(a.Version, "alias "+a.Name)
+		}
+	}
+	return targets
+}
+
+// getAdvisories returns the security advisories of vcluster, following the
+// strategy of getRemoteVersions: a fresh disk cache is served as is, a stale
+// one is refreshed, and served when the refresh fails or vc-env is offline.
+func getAdvisories(client *github.Client) ([]github.Advisory, error) {
+	c := newAdvisoryCacheForRoot()
+	cached, fresh, hasCache := loadAdvisories(c)
+	if fresh {
+		return cached, nil
+	}
+
+	if offline, reason := offlineMode(); offline {
+		if hasCache {
+			if !config.IsOffline() {
+				fmt.Fprintf(os.Stderr, "warning: %s; using cached security advisories\n", reason)
+			}
+			return cached, nil
+		}
+		return nil, fmt.Errorf("no cached security advisories: %s", reason)
+	}
+
+	advisories, err := client.ListAdvisories()
+	recordNetworkResult(err)
+	if err != nil {
+		if hasCache {
+			fmt.Fprintln(os.Stderr, "warning: failed to fetch security advisories; using cached data")
+			return cached, nil
+		}
+		return nil, fmt.Errorf("failed to fetch security advisories: %w", err)
+	}
+	if err := saveAdvisories(c, advisories); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not write advisory cache: %v\n", err)
+	}
+	return advisories, nil
+}
+
+// cachedAdvisories returns the cached security advisories, regardless of
+// their age, without accessing the network.
+func cachedAdvisories() []github.Advisory {
+	advisories, _, _ := loadAdvisories(newAdvisoryCacheForRoot())
+	return advisories
+}
+
+// loadAdvisories decodes the advisories held by c.  An entry that cannot be
+// decoded is treated as missing.
+func loadAdvisories(c *cache.AdvisoryCache) (advisories []github.Advisory, fresh bool, ok bool) {
+	data, fresh, ok := c.Load()
+	if !ok {
+		return nil, false, false
+	}
+	if err := json.Unmarshal(data, &advisories); err != nil {
+		return nil, false, false
+	}
+	return advisories, fresh, true
+}
+
+// saveAdvisories encodes advisories and stores them in c.
+func saveAdvisories(c *cache.AdvisoryCache, advisories []github.Advisory) error {
+	data, err := json.Marshal(advisories)
+	if err != nil {
+		return fmt.Errorf("failed to encode security advisories: %w", err)
+	}
+	return c.Save(data)
+}
+
+// vulnerabilityNote describes the advisories that affect version, e.g.
+// "vulnerable: CVE-2026-0001, fixed in 0.19.5", or returns "" if none does.
+func vulnerabilityNote(advisories []github.Advisory, version string) string {
+	var ids, fixes []string
+	for _, a := range advisories {
+		vuln, ok := a.Affects(version)
+		if !ok {
+			continue
+		}
+		ids = append(ids, a.ID())
+		if vuln.PatchedVersions != "" {
+			fixes = append(fixes, vuln.PatchedVersions)
+		}
+	}
+	if len(ids) == 0 {
+		return ""
+	}
+	note := "vulnerable: " + strings.Join(ids, ", ")
+	if len(fixes) > 0 {
+		sort.Strings(fixes)
+		note += ", fixed in " + strings.Join(fixes, ", ")
+	}
+	return note
+}
+
+// newAdvisoryCacheForRoot creates an AdvisoryCache rooted at
+// $VCENV_ROOT/cache.  If VCENV_ROOT is not set nothing is persisted.
+func newAdvisoryCacheForRoot() *cache.AdvisoryCache {
+	root, ok := config.GetVCEnvRoot()
+	if !ok {
+		return cache.NewAdvisoryCache("")
+	}
+	return cache.NewAdvisoryCache(filepath.Join(root, "cache"))
+}
+
+// orDash returns s, or "-" if s is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/user/vc-env/internal/github"
+)
+
+func TestAudit(t *testing.T) {
+	advisories := []github.Advisory{{
+		GHSAID:   "GHSA-aaaa-bbbb-cccc",
+		CVEID:    "CVE-2026-0001",
+		Severity: "HIGH",
+		Vulnerabilities: []github.Vulnerability{
+			{VulnerableVersionRange: ">= 0.19.0, < 0.19.5", PatchedVersions: "0.19.5"},
+		},
+	}}
+	setup := func(t *testing.T) (string, *github.Client) {
+		t.Helper()
+		tmpDir := t.TempDir()
+		t.Setenv("VCENV_ROOT", tmpDir)
+		t.Setenv("VCENV_VERSION", "")
+		t.Setenv("VCENV_RESOLVE_ORDER", "")
+		t.Setenv("VCENV_OFFLINE", "")
+		t.Setenv("VCENV_CACHE_TTL", "1h")
+		t.Setenv("VCENV_SUPPORT_WINDOW", "0.19")
+		for _, v := range []string{"0.19.2", "0.21.0"} {
+			if err := os.MkdirAll(filepath.Join(tmpDir, "versions", v), 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/repos/loft-sh/vcluster/security-advisories" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(advisories)
+		}))
+		t.Cleanup(server.Close)
+		return tmpDir, &github.Client{BaseURL: server.URL, HTTPClient: server.Client()}
+	}
+
+	t.Run("reports affected versions", func(t *testing.T) {
+		_, client := setup(t)
+		var err error
+		out := captureStdout(t, func() {
+			err = auditWithClient(client)
+		})
+		if err == nil || err.Error() != "1 installed or pinned version(s) affected by security advisories" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(out, "VERSION") || !strings.Contains(out, "0.19.2") || !strings.Contains(out, "CVE-2026-0001") ||
+			!strings.Contains(out, "high") || !strings.Contains(out, "0.19.5") || !strings.Contains(out, "installed") {
+			t.Fatalf("unexpected output %q", out)
+		}
+		if strings.Contains(out, "0.21.0") {
+			t.Fatalf("unaffected version reported: %q", out)
+		}
+
+		// list flags the vulnerable install from the cache written by audit.
+		out = captureStdout(t, func() {
+			if err := List(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+		if !strings.Contains(out, "0.19.2  (vulnerable: CVE-2026-0001, fixed in 0.19.5)\n") || !strings.Contains(out, "0.21.0\n") {
+			t.Fatalf("unexpected list output %q", out)
+		}
+	})
+
+	t.Run("reports pinned versions", func(t *testing.T) {
+		root, client := setup(t)
+		if err := os.RemoveAll(filepath.Join(root, "versions", "0.19.2")); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, "version"), []byte("0.19.3\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		var err error
+		out := captureStdout(t, func() {
+			err = auditWithClient(client)
+		})
+		if err == nil || !strings.Contains(out, "0.19.3") || !strings.Contains(out, "global version file") {
+			t.Fatalf("unexpected result %q (%v)", out, err)
+		}
+	})
+
+	t.Run("warns about unparseable ranges", func(t *testing.T) {
+		saved := advisories
+		advisories = append(slices.Clone(saved), github.Advisory{
+			GHSAID:          "GHSA-dddd-eeee-ffff",
+			Vulnerabilities: []github.Vulnerability{{VulnerableVersionRange: "~> 0.21", PatchedVersions: "0.21.4"}},
+		})
+		defer func() { advisories = saved }()
+		_, client := setup(t)
+
+		var stderrBuf bytes.Buffer
+		oldStderr := os.Stderr
+		r, w, _ := os.Pipe()
+		os.Stderr = w
+		captureStdout(t, func() {
+			_ = auditWithClient(client)
+		})
+		_ = w.Close()
+		os.Stderr = oldStderr
+		_, _ = io.Copy(&stderrBuf, r)
+
+		want := `warning: GHSA-dddd-eeee-ffff has a version range that cannot be checked: "~> 0.21"` + "\n"
+		if got := stderrBuf.String(); got != want {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+	})
+
+	t.Run("no affected versions", func(t *testing.T) {
+		root, client := setup(t)
+		if err := os.RemoveAll(filepath.Join(root, "versions", "0.19.2")); err != nil {
+			t.Fatal(err)
+		}
+		out := captureStdout(t, func() {
+			if err := auditWithClient(client); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+		if out != "No known security advisories affect the 1 installed or pinned versions.\n" {
+			t.Fatalf("unexpected output %q", out)
+		}
+	})
+}
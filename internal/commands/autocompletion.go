@@ -11,7 +11,7 @@ func Autocompletion() error {
     COMPREPLY=()
     cur="${COMP_WORDS[COMP_CWORD]}"
     prev="${COMP_WORDS[COMP_CWORD-1]}"
-    opts="help list list-remote init install uninstall shell local global latest which exec status upgrade version"
+    opts="help list list-remote init install uninstall import shell local global alias latest which resolve exec status audit outdated bump check scan cache bundle mirror upgrade version"
 
     if [[ ${COMP_CWORD} -eq 1 ]] ; then
         COMPREPLY=( $(compgen -W "${opts}" -- "${cur}") )
@@ -18,7 +18,7 @@ func TestAutocompletion(t *testing.T) {
 			t.Errorf("output should contain completion definition, got: %q", output)
 		}
 
-		if !strings.Contains(output, "opts=\"help list list-remote init install uninstall shell local global latest which exec status upgrade version\"") {
+		if !strings.Contains(output, "opts=\"help list list-remote init install uninstall import shell local global alias latest which resolve exec status audit outdated bump check scan cache bundle mirror upgrade version\"") {
 			t.Errorf("output should contain subcommands list, got: %q", output)
 		}
 	})
@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/user/vc-env/internal/config"
+	"github.com/user/vc-env/internal/github"
+	"github.com/user/vc-env/internal/pins"
+	"github.com/user/vc-env/internal/semver"
+)
+
+// Bump modes, selecting how far a pin may move.
+const (
+	BumpLatest = "latest"
+	BumpMinor  = "minor"
+	BumpPatch  = "patch"
+)
+
+// BumpHelp prints the help message for the bump command.
+func BumpHelp() {
+	fmt.Println(`Usage: vc-env bump [--patch|--minor|--to VERSION] [flags] [PATH...]
+
+Rewrite the version pinned by the .vcluster-version and .tool-versions files
+in each PATH (default: the current directory) to the newest matching stable
+release, and print a diff-style summary of the changes.
+
+Only the preferred version of a file, the first one listed, is changed;
+fallback versions, comments and other tools are kept.  Pins are never moved
+to an older release, except with --to, and releases denied by the version
+policy (VCENV_POLICY) are skipped.  Pins that are not release versions, such
+as aliases and custom builds, are left alone.
+
+Flags:
+  --patch          Move to the newest patch release of the same minor line
+  --minor          Move to the newest minor release of the same major line
+  --to VERSION     Move to VERSION, which must be a known release
+  -r, --recursive  Update every pin under each PATH, e.g. in a monorepo,
+                   skipping hidden directories, node_modules and paths
+                   ignored by .gitignore
+  --install        Install the new versions
+  -h, --help       Show this help message`)
+}
+
+// Bump updates the version pins in paths.  mode is one of BumpLatest,
+// BumpMinor and BumpPatch; a non-empty to moves every pin to that version
+// instead.
+func Bump(paths []string, mode, to string, recursive, install bool) error {
+	return bumpWithClient(github.NewClient(), paths, mode, to, recursive, install)
+}
+
+// bumpWithClient is the testable core of Bump.
+func bumpWithClient(client *github.Client, paths []string, mode, to string, recursive, install bool) error {
+	if err := config.RequireInit(); err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	found, err := pins.Find(paths, recursive)
+	if err != nil {
+		return err
+	}
+	if len(found) == 0 {
+		return fmt.Errorf("no .vcluster-version or .tool-versions file found")
+	}
+
+	stable, _, err := getRemoteVersions(client)
+	if err != nil {
+		return err
+	}
+	if to != "" {
+		if !slices.Contains(stable, to) {
+			return fmt.Errorf("version %s is not a known release", to)
+		}
+		if err := checkPolicy(to); err != nil {
+			return err
+		}
+	}
+	p, err := loadPolicy()
+	if err != nil {
+		return err
+	}
+
+	bumped := 0
+	var toInstall []string
+	for _, pin := range found {
+		current := pin.Versions[0]
+		if !semver.IsValid(current) {
+			continue
+		}
+		target := to
+		if target == "" {
+			target = bumpTarget(stable, current, mode, func(v string) bool {
+				return p == nil || p.Check(v) == nil
+			})
+		}
+		if target == "" || target == current {
+			continue
+		}
+
+		data, err := os.ReadFile(pin.Path)
+		if err != nil {
+			return err
+		}
+		info, err := os.Stat(pin.Path)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(pin.Path, pin.Replace(data, target), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to update %s: %w", pin.Path, err)
+		}
+		fmt.Printf("%s\n-%s\n+%s\n", pin.Path, current, target)
+		bumped++
+		if !slices.Contains(toInstall, target) {
+			toInstall = append(toInstall, target)
+		}
+	}
+
+	if bumped == 0 {
+		fmt.Println("All pins are up to date.")
+		return nil
+	}
+	fmt.Printf("Bumped %d pin(s).\n", bumped)
+	if install {
+		for _, v := range toInstall {
+			if err := installWithClient(client, v, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// bumpTarget returns the newest of releases, which are sorted newest first,
+// that is newer than current, allowed, and within the range selected by
+// mode.  It returns "" if there is none.
+func bumpTarget(releases []string, current, mode string, allowed func(string) bool) string {
+	c := semver.Parse(current)
+	for _, r := range releases {
+		v := semver.Parse(r)
+		if !semver.Less(c, v) {
+			break
+		}
+		if mode == BumpPatch && (v.Major != c.Major || v.Minor != c.Minor) {
+			continue
+		}
+		if mode == BumpMinor && v.Major != c.Major {
+			continue
+		}
+		if allowed(r) {
+			return r
+		}
+	}
+	return ""
+}
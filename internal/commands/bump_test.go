@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/github"
+)
+
+func TestBump(t *testing.T) {
+	setup := func(t *testing.T) string {
+		t.Helper()
+		tmpDir := t.TempDir()
+		t.Setenv("VCENV_ROOT", tmpDir)
+		t.Setenv("VCENV_POLICY", "")
+		if err := os.MkdirAll(filepath.Join(tmpDir, "versions"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		releases := []string{"1.0.0", "0.22.1", "0.22.0", "0.21.3", "0.21.0"}
+		if err := cache.NewWithTTL(filepath.Join(tmpDir, "cache"), time.Hour).Save(releases, releases); err != nil {
+			t.Fatal(err)
+		}
+		projects := t.TempDir()
+		for path, content := range map[string]string{
+			".vcluster-version":       "0.21.0\n",
+			"svc/a/.tool-versions":    "golang 1.24.4\nvcluster 0.21.0 # pinned\n",
+			"svc/b/.vcluster-version": "prod\n",
+		} {
+			path = filepath.Join(projects, path)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return projects
+	}
+	client := &github.Client{BaseURL: "http://127.0.0.1:0"}
+	readFile := func(t *testing.T, path string) string {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	t.Run("patch bump of the top-level pin", func(t *testing.T) {
+		projects := setup(t)
+		out := captureStdout(t, func() {
+			if err := bumpWithClient(client, []string{projects}, BumpPatch, "", false, false); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+		path := filepath.Join(projects, ".vcluster-version")
+		if want := path + "\n-0.21.0\n+0.21.3\nBumped 1 pin(s).\n"; out != want {
+			t.Fatalf("expected %q, got %q", want, out)
+		}
+		if got := readFile(t, path); got != "0.21.3\n" {
+			t.Fatalf("unexpected content %q", got)
+		}
+		if got := readFile(t, filepath.Join(projects, "svc", "a", ".tool-versions")); !strings.Contains(got, "vcluster 0.21.0") {
+			t.Fatalf("expected the nested pin to be left alone without --recursive, got %q", got)
+		}
+	})
+
+	t.Run("recursive minor bump", func(t *testing.T) {
+		projects := setup(t)
+		captureStdout(t, func() {
+			if err := bumpWithClient(client, []string{projects}, BumpMinor, "", true, false); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+		if got := readFile(t, filepath.Join(projects, "svc", "a", ".tool-versions")); got != "golang 1.24.4\nvcluster 0.22.1 # pinned\n" {
+			t.Fatalf("unexpected content %q", got)
+		}
+		if got := readFile(t, filepath.Join(projects, "svc", "b", ".vcluster-version")); got != "prod\n" {
+			t.Fatalf("expected the alias pin to be left alone, got %q", got)
+		}
+	})
+
+	t.Run("skips versions denied by policy", func(t *testing.T) {
+		projects := setup(t)
+		t.Setenv("VCENV_POLICY", filepath.Join(projects, "policy.json"))
+		if err := os.WriteFile(filepath.Join(projects, "policy.json"), []byte(`{"denied": [{"version": "1.0.0"}]}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		captureStdout(t, func() {
+			if err := bumpWithClient(client, []string{projects}, BumpLatest, "", false, false); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+		if got := readFile(t, filepath.Join(projects, ".vcluster-version")); got != "0.22.1\n" {
+			t.Fatalf("unexpected content %q", got)
+		}
+	})
+
+	t.Run("--to requires a known release", func(t *testing.T) {
+		projects := setup(t)
+		if err := bumpWithClient(client, []string{projects}, BumpLatest, "0.21.10", false, false); err == nil {
+			t.Fatal("expected an error for an unknown release")
+		}
+		captureStdout(t, func() {
+			if err := bumpWithClient(client, []string{projects}, BumpLatest, "0.22.0", false, false); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+		if got := readFile(t, filepath.Join(projects, ".vcluster-version")); got != "0.22.0\n" {
+			t.Fatalf("unexpected content %q", got)
+		}
+	})
+}
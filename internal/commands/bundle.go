@@ -0,0 +1,271 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/user/vc-env/internal/archive"
+	"github.com/user/vc-env/internal/bundle"
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/config"
+	"github.com/user/vc-env/internal/github"
+	"github.com/user/vc-env/internal/platform"
+)
+
+// defaultBundleFile is the output file of "bundle create" when -o is omitted.
+const defaultBundleFile = "vcenv-bundle.tar.gz"
+
+// BundleHelp prints the help message for the bundle command.
+func BundleHelp() {
+	fmt.Println(`Usage: vc-env bundle create --versions V1[,V2...] [--platform OS/ARCH[,...]] [-o FILE]
+       vc-env bundle install FILE
+
+Export vcluster versions into a single archive and install them on machines
+without network access.
+
+A bundle contains the binaries, the upstream checksums.txt of every version,
+a manifest with the SHA-256 digest of each binary, and a snapshot of the
+release list.  "bundle install" verifies all of them before installing the
+binaries for the current platform into $VCENV_ROOT/versions and adding every
+artifact to the download cache.
+
+Flags for create:
+  --versions     Comma-separated list of versions to include (required)
+  --platform     Comma-separated list of platforms (default: current platform)
+  -o, --output   Output file (default: vcenv-bundle.tar.gz)
+  -h, --help     Show this help message`)
+}
+
+// BundleCreate writes an offline bundle of the given versions and platforms.
+func BundleCreate(versions, platforms []string, output string) error {
+	return bundleCreateWithClient(github.NewClient(), versions, platforms, output)
+}
+
+// bundleCreateWithClient is the testable core of BundleCreate.
+func bundleCreateWithClient(client *github.Client, versions, platforms []string, output string) error {
+	if len(versions) == 0 {
+		return fmt.Errorf("no versions specified. Usage: vc-env bundle create --versions V1[,V2...]")
+	}
+	if output == "" {
+		output = defaultBundleFile
+	}
+
+	infos, err := parsePlatforms(platforms)
+	if err != nil {
+		return err
+	}
+
+	var items []bundle.Item
+	for _, version := range versions {
+		for _, info := range infos {
+			item, err := collectBundleItem(client, version, info)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Added vcluster %s for %s/%s\n", version, info.OS, info.Arch)
+			items = append(items, item)
+		}
+	}
+
+	stable, pre, err := getRemoteVersions(client)
+	if err != nil {
+		return err
+	}
+	releases := &bundle.Releases{Versions: stable, PrereleaseVersions: pre, FetchedAt: time.Now().UTC()}
+	if fetchedAt, ok := newCacheForRoot().FetchedAt(); ok {
+		releases.FetchedAt = fetchedAt
+	}
+
+	dir := filepath.Dir(output)
+	tmp, err := os.CreateTemp(dir, ".vcenv-bundle-*")
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := bundle.Write(tmp, items, releases, "vc-env "+Version); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), output); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	fmt.Printf("Wrote %s (%d artifacts)\n", output, len(items))
+	return nil
+}
+
+// parsePlatforms parses "os/arch" strings, defaulting to the current
+// platform when none are given.
+func parsePlatforms(platforms []string) ([]platform.Info, error) {
+	if len(platforms) == 0 {
+		info, err := platform.Detect()
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect platform: %w", err)
+		}
+		return []platform.Info{info}, nil
+	}
+
+	infos := make([]platform.Info, 0, len(platforms))
+	for _, p := range platforms {
+		info, err := platform.Parse(p)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// collectBundleItem returns a verified artifact for version and info, taken
+// from the download cache, the installed binary, or a fresh download (in
+// that order).  Artifacts that cannot be verified against the upstream
+// checksums are refused.
+func collectBundleItem(client *github.Client, version string, info platform.Info) (bundle.Item, error) {
+	item := bundle.Item{Version: version, Platform: info, Name: platform.BinaryName(info)}
+
+	store := newDownloadStoreForRoot()
+	if artifact, ok := store.Get(version, platform.Key(info)); ok && len(artifact.Checksums) > 0 {
+		item.Name, item.Data, item.Checksums = artifact.Name, artifact.Data, artifact.Checksums
+		return item, nil
+	}
+
+	offline, offlineReason := offlineMode()
+	if offline {
+		return item, fmt.Errorf("cannot bundle vcluster %s for %s/%s: %s and it is not in the download cache", version, info.OS, info.Arch, offlineReason)
+	}
+
+	asset, err := resolveReleaseAsset(client, version, info)
+	if err != nil {
+		return item, err
+	}
+	item.Name = asset.name
+
+	// Fall back to the installed binary for the current platform, unless
+	// the release ships it as an archive.  It is still checked against the
+	// upstream checksums by bundle.Write.
+	if host, err := platform.Detect(); err == nil && host == info && asset.checksumName != "" && !archive.IsArchive(asset.name) {
+		if binaryPath, err := config.GetBinaryPath(version); err == nil {
+			if data, err := os.ReadFile(binaryPath); err == nil {
+				checksums, err := client.DownloadBinary(client.DownloadURL(platform.ReleaseAssetPath(version, asset.checksumName)))
+				recordNetworkResult(err)
+				if err == nil {
+					item.Data, item.Checksums = data, checksums
+					return item, nil
+				}
+			}
+		}
+	}
+
+	artifact, err := downloadAsset(client, version, info, asset, true)
+	if err != nil {
+		return item, err
+	}
+	if artifact.Checksums == nil {
+		return item, fmt.Errorf("cannot bundle vcluster %s for %s/%s: no upstream checksum available", version, info.OS, info.Arch)
+	}
+	if err := store.Put(version, platform.Key(info), artifact); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not write download cache: %v\n", err)
+	}
+
+	item.Name, item.Data, item.Checksums = artifact.Name, artifact.Data, artifact.Checksums
+	return item, nil
+}
+
+// BundleInstall verifies a bundle, installs the binaries for the current
+// platform and adds every artifact and the release snapshot to the caches.
+func BundleInstall(file string) error {
+	if err := config.RequireInit(); err != nil {
+		return err
+	}
+	if file == "" {
+		return fmt.Errorf("bundle file not specified. Usage: vc-env bundle install FILE")
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer f.Close()
+
+	contents, err := bundle.Read(f)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Bundle verified: %d artifacts\n", len(contents.Items))
+
+	host, err := platform.Detect()
+	if err != nil {
+		return fmt.Errorf("failed to detect platform: %w", err)
+	}
+
+	store := newDownloadStoreForRoot()
+	installedAny := false
+	for _, it := range contents.Items {
+		artifact := cache.Artifact{Name: it.Name, Data: it.Data, Checksums: it.Checksums}
+		if err := store.Put(it.Version, platform.Key(it.Platform), artifact); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: could not write download cache: %v\n", err)
+		}
+
+		if it.Platform != host {
+			continue
+		}
+		installedAny = true
+		installed, err := config.IsVersionInstalled(it.Version)
+		if err != nil {
+			return err
+		}
+		if installed {
+			fmt.Printf("version %s already installed skipping\n", it.Version)
+			continue
+		}
+		if err := checkPolicy(it.Version); err != nil {
+			return err
+		}
+		if err := installArtifact(it.Version, artifact, false); err != nil {
+			return err
+		}
+	}
+	if !installedAny {
+		fmt.Printf("Bundle contains no binaries for %s/%s; artifacts were added to the download cache only\n", host.OS, host.Arch)
+	}
+
+	if contents.Releases != nil {
+		if err := importReleaseSnapshot(contents.Releases); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: could not import release list: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// importReleaseSnapshot merges a bundled release list into the disk cache.
+// An existing cache keeps its own fetch time so that it is refreshed on its
+// usual schedule once the network is available again.
+func importReleaseSnapshot(rel *bundle.Releases) error {
+	c := newCacheForRoot()
+	stable, pre, ok := c.LoadStale()
+	if ok {
+		fetchedAt, _ := c.FetchedAt()
+		return c.SaveFetchedAt(
+			cache.MergeWithCached(stable, rel.Versions),
+			cache.MergeWithCached(pre, rel.PrereleaseVersions),
+			fetchedAt,
+		)
+	}
+	fetchedAt := rel.FetchedAt
+	if fetchedAt.IsZero() {
+		// Unknown age: store it as long expired so it is refreshed first
+		// chance, but still readable as a stale cache.
+		fetchedAt = time.Unix(0, 0)
+	}
+	return c.SaveFetchedAt(
+		cache.MergeWithBaseline(rel.Versions, false),
+		cache.MergeWithBaseline(rel.PrereleaseVersions, true),
+		fetchedAt,
+	)
+}
@@ -0,0 +1,192 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/user/vc-env/internal/bundle"
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/github"
+	"github.com/user/vc-env/internal/platform"
+)
+
+// newReleaseServer returns a test server that serves binaries (whose
+// content is "<version> <asset>") and matching checksums.txt files.
+func newReleaseServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/repos/") {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte("[]"))
+			return
+		}
+		parts := strings.Split(r.URL.Path, "/")
+		if len(parts) < 2 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		tag, asset := parts[len(parts)-2], parts[len(parts)-1]
+		if asset == "checksums.txt" {
+			for _, p := range []string{"linux-amd64", "linux-arm64", "darwin-amd64", "darwin-arm64"} {
+				name := "vcluster-" + p
+				fmt.Fprintf(w, "%s  %s\n", cache.SHA256([]byte(tag+" "+name)), name)
+			}
+			return
+		}
+		_, _ = w.Write([]byte(tag + " " + asset))
+	}))
+}
+
+func TestBundleCreateAndInstall(t *testing.T) {
+	server := newReleaseServer(t)
+	defer server.Close()
+	client := &github.Client{
+		BaseURL:         server.URL,
+		DownloadBaseURL: server.URL,
+		HTTPClient:      server.Client(),
+	}
+
+	host, err := platform.Detect()
+	if err != nil {
+		t.Skipf("unsupported platform: %v", err)
+	}
+	other := platform.Info{OS: "darwin", Arch: "arm64"}
+	if host == other {
+		other = platform.Info{OS: "linux", Arch: "amd64"}
+	}
+
+	// Create the bundle on a "connected" machine.
+	src := t.TempDir()
+	t.Setenv("VCENV_ROOT", src)
+	t.Setenv("VCENV_DOWNLOAD_CACHE_DIR", "")
+	output := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	platforms := []string{host.OS + "/" + host.Arch, other.OS + "/" + other.Arch}
+
+	_ = captureStdout(t, func() {
+		if err := bundleCreateWithClient(client, []string{"0.21.1", "0.22.0"}, platforms, output); err != nil {
+			t.Fatalf("bundle create: %v", err)
+		}
+	})
+
+	// Install it on an "airgapped" machine.
+	dst := t.TempDir()
+	t.Setenv("VCENV_ROOT", dst)
+	t.Setenv("VCENV_OFFLINE", "1")
+	if err := os.MkdirAll(filepath.Join(dst, "versions"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := BundleInstall(output); err != nil {
+			t.Fatalf("bundle install: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Bundle verified: 4 artifacts") {
+		t.Errorf("expected verification message, got %q", out)
+	}
+
+	for _, v := range []string{"0.21.1", "0.22.0"} {
+		data, err := os.ReadFile(filepath.Join(dst, "versions", v, "vcluster"))
+		if err != nil {
+			t.Fatalf("version %s not installed: %v", v, err)
+		}
+		if want := "v" + v + " " + platform.BinaryName(host); string(data) != want {
+			t.Fatalf("unexpected binary for %s: %q", v, data)
+		}
+	}
+
+	// Artifacts for the other platform land in the download cache.
+	if _, ok := newDownloadStoreForRoot().Get("0.22.0", platform.Key(other)); !ok {
+		t.Fatalf("expected %s artifact in the download cache", platform.Key(other))
+	}
+
+	// The release snapshot is imported into the release cache.
+	if _, _, ok := newCacheForRoot().LoadStale(); !ok {
+		t.Fatal("expected release cache to be populated from the bundle")
+	}
+}
+
+func TestBundleCreate_AssetPattern(t *testing.T) {
+	t.Setenv("VCENV_ASSET_PATTERN", "vcluster_{version}_{os}_{arch}{ext}")
+	binary := []byte("custom vcluster")
+	server := newAssetReleaseServer(t, "0.22.0", map[string][]byte{
+		"vcluster_0.22.0_linux_amd64": binary,
+		"vcluster_checksums.txt":      []byte(cache.SHA256(binary) + "  vcluster_0.22.0_linux_amd64\n"),
+	}, nil)
+	defer server.Close()
+	client := &github.Client{BaseURL: server.URL, DownloadBaseURL: server.URL, HTTPClient: server.Client()}
+
+	t.Setenv("VCENV_ROOT", t.TempDir())
+	t.Setenv("VCENV_DOWNLOAD_CACHE_DIR", "")
+
+	// The second bundle is built from the download cache.
+	for range 2 {
+		output := filepath.Join(t.TempDir(), "bundle.tar.gz")
+		_ = captureStdout(t, func() {
+			if err := bundleCreateWithClient(client, []string{"0.22.0"}, []string{"linux/amd64"}, output); err != nil {
+				t.Fatalf("bundle create: %v", err)
+			}
+		})
+
+		f, err := os.Open(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents, err := bundle.Read(f)
+		_ = f.Close()
+		if err != nil {
+			t.Fatalf("bundle read: %v", err)
+		}
+		if len(contents.Items) != 1 || contents.Items[0].Name != "vcluster_0.22.0_linux_amd64" || string(contents.Items[0].Data) != string(binary) {
+			t.Fatalf("unexpected bundle items %+v", contents.Items)
+		}
+	}
+}
+
+func TestBundleCreate_MixedChecksums(t *testing.T) {
+	server := newReleaseServer(t)
+	defer server.Close()
+	client := &github.Client{BaseURL: server.URL, DownloadBaseURL: server.URL, HTTPClient: server.Client()}
+
+	t.Setenv("VCENV_ROOT", t.TempDir())
+	t.Setenv("VCENV_DOWNLOAD_CACHE_DIR", "")
+
+	// The arm64 binary was cached after being verified against the API
+	// digest only, so its checksums.txt lists just that binary.
+	data := []byte("v0.21.1 vcluster-linux-arm64")
+	artifact := cache.Artifact{Name: "vcluster-linux-arm64", Data: data, Checksums: []byte(cache.SHA256(data) + "  vcluster-linux-arm64\n")}
+	if err := newDownloadStoreForRoot().Put("0.21.1", "linux-arm64", artifact); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	_ = captureStdout(t, func() {
+		if err := bundleCreateWithClient(client, []string{"0.21.1"}, []string{"linux/amd64", "linux/arm64"}, output); err != nil {
+			t.Fatalf("bundle create: %v", err)
+		}
+	})
+
+	f, err := os.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	contents, err := bundle.Read(f)
+	if err != nil {
+		t.Fatalf("bundle read: %v", err)
+	}
+	if len(contents.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(contents.Items))
+	}
+}
+
+func TestBundleCreate_RequiresVersions(t *testing.T) {
+	if err := BundleCreate(nil, nil, ""); err == nil {
+		t.Fatal("expected error without versions")
+	}
+}
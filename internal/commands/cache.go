@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+)
+
+// CacheHelp prints the help message for the cache command.
+func CacheHelp() {
+	fmt.Println(`Usage: vc-env cache <subcommand> [flags]
+
+Inspect or clear the caches kept under $VCENV_ROOT/cache.
+
+Subcommands:
+  status        Show the release list cache and the download cache
+  clear         Remove cached data
+
+Flags for clear:
+  --releases    Only clear the release list cache
+  --downloads   Only clear the download cache
+  -h, --help    Show this help message
+
+The download cache keeps verified vcluster binaries and their checksums.txt so
+that reinstalling a version needs no network access.  Its size is capped by
+VCENV_DOWNLOAD_CACHE_SIZE (default 1GB); least recently used entries are
+evicted first.  Set VCENV_DOWNLOAD_CACHE_DIR to share it between roots.`)
+}
+
+// CacheStatus prints the state of the release list cache and the download
+// cache.
+func CacheStatus() error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+
+	c := newCacheForRoot()
+	if c.Dir() == "" {
+		fmt.Fprintf(w, "Release cache:\tdisabled (VCENV_ROOT is not set)\n")
+	} else if fetchedAt, ok := c.FetchedAt(); ok {
+		state := "fresh"
+		if time.Since(fetchedAt) > c.TTL() {
+			state = "stale"
+		}
+		fmt.Fprintf(w, "Release cache:\t%s\n", filepath.Join(c.Dir(), "releases.json"))
+		fmt.Fprintf(w, "  Fetched at:\t%s (%s, TTL %s)\n", fetchedAt.Local().Format(time.RFC3339), state, c.TTL())
+	} else {
+		fmt.Fprintf(w, "Release cache:\tempty\n")
+	}
+
+	store := newDownloadStoreForRoot()
+	if store.Dir() == "" {
+		fmt.Fprintf(w, "Download cache:\tdisabled (VCENV_ROOT is not set)\n")
+		return w.Flush()
+	}
+	count, size, err := store.Usage()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Download cache:\t%s\n", store.Dir())
+	fmt.Fprintf(w, "  Artifacts:\t%d\n", count)
+	fmt.Fprintf(w, "  Size:\t%s of %s\n", formatBytes(size), formatBytes(store.MaxBytes()))
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	entries, err := store.Entries()
+	if err != nil {
+		return err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		fmt.Printf("\t%s\t%s\t%s\n", e.Version, e.PlatformKey, formatBytes(e.Size))
+	}
+	return nil
+}
+
+// CacheClear removes cached data.  With both flags false everything is
+// cleared.
+func CacheClear(releases, downloads bool) error {
+	if !releases && !downloads {
+		releases, downloads = true, true
+	}
+
+	if releases {
+		if err := newCacheForRoot().Clear(); err != nil {
+			return err
+		}
+		fmt.Println("Release cache cleared")
+	}
+	if downloads {
+		if err := newDownloadStoreForRoot().Clear(); err != nil {
+			return err
+		}
+		fmt.Println("Download cache cleared")
+	}
+	return nil
+}
+
+// formatBytes renders a byte count using binary units.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/user/vc-env/internal/cache"
+)
+
+func TestCacheStatusAndClear(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("VCENV_ROOT", root)
+	t.Setenv("VCENV_DOWNLOAD_CACHE_DIR", "")
+
+	if err := cache.New(filepath.Join(root, "cache")).Save([]string{"0.21.1"}, []string{"0.21.1"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	store := cache.NewDownloadStore(filepath.Join(root, "cache", "downloads"))
+	if err := store.Put("0.21.1", "linux-amd64", cache.Artifact{Name: "vcluster-linux-amd64", Data: []byte("binary")}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	t.Run("status lists both caches", func(t *testing.T) {
+		output := captureStdout(t, func() {
+			if err := CacheStatus(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+		if !strings.Contains(output, "releases.json") || !strings.Contains(output, "fresh") {
+			t.Errorf("expected release cache details, got %q", output)
+		}
+		if !strings.Contains(output, "Artifacts:") || !strings.Contains(output, "0.21.1") {
+			t.Errorf("expected download cache details, got %q", output)
+		}
+	})
+
+	t.Run("clear downloads keeps release cache", func(t *testing.T) {
+		_ = captureStdout(t, func() {
+			if err := CacheClear(false, true); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+		if count, _, _ := store.Usage(); count != 0 {
+			t.Fatalf("expected empty download cache, got %d entries", count)
+		}
+		if _, err := os.Stat(filepath.Join(root, "cache", "releases.json")); err != nil {
+			t.Fatalf("expected release cache to survive: %v", err)
+		}
+	})
+
+	t.Run("clear without flags removes everything", func(t *testing.T) {
+		_ = captureStdout(t, func() {
+			if err := CacheClear(false, false); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+		if _, err := os.Stat(filepath.Join(root, "cache", "releases.json")); !os.IsNotExist(err) {
+			t.Fatalf("expected release cache to be removed, got %v", err)
+		}
+	})
+}
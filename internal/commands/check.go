@@ -0,0 +1,364 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/user/vc-env/internal/archive"
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/config"
+	"github.com/user/vc-env/internal/github"
+	"github.com/user/vc-env/internal/pins"
+	"github.com/user/vc-env/internal/platform"
+	"github.com/user/vc-env/internal/semver"
+)
+
+// Output formats of the check command.
+const (
+	CheckFormatText  = "text"
+	CheckFormatJSON  = "json"
+	CheckFormatSARIF = "sarif"
+)
+
+// checkRules are the rules a pin is checked against, in the order they are
+// applied, with their descriptions.
+var checkRules = []struct {
+	id          string
+	description string
+}{
+	{"malformed-version", "The pin is not a semantic version"},
+	{"unknown-version", "The pinned version is not a vcluster release"},
+	{"prerelease", "The pinned version is a pre-release"},
+	{"denied-version", "The pinned version is denied by the version policy"},
+	{"missing-asset", "The pinned release has no binary for a required platform"},
+	{"digest-mismatch", "A local binary of the pinned version differs from the release asset"},
+}
+
+// pinFinding is a pin that failed a check rule.
+type pinFinding struct {
+	Path    string `json:"path"`
+	Line    int    `json:"line"`
+	Version string `json:"version"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// CheckHelp prints the help message for the check command.
+func CheckHelp() {
+	fmt.Println(`Usage: vc-env check [flags] [PATH...]
+
+Find every .vcluster-version and .tool-versions file under each PATH (default:
+the current directory), skipping hidden directories, node_modules and paths
+ignored by .gitignore, and validate each pinned version, including
+fallbacks.  A pin fails when it:
+
+  malformed-version  is not a semantic version, e.g. an alias
+  unknown-version    is not a vcluster release; pins missing from the cached
+                     release list are looked up on GitHub, and only warned
+                     about offline
+  prerelease         is a pre-release
+  denied-version     is denied by the version policy (VCENV_POLICY)
+  missing-asset      has no binary for a platform given with --platform
+  digest-mismatch    is installed from a release, or held in the download
+                     cache, with a SHA-256 digest that differs from the
+                     digest GitHub reports for the release asset; custom
+                     versions named with --as and archive assets are not
+                     compared
+
+Exits with status 1 when a pin fails.
+
+Flags:
+  --format FORMAT  Output format: text (default), json or sarif
+  --platform       Comma-separated list of platforms that must have a binary,
+                   e.g. linux/amd64,darwin/arm64
+  -h, --help       Show this help message`)
+}
+
+// Check validates the version pins found under paths.
+func Check(paths []string, format string, platforms []string) error {
+	return checkWithClient(github.NewClient(), paths, format, platforms)
+}
+
+// checkWithClient is the testable core of Check.
+func checkWithClient(client *github.Client, paths []string, format string, platforms []string) error {
+	if format == "" {
+		format = CheckFormatText
+	}
+	if format != CheckFormatText && format != CheckFormatJSON && format != CheckFormatSARIF {
+		return fmt.Errorf("invalid format %q: use text, json or sarif", format)
+	}
+	var infos []platform.Info
+	if len(platforms) > 0 {
+		var err error
+		if infos, err = parsePlatforms(platforms); err != nil {
+			return err
+		}
+	}
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	found, err := pins.Find(paths, true)
+	if err != nil {
+		return err
+	}
+
+	_, releases, err := getRemoteVersions(client)
+	if err != nil {
+		return err
+	}
+	p, err := loadPolicy()
+	if err != nil {
+		return err
+	}
+
+	downloads, err := newDownloadStoreForRoot().Entries()
+	if err != nil {
+		return err
+	}
+	fetched := map[string]*github.Release{}
+	getRelease := func(v string) (*github.Release, error) {
+		if release, ok := fetched[v]; ok {
+			return release, nil
+		}
+		release, err := client.GetRelease(v)
+		recordNetworkResult(err)
+		if err != nil {
+			return nil, err
+		}
+		fetched[v] = release
+		return release, nil
+	}
+
+	checked := 0
+	var findings []pinFinding
+	for _, pin := range found {
+		data, err := os.ReadFile(pin.Path)
+		if err != nil {
+			return err
+		}
+		for _, v := range pin.Versions {
+			checked++
+			finding := pinFinding{Path: pin.Path, Line: pinLine(data, v), Version: v}
+			fail := func(rule, msg string, args ...any) {
+				finding.Rule, finding.Message = rule, fmt.Sprintf(msg, args...)
+				findings = append(findings, finding)
+			}
+
+			if !semver.IsValid(v) {
+				fail("malformed-version", "%q is not a semantic version", v)
+				continue
+			}
+			v = strings.TrimPrefix(v, "v")
+			if !slices.Contains(releases, v) {
+				// The release list may come from a cache or the baseline
+				// and miss recent releases, so the release is looked up.
+				if offline, reason := offlineMode(); offline {
+					fmt.Fprintf(os.Stderr, "warning: cannot confirm that vcluster %s is a release: %s\n", v, reason)
+					continue
+				}
+				if _, err := getRelease(v); errors.Is(err, github.ErrReleaseNotFound) {
+					fail("unknown-version", "vcluster %s is not a release", v)
+					continue
+				} else if err != nil {
+					fmt.Fprintf(os.Stderr, "warning: cannot confirm that vcluster %s is a release: %v\n", v, err)
+					continue
+				}
+			}
+			if semver.Parse(v).PreRelease != "" {
+				fail("prerelease", "vcluster %s is a pre-release", v)
+			}
+			if p != nil {
+				if violation := p.Check(v); violation != nil {
+					fail("denied-version", "%v", violation)
+				}
+			}
+			if len(infos) > 0 {
+				release, err := getRelease(v)
+				if err != nil {
+					return fmt.Errorf("failed to fetch the assets of vcluster %s: %w", v, err)
+				}
+				names := release.AssetNames()
+				for _, info := range infos {
+					if _, ok := platform.MatchAsset(names, v, info); !ok {
+						fail("missing-asset", "vcluster %s has no binary for %s/%s", v, info.OS, info.Arch)
+					}
+				}
+			}
+
+			local, err := localDigests(v, downloads)
+			if err != nil {
+				return err
+			}
+			if len(local) == 0 {
+				continue
+			}
+			release, err := getRelease(v)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: skipping the digest check of vcluster %s: %v\n", v, err)
+				continue
+			}
+			for _, d := range local {
+				name := d.asset
+				if name == "" {
+					info, err := platform.Detect()
+					if err != nil {
+						continue
+					}
+					var ok bool
+					if name, ok = platform.MatchAsset(release.AssetNames(), v, info); !ok {
+						continue
+					}
+					// An installed binary extracted from an archive cannot
+					// be compared with the archive's digest.
+					if archive.IsArchive(name) {
+						continue
+					}
+				}
+				asset, ok := release.Asset(name)
+				if ok && asset.Digest != "" && !strings.EqualFold(asset.Digest, d.digest) {
+					fail("digest-mismatch", "the %s of vcluster %s has digest %s, but release asset %s has %s",
+						d.what, v, d.digest, name, asset.Digest)
+				}
+			}
+		}
+	}
+
+	switch format {
+	case CheckFormatJSON:
+		err = writeJSON(map[string]any{"checked": checked, "findings": nonNil(findings)})
+	case CheckFormatSARIF:
+		err = writeJSON(sarifReport(findings))
+	default:
+		for _, f := range findings {
+			fmt.Printf("%s:%d: %s: %s\n", f.Path, f.Line, f.Rule, f.Message)
+		}
+		fmt.Printf("Checked %d pin(s) in %d file(s): %d problem(s).\n", checked, len(found), len(findings))
+	}
+	if err != nil {
+		return err
+	}
+	if len(findings) > 0 {
+		failed := map[[2]string]bool{}
+		for _, f := range findings {
+			failed[[2]string{f.Path, f.Version}] = true
+		}
+		return fmt.Errorf("%d pin(s) failed the check", len(failed))
+	}
+	return nil
+}
+
+// localDigest is the digest of a binary of a release held on this machine.
+type localDigest struct {
+	what   string // description of the binary, e.g. "installed binary"
+	asset  string // name of the release asset it should match, or empty for the current platform's
+	digest string // "sha256:<hex>"
+}
+
+// localDigests returns the digests of the binaries of version held on this
+// machine: the installed binary, which is compared with the release asset
+// for the current platform, and the artifacts of the download cache.
+// Custom versions installed under a release's name, which record their
+// origin in version metadata, are not release binaries and are skipped.
+func localDigests(version string, downloads []cache.DownloadEntry) ([]localDigest, error) {
+	var digests []localDigest
+	versionDir, err := config.GetVersionDir(version)
+	if err != nil {
+		return nil, err
+	}
+	_, custom, err := config.ReadVersionMeta(versionDir)
+	if err != nil {
+		return nil, fmt.Errorf("vcluster %s: %w", version, err)
+	}
+	if !custom {
+		if data, err := os.ReadFile(filepath.Join(versionDir, "vcluster")); err == nil {
+			digests = append(digests, localDigest{what: "installed binary", digest: cache.Digest(data)})
+		}
+	}
+	for _, e := range downloads {
+		if e.Version == version && e.Digest != "" {
+			digests = append(digests, localDigest{
+				what:   "cached download for " + e.PlatformKey,
+				asset:  e.Name,
+				digest: e.Digest,
+			})
+		}
+	}
+	return digests, nil
+}
+
+// pinLine returns the 1-based number of the first line of a version file
+// that lists version, or 1 if none does.
+func pinLine(data []byte, version string) int {
+	for i, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		if slices.Contains(strings.Fields(line), version) {
+			return i + 1
+		}
+	}
+	return 1
+}
+
+// nonNil returns findings, or an empty slice if it is nil, so that it is
+// encoded as [] rather than null.
+func nonNil(findings []pinFinding) []pinFinding {
+	if findings == nil {
+		return []pinFinding{}
+	}
+	return findings
+}
+
+// writeJSON prints v as indented JSON.
+func writeJSON(v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// sarifReport returns the findings as a SARIF 2.1.0 log, as read by code
+// scanning tools.
+func sarifReport(findings []pinFinding) map[string]any {
+	rules := make([]map[string]any, 0, len(checkRules))
+	for _, r := range checkRules {
+		rules = append(rules, map[string]any{
+			"id":               r.id,
+			"shortDescription": map[string]string{"text": r.description},
+		})
+	}
+	results := make([]map[string]any, 0, len(findings))
+	for _, f := range findings {
+		results = append(results, map[string]any{
+			"ruleId":  f.Rule,
+			"level":   "error",
+			"message": map[string]string{"text": f.Message},
+			"locations": []map[string]any{{
+				"physicalLocation": map[string]any{
+					"artifactLocation": map[string]string{"uri": filepath.ToSlash(f.Path)},
+					"region":           map[string]int{"startLine": f.Line},
+				},
+			}},
+		})
+	}
+	return map[string]any{
+		"version": "2.1.0",
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"runs": []map[string]any{{
+			"tool": map[string]any{
+				"driver": map[string]any{
+					"name":           "vc-env",
+					"version":        Version,
+					"informationUri": "https://github.com/mmpyro/vc-env",
+					"rules":          rules,
+				},
+			},
+			"results": results,
+		}},
+	}
+}
@@ -0,0 +1,235 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/config"
+	"github.com/user/vc-env/internal/github"
+	"github.com/user/vc-env/internal/platform"
+)
+
+func TestCheck(t *testing.T) {
+	setup := func(t *testing.T, files map[string]string) string {
+		t.Helper()
+		tmpDir := t.TempDir()
+		t.Setenv("VCENV_ROOT", tmpDir)
+		t.Setenv("VCENV_POLICY", "")
+		stable := []string{"0.22.0", "0.21.0"}
+		pre := []string{"0.23.0-alpha.1", "0.22.0", "0.21.0"}
+		if err := cache.NewWithTTL(filepath.Join(tmpDir, "cache"), time.Hour).Save(stable, pre); err != nil {
+			t.Fatal(err)
+		}
+		projects := t.TempDir()
+		for path, content := range files {
+			path = filepath.Join(projects, path)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return projects
+	}
+	offlineClient := &github.Client{BaseURL: "http://127.0.0.1:0"}
+
+	// 0.23.0 was released after the release list was cached.
+	releaseServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/loft-sh/vcluster/releases/tags/v0.23.0" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(github.Release{TagName: "v0.23.0"})
+	}))
+	defer releaseServer.Close()
+	releaseClient := &github.Client{BaseURL: releaseServer.URL, HTTPClient: releaseServer.Client()}
+
+	t.Run("reports failing pins", func(t *testing.T) {
+		projects := setup(t, map[string]string{
+			"a/.vcluster-version": "# pinned\n0.21.10\n",
+			"b/.vcluster-version": "0.23.0-alpha.1\n",
+			"c/.tool-versions":    "vcluster prod\n",
+			"d/.vcluster-version": "0.22.0\n0.21.0\n",
+			"e/.vcluster-version": "0.23.0\n",
+		})
+		var err error
+		out := captureStdout(t, func() {
+			err = checkWithClient(releaseClient, []string{projects}, "", nil)
+		})
+		if err == nil || err.Error() != "3 pin(s) failed the check" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, want := range []string{
+			filepath.Join(projects, "a", ".vcluster-version") + ":2: unknown-version: vcluster 0.21.10 is not a release\n",
+			filepath.Join(projects, "b", ".vcluster-version") + ":1: prerelease: vcluster 0.23.0-alpha.1 is a pre-release\n",
+			filepath.Join(projects, "c", ".tool-versions") + `:1: malformed-version: "prod" is not a semantic version` + "\n",
+			"Checked 6 pin(s) in 5 file(s): 3 problem(s).\n",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("expected %q in output %q", want, out)
+			}
+		}
+	})
+
+	t.Run("offline, unknown pins are not confirmed", func(t *testing.T) {
+		projects := setup(t, map[string]string{".vcluster-version": "0.23.0\n"})
+		t.Setenv("VCENV_OFFLINE", "1")
+
+		var stderrBuf bytes.Buffer
+		oldStderr := os.Stderr
+		r, w, _ := os.Pipe()
+		os.Stderr = w
+		out := captureStdout(t, func() {
+			if err := checkWithClient(offlineClient, []string{projects}, "", nil); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+		_ = w.Close()
+		os.Stderr = oldStderr
+		_, _ = io.Copy(&stderrBuf, r)
+
+		if !strings.Contains(out, "0 problem(s)") {
+			t.Fatalf("unexpected output %q", out)
+		}
+		if want := "warning: cannot confirm that vcluster 0.23.0 is a release: offline mode enabled\n"; !strings.Contains(stderrBuf.String(), want) {
+			t.Fatalf("expected %q on stderr, got %q", want, stderrBuf.String())
+		}
+	})
+
+	t.Run("denied versions and missing assets in SARIF", func(t *testing.T) {
+		projects := setup(t, map[string]string{".vcluster-version": "0.21.0\n"})
+		t.Setenv("VCENV_POLICY", filepath.Join(projects, "policy.json"))
+		if err := os.WriteFile(filepath.Join(projects, "policy.json"), []byte(`{"denied": [{"version": "0.21.0", "reason": "CVE-2026-0001"}]}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/repos/loft-sh/vcluster/releases/tags/v0.21.0" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(github.Release{TagName: "v0.21.0", Assets: []github.Asset{{Name: "vcluster-linux-amd64"}}})
+		}))
+		defer server.Close()
+		client := &github.Client{BaseURL: server.URL, HTTPClient: server.Client()}
+
+		var err error
+		out := captureStdout(t, func() {
+			err = checkWithClient(client, []string{projects}, CheckFormatSARIF, []string{"linux/amd64", "darwin/arm64"})
+		})
+		if err == nil {
+			t.Fatal("expected the check to fail")
+		}
+		var report struct {
+			Version string `json:"version"`
+			Runs    []struct {
+				Results []struct {
+					RuleID  string `json:"ruleId"`
+					Message struct {
+						Text string `json:"text"`
+					} `json:"message"`
+				} `json:"results"`
+			} `json:"runs"`
+		}
+		if err := json.Unmarshal([]byte(out), &report); err != nil {
+			t.Fatalf("invalid SARIF %q: %v", out, err)
+		}
+		if report.Version != "2.1.0" || len(report.Runs) != 1 || len(report.Runs[0].Results) != 2 {
+			t.Fatalf("unexpected report %+v", report)
+		}
+		results := report.Runs[0].Results
+		if results[0].RuleID != "denied-version" || results[1].RuleID != "missing-asset" ||
+			results[1].Message.Text != "vcluster 0.21.0 has no binary for darwin/arm64" {
+			t.Fatalf("unexpected results %+v", results)
+		}
+	})
+
+	t.Run("local binaries that differ from the release", func(t *testing.T) {
+		projects := setup(t, map[string]string{".vcluster-version": "0.22.0\n0.21.0\n"})
+		root := os.Getenv("VCENV_ROOT")
+		t.Setenv("VCENV_DOWNLOAD_CACHE_DIR", "")
+		info, err := platform.Detect()
+		if err != nil {
+			t.Skip(err)
+		}
+
+		// 0.22.0 is installed from a release and its arm64 binary is
+		// cached, both differing from upstream; 0.21.0 is a custom build
+		// installed under the release's name, which is not compared, and
+		// its cached binary is the upstream one.
+		writeFakeVCluster(t, filepath.Join(root, "versions", "0.22.0"), "0.22.0")
+		installed, err := os.ReadFile(filepath.Join(root, "versions", "0.22.0", "vcluster"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeFakeVCluster(t, filepath.Join(root, "versions", "0.21.0"), "0.21.0")
+		if err := config.WriteVersionMeta(filepath.Join(root, "versions", "0.21.0"), config.VersionMeta{Source: config.SourceFile}); err != nil {
+			t.Fatal(err)
+		}
+		store := newDownloadStoreForRoot()
+		for _, put := range []struct{ version, data string }{{"0.22.0", "tampered"}, {"0.21.0", "upstream"}} {
+			if err := store.Put(put.version, "linux-arm64", cache.Artifact{Name: "vcluster-linux-arm64", Data: []byte(put.data), Checksums: []byte("x")}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		upstream := cache.Digest([]byte("upstream"))
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tag := strings.TrimPrefix(r.URL.Path, "/repos/loft-sh/vcluster/releases/tags/")
+			_ = json.NewEncoder(w).Encode(github.Release{TagName: tag, Assets: []github.Asset{
+				{Name: platform.BinaryName(info), Digest: upstream},
+				{Name: "vcluster-linux-arm64", Digest: upstream},
+			}})
+		}))
+		defer server.Close()
+		client := &github.Client{BaseURL: server.URL, HTTPClient: server.Client()}
+
+		out := captureStdout(t, func() {
+			err = checkWithClient(client, []string{projects}, "", nil)
+		})
+		// One pin with two findings.
+		if err == nil || err.Error() != "1 pin(s) failed the check" {
+			t.Fatalf("unexpected error: %v\n%s", err, out)
+		}
+		pinPath := filepath.Join(projects, ".vcluster-version")
+		for _, want := range []string{
+			pinPath + ":1: digest-mismatch: the installed binary of vcluster 0.22.0 has digest " + cache.Digest(installed) +
+				", but release asset " + platform.BinaryName(info) + " has " + upstream + "\n",
+			pinPath + ":1: digest-mismatch: the cached download for linux-arm64 of vcluster 0.22.0 has digest " + cache.Digest([]byte("tampered")),
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("expected %q in output %q", want, out)
+			}
+		}
+		if strings.Contains(out, "0.21.0") {
+			t.Errorf("expected 0.21.0 to pass, got %q", out)
+		}
+	})
+
+	t.Run("passing pins as JSON", func(t *testing.T) {
+		projects := setup(t, map[string]string{".vcluster-version": "0.22.0\n"})
+		out := captureStdout(t, func() {
+			if err := checkWithClient(offlineClient, []string{projects}, CheckFormatJSON, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+		if out != "{\n  \"checked\": 1,\n  \"findings\": []\n}\n" {
+			t.Fatalf("unexpected output %q", out)
+		}
+	})
+
+	t.Run("rejects unknown formats", func(t *testing.T) {
+		if err := checkWithClient(offlineClient, nil, "xml", nil); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
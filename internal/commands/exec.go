@@ -22,6 +22,10 @@ func Exec(version string, args []string) error {
 		return fmt.Errorf("command not specified. Usage: vc-env exec <version> <command> [args...]")
 	}
 
+	version = config.ExpandAlias(version)
+	if err := checkPolicy(version); err != nil {
+		return err
+	}
 	installed, err := config.IsVersionInstalled(version)
 	if err != nil {
 		return err
@@ -44,7 +48,9 @@ func Exec(version string, args []string) error {
 	// Maintain environment but force VCENV_VERSION for the shim if it's ever called
 	// but here we are calling the binary directly.
 	// However, we should still set it in case the subprocess calls other tools that depend on it.
-	cmd.Env = append(os.Environ(), fmt.Sprintf("VCENV_VERSION=%s", version))
+	// The resolve order is narrowed to the environment so that a configured
+	// VCENV_RESOLVE_ORDER cannot override the requested version.
+	cmd.Env = append(os.Environ(), fmt.Sprintf("VCENV_VERSION=%s", version), "VCENV_RESOLVE_ORDER="+config.ProviderEnv)
 
 	return cmd.Run()
 }
@@ -3,7 +3,10 @@ package commands
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/user/vc-env/internal/config"
 )
 
 func TestExec(t *testing.T) {
@@ -45,4 +48,29 @@ func TestExec(t *testing.T) {
 			t.Fatalf("expected command missing error, got %v", err)
 		}
 	})
+
+	t.Run("runs the version an alias stands for", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("VCENV_ROOT", tmpDir)
+		versionDir := filepath.Join(tmpDir, "versions", "0.31.0")
+		if err := os.MkdirAll(versionDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		out := filepath.Join(t.TempDir(), "out")
+		script := "#!/bin/sh\necho \"$VCENV_VERSION $*\" > " + out + "\n"
+		if err := os.WriteFile(filepath.Join(versionDir, "vcluster"), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := config.WriteAlias("prod", "0.31.0"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := Exec("prod", []string{"version"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data, err := os.ReadFile(out)
+		if err != nil || strings.TrimSpace(string(data)) != "0.31.0 version" {
+			t.Fatalf("expected 0.31.0 to run, got %q (%v)", data, err)
+		}
+	})
 }
@@ -0,0 +1,22 @@
+package commands
+
+// Exit statuses other than 1 that commands report through ExitError.
+const (
+	// ExitOutdated is returned by outdated when a version is outdated.
+	ExitOutdated = 3
+)
+
+// ExitError is an error that asks main to exit with Code instead of 1, so
+// that scripts and CI jobs can tell a finding from a failure.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
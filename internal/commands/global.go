@@ -2,14 +2,18 @@ package commands
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 
+	"github.com/user/vc-env/internal/channel"
 	"github.com/user/vc-env/internal/config"
+	"github.com/user/vc-env/internal/github"
 )
 
 // Global manages the global vcluster version.
-// With a version argument: verifies it's installed and writes $VCENV_ROOT/version.
+// With a version argument: verifies it's installed and writes $VCENV_ROOT/version,
+// replacing a configured channel.
 // Without argument: reads and prints the global version or errors.
 func Global(version string) error {
 	if err := config.RequireInit(); err != nil {
@@ -19,22 +23,31 @@ func Global(version string) error {
 	root, _ := config.GetVCEnvRoot()
 
 	if version == "" {
-		// Read global version
+		// Read global version, falling back to the channel
 		v, err := config.ReadGlobalVersion(root)
-		if err != nil {
+		if err == nil {
+			fmt.Println(v)
+			return nil
+		}
+		cfg, cfgErr := config.ReadChannel(root)
+		if !os.IsNotExist(err) || cfgErr != nil {
 			return fmt.Errorf("no global version configured")
 		}
+		v, err = config.ChannelVersion(root, cfg)
+		if err != nil {
+			return err
+		}
 		fmt.Println(v)
 		return nil
 	}
 
-	// Verify version is installed
-	installed, err := config.IsVersionInstalled(version)
-	if err != nil {
+	// Verify version is installed.  Aliases are written as given, so that
+	// moving the alias later also moves the global version.
+	if err := requireInstalled(version); err != nil {
 		return err
 	}
-	if !installed {
-		return fmt.Errorf("version %s not installed", version)
+	if err := checkPolicy(config.ExpandAlias(version)); err != nil {
+		return err
 	}
 
 	// Write global version file
@@ -43,5 +56,53 @@ func Global(version string) error {
 		return fmt.Errorf("failed to write global version: %w", err)
 	}
 
+	return config.RemoveChannel(root)
+}
+
+// GlobalChannel makes the release channel at channelURL the global version,
+// replacing $VCENV_ROOT/version.  The channel is fetched once to check that
+// it names a version.  With autoInstall the version is installed now, and
+// the shim installs later versions the channel names when they are first
+// used.
+func GlobalChannel(channelURL string, autoInstall bool) error {
+	return globalChannelWithClient(github.NewClient(), channelURL, autoInstall)
+}
+
+func globalChannelWithClient(client *github.Client, channelURL string, autoInstall bool) error {
+	if err := config.RequireInit(); err != nil {
+		return err
+	}
+	if u, err := url.Parse(channelURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid channel URL %q: expected an http or https URL", channelURL)
+	}
+	if offline, reason := offlineMode(); offline {
+		return fmt.Errorf("cannot fetch channel %s: %s", channelURL, reason)
+	}
+
+	root, _ := config.GetVCEnvRoot()
+	version, err := channel.New(channelURL, filepath.Join(root, "cache")).Refresh()
+	if err != nil {
+		return err
+	}
+	if err := checkPolicy(version); err != nil {
+		return err
+	}
+
+	if err := config.WriteChannel(root, config.ChannelConfig{URL: channelURL, AutoInstall: autoInstall}); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(root, "version")); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove global version: %w", err)
+	}
+	fmt.Printf("Global channel: %s (currently %s)\n", channelURL, version)
+
+	installed, err := config.IsVersionInstalled(version)
+	if err != nil || installed {
+		return err
+	}
+	if autoInstall {
+		return installWithClient(client, version, false)
+	}
+	fmt.Printf("Version %s is not installed; install it with: vc-env install %s\n", version, version)
 	return nil
 }
@@ -1,10 +1,16 @@
 package commands
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/user/vc-env/internal/config"
+	"github.com/user/vc-env/internal/github"
+	"github.com/user/vc-env/internal/platform"
 )
 
 func TestGlobal(t *testing.T) {
@@ -98,3 +104,98 @@ func TestGlobal(t *testing.T) {
 		}
 	})
 }
+
+func TestGlobal_System(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("VCENV_ROOT", tmpDir)
+	t.Setenv("PATH", t.TempDir())
+	if err := os.MkdirAll(filepath.Join(tmpDir, "versions"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// "system" is accepted even when no system binary exists yet.
+	if err := Global("system"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(tmpDir, "version"))
+	if err != nil || strings.TrimSpace(string(data)) != "system" {
+		t.Fatalf("expected global version system, got %q (%v)", data, err)
+	}
+}
+
+func TestGlobal_Channel(t *testing.T) {
+	host, err := platform.Detect()
+	if err != nil {
+		t.Skipf("unsupported platform: %v", err)
+	}
+	tmpDir := t.TempDir()
+	t.Setenv("VCENV_ROOT", tmpDir)
+	t.Setenv("VCENV_VERSION", "")
+	t.Setenv("VCENV_RESOLVE_ORDER", "")
+	t.Setenv("VCENV_OFFLINE", "")
+	t.Setenv("VCENV_ASSET_PATTERN", "")
+	t.Setenv("VCENV_DOWNLOAD_CACHE_DIR", "")
+	if err := os.MkdirAll(filepath.Join(tmpDir, "versions"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "version"), []byte("0.31.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	channelServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("# company default\n0.40.0\n"))
+	}))
+	defer channelServer.Close()
+	releases := newAssetReleaseServer(t, "0.40.0", map[string][]byte{platform.BinaryName(host): []byte("binary")}, nil)
+	defer releases.Close()
+	client := &github.Client{BaseURL: releases.URL, DownloadBaseURL: releases.URL, HTTPClient: releases.Client()}
+	channelURL := channelServer.URL + "/stable.txt"
+
+	if err := globalChannelWithClient(client, "ftp://intranet/stable.txt", false); err == nil {
+		t.Fatal("expected a non-HTTP channel URL to be rejected")
+	}
+
+	output := captureStdout(t, func() {
+		if err := globalChannelWithClient(client, channelURL, false); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	if !strings.Contains(output, "currently 0.40.0") || !strings.Contains(output, "vc-env install 0.40.0") {
+		t.Fatalf("unexpected output %q", output)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "version")); !os.IsNotExist(err) {
+		t.Fatal("expected the channel to replace the global version file")
+	}
+	output = captureStdout(t, func() {
+		if err := Global(""); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	if strings.TrimSpace(output) != "0.40.0" {
+		t.Fatalf("expected the channel version, got %q", output)
+	}
+	if err := whichShimWithClient(client); err == nil || !strings.Contains(err.Error(), "0.40.0 is not installed") {
+		t.Fatalf("expected a not-installed error without auto-install, got %v", err)
+	}
+
+	// With auto-install the shim installs the version the channel names.
+	if err := config.WriteChannel(tmpDir, config.ChannelConfig{URL: channelURL, AutoInstall: true}); err != nil {
+		t.Fatal(err)
+	}
+	output = captureStdout(t, func() {
+		if err := whichShimWithClient(client); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	if strings.TrimSpace(output) != filepath.Join(tmpDir, "versions", "0.40.0", "vcluster") {
+		t.Fatalf("unexpected output %q", output)
+	}
+
+	// Setting a version replaces the channel.
+	if err := Global("0.40.0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := config.ReadChannel(tmpDir); !os.IsNotExist(err) {
+		t.Fatalf("expected the channel to be removed, got %v", err)
+	}
+}
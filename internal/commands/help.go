@@ -12,24 +12,68 @@ Commands:
   list            List all installed versions of vcluster cli
   list-remote     List all available versions of vcluster cli from GitHub
   init            Initialize vc-env setup
-  install         Install a specific version (or latest if not specified). Flags: -s, --silent
+  install         Install a version (default: the pinned version, else the latest). Flags: -s, --silent
   uninstall       Uninstall a specific version
+  import          Import vcluster binaries installed outside of vc-env
   shell           Set or show the shell version of vcluster cli
-  local           Set or show the local version of vcluster cli
-  global          Set or show the global version of vcluster cli
+  local           Set or show the local version of vcluster cli. Flags: --tool-versions
+  global          Set or show the global version of vcluster cli. Flags: --channel URL, --auto-install
+  alias           Set, list or remove named aliases for versions
   latest          Print the latest available version of vcluster cli from GitHub releases.
   which           Print the full path to the active vcluster binary
+  resolve         Print the active version. Flags: --explain
   exec            Run a command using a specific vcluster version
   status          Show current vc-env environment status
+  audit           List installed and pinned versions affected by security advisories
+  outdated        List pinned and installed versions that have newer releases
+  bump            Update version pins in place to the newest matching release
+  check           Validate every version pin in a directory tree
+  scan            List the version pins under a directory and their install status
+  cache           Show or clear the release and download caches
+  bundle          Create or install an offline bundle of vcluster versions
+  mirror          Build or serve a self-hosted mirror of vcluster releases
   upgrade         Upgrade vc-env to the latest version
   autocompletion  Generate bash autocompletion script
-  version         Print the version of vc-env`)
+  version         Print the version of vc-env
+
+Global flags:
+  --offline       Never access the network; serve cached data only (same as VCENV_OFFLINE=1)`)
 }
 
 // InstallHelp prints help for the install command.
 func InstallHelp() {
 	fmt.Println(`Usage: vc-env install [version] [flags]
+       vc-env install --from FILE --as NAME [--sha256 HEX] [flags]
+       vc-env install --url URL --sha256 HEX --as NAME [flags]
+       vc-env install --source DIR [--as NAME] [flags]
+       vc-env install --all-pinned [DIR...] [flags]
 
 Flags:
-  -s, --silent    Do not display progress bar or checksum info`)
+  -s, --silent    Do not display progress bar or checksum info
+  --from FILE     Install a local vcluster binary (or archive) as version NAME
+  --url URL       Download a vcluster binary (or archive) and install it as NAME
+  --sha256 HEX    Expected SHA-256 of the file (required with --url)
+  --source DIR    Build the vcluster CLI from a source checkout and install it
+                  as NAME (default: dev-<shortsha>), replacing earlier dev-*
+                  builds from the same checkout
+  --as NAME       Version name for --from, --url and --source, e.g. 0.23.0-custom
+  --all-pinned    Install the versions pinned under each DIR (default: the
+                  current directory) that are missing, as listed by "scan"
+
+Without a version, the first version listed by the version file in effect
+(.vcluster-version, .tool-versions or the global version file) is installed,
+skipping "system", which refers to the vcluster on PATH.  When no version file
+applies, the latest release is installed.
+
+Binaries installed with --from or --url must run "vcluster version"
+successfully; their origin is recorded in versions/NAME/.vcenv-meta.json.
+
+The binary is picked from the release assets by VCENV_ASSET_PATTERN (default
+"vcluster-{os}-{arch}{ext}"; placeholders {os}, {arch}, {version}, {ext}).
+.tar.gz, .tgz and .zip assets are unpacked after checksum verification; set
+VCENV_ARCHIVE_BINARY_PATH to the executable's path inside the archive if it
+is not named vcluster.
+
+In offline mode (--offline or VCENV_OFFLINE=1) only versions that are already
+available locally can be installed; anything else fails immediately.`)
 }
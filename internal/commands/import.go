@@ -0,0 +1,323 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/user/vc-env/internal/archive"
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/config"
+	"github.com/user/vc-env/internal/github"
+	"github.com/user/vc-env/internal/platform"
+)
+
+// systemImportPatterns are the glob patterns of vcluster installs outside the
+// user's home directory that "import" looks at besides PATH.
+var systemImportPatterns = []string{
+	"/usr/local/bin/vcluster",
+	"/opt/homebrew/Cellar/vcluster/*/bin/vcluster",
+	"/usr/local/Cellar/vcluster/*/bin/vcluster",
+	"/home/linuxbrew/.linuxbrew/Cellar/vcluster/*/bin/vcluster",
+}
+
+// releaseVersionPattern matches the versions "import" accepts from
+// "vcluster version".
+var releaseVersionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?$`)
+
+// ImportHelp prints the help message for the import command.
+func ImportHelp() {
+	fmt.Println(`Usage: vc-env import [PATH...] [flags]
+
+Import vcluster binaries installed outside of vc-env into $VCENV_ROOT/versions.
+
+Without PATH arguments, vcluster binaries are discovered on PATH, in
+/usr/local/bin, ~/.local/bin, asdf installs ($ASDF_DATA_DIR or ~/.asdf) and
+Homebrew cellars.  Version manager shim directories are skipped.
+
+Each binary is installed under the version reported by "vcluster version".
+Its SHA-256 is compared with the checksum published for that release; a
+binary that differs from the release is not imported (use "vc-env install
+--from FILE --as NAME" for custom builds).  Binaries that cannot be verified,
+e.g. offline, are imported and recorded in versions/VERSION/.vcenv-meta.json.
+Binaries are hardlinked when possible and copied otherwise.
+
+Flags:
+  --remove-original   Remove each binary from its original location after it
+                      has been imported
+  -h, --help          Show this help message`)
+}
+
+// Import copies existing vcluster installs into vc-env.  Without paths the
+// usual install locations are searched.
+func Import(paths []string, removeOriginal bool) error {
+	return importWithClient(github.NewClient(), paths, removeOriginal)
+}
+
+// importWithClient is the testable core of Import.
+func importWithClient(client *github.Client, paths []string, removeOriginal bool) error {
+	if err := config.RequireInit(); err != nil {
+		return err
+	}
+
+	var candidates []string
+	if len(paths) > 0 {
+		for _, p := range paths {
+			if fi, err := os.Stat(p); err == nil && fi.IsDir() {
+				p = filepath.Join(p, "vcluster")
+			}
+			candidates = append(candidates, p)
+		}
+	} else {
+		candidates = discoverVClusterBinaries()
+		if len(candidates) == 0 {
+			fmt.Println("No vcluster installs found")
+			return nil
+		}
+	}
+
+	info, err := platform.Detect()
+	if err != nil {
+		return fmt.Errorf("failed to detect platform: %w", err)
+	}
+
+	failed := 0
+	seen := map[string]bool{}
+	for _, path := range candidates {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: skipping %s: %v\n", path, err)
+			failed++
+			continue
+		}
+		if seen[resolved] {
+			continue
+		}
+		seen[resolved] = true
+
+		if err := importBinary(client, info, path, resolved, removeOriginal); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: skipping %s: %v\n", path, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d vcluster binaries could not be imported", failed)
+	}
+	return nil
+}
+
+// importBinary imports the vcluster binary at path, whose symlinks resolve
+// to resolved.
+func importBinary(client *github.Client, info platform.Info, path, resolved string, removeOriginal bool) error {
+	reported, err := probeBinary(resolved)
+	if err != nil {
+		return err
+	}
+	if !releaseVersionPattern.MatchString(reported) {
+		return fmt.Errorf("cannot determine its version from \"vcluster version\"")
+	}
+
+	installed, err := config.IsVersionInstalled(reported)
+	if err != nil {
+		return err
+	}
+	if installed {
+		fmt.Printf("vcluster %s from %s is already installed\n", reported, path)
+		return nil
+	}
+	if err := checkPolicy(reported); err != nil {
+		return err
+	}
+
+	actual, err := fileSHA256(resolved)
+	if err != nil {
+		return err
+	}
+
+	// Without an upstream checksum the binary is imported as is, but its
+	// origin is recorded so that it can be told apart from a release.
+	var meta *config.VersionMeta
+	verification := ""
+	if offline, reason := offlineMode(); offline {
+		verification = "not verified: " + reason
+	} else if expected, err := upstreamChecksum(client, reported, info); err != nil {
+		verification = "not verified: " + err.Error()
+	} else if actual != expected {
+		return fmt.Errorf("it differs from the vcluster %s release (sha256 %s, expected %s); use 'vc-env install --from %s --as NAME' to install it under another name",
+			reported, actual, expected, path)
+	}
+	if verification != "" {
+		meta = &config.VersionMeta{
+			Source:          config.SourceFile,
+			Origin:          resolved,
+			SHA256:          actual,
+			ReportedVersion: reported,
+			InstalledAt:     time.Now().UTC(),
+		}
+	}
+
+	if err := linkVersionBinary(reported, resolved, meta); err != nil {
+		return err
+	}
+	if verification == "" {
+		verification = "checksum verified"
+	}
+	fmt.Printf("Imported vcluster %s from %s (%s)\n", reported, path, verification)
+
+	if removeOriginal {
+		removed := []string{path}
+		if resolved != path {
+			removed = append(removed, resolved)
+		}
+		for _, p := range removed {
+			if err := os.Remove(p); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: could not remove %s: %v\n", p, err)
+			} else {
+				fmt.Printf("Removed %s\n", p)
+			}
+		}
+	}
+	return nil
+}
+
+// linkVersionBinary installs the binary at src as version, hardlinking it
+// when src is on the same file system as $VCENV_ROOT and copying it
+// otherwise.  The version directory is staged and moved into place with a
+// single rename.
+func linkVersionBinary(version, src string, meta *config.VersionMeta) error {
+	root, _ := config.GetVCEnvRoot()
+	staging, err := os.MkdirTemp(root, ".install-*")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	binary := filepath.Join(staging, "vcluster")
+	if err := os.Link(src, binary); err != nil {
+		if err := copyExecutable(src, binary); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", src, err)
+		}
+	}
+	if meta != nil {
+		if err := config.WriteVersionMeta(staging, *meta); err != nil {
+			return fmt.Errorf("failed to write version metadata: %w", err)
+		}
+	}
+	if err := os.Chmod(staging, 0o755); err != nil {
+		return fmt.Errorf("failed to prepare version directory: %w", err)
+	}
+
+	versionDir, err := config.GetVersionDir(version)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(staging, versionDir); err != nil {
+		return fmt.Errorf("failed to install version %s: %w", version, err)
+	}
+	return nil
+}
+
+// copyExecutable copies the file src to dst with mode 0755.
+func copyExecutable(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// fileSHA256 returns the hex SHA-256 digest of the file at path.
+func fileSHA256(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return cache.SHA256(data), nil
+}
+
+// upstreamChecksum returns the SHA-256 published for the vcluster binary of
+// version on info, taken from the asset digest or the release's checksums
+// file.
+func upstreamChecksum(client *github.Client, version string, info platform.Info) (string, error) {
+	asset, err := resolveReleaseAsset(client, version, info)
+	if err != nil {
+		return "", err
+	}
+	if archive.IsArchive(asset.name) {
+		return "", fmt.Errorf("the release ships %s as an archive", asset.name)
+	}
+	if expected, ok := cache.ParseDigest(asset.digest); ok {
+		return expected, nil
+	}
+	if asset.checksumName == "" {
+		return "", fmt.Errorf("vcluster %s publishes no checksums", version)
+	}
+	data, err := client.DownloadBinary(client.DownloadURL(platform.ReleaseAssetPath(version, asset.checksumName)))
+	recordNetworkResult(err)
+	if err != nil {
+		return "", fmt.Errorf("could not download checksums: %w", err)
+	}
+	return github.FindChecksum(string(data), asset.name)
+}
+
+// discoverVClusterBinaries lists the vcluster executables on PATH and in the
+// usual install locations, in that order.  Shim directories, including
+// vc-env's own, are skipped as they only point at other installs.
+func discoverVClusterBinaries() []string {
+	var found []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" || filepath.Base(filepath.Clean(dir)) == "shims" {
+			continue
+		}
+		found = append(found, filepath.Join(dir, "vcluster"))
+	}
+
+	home, _ := os.UserHomeDir()
+	asdfDir := os.Getenv("ASDF_DATA_DIR")
+	if asdfDir == "" && home != "" {
+		asdfDir = filepath.Join(home, ".asdf")
+	}
+	var patterns []string
+	if home != "" {
+		patterns = append(patterns, filepath.Join(home, ".local", "bin", "vcluster"))
+	}
+	if asdfDir != "" {
+		patterns = append(patterns, filepath.Join(asdfDir, "installs", "vcluster", "*", "bin", "vcluster"))
+	}
+	if cellar := os.Getenv("HOMEBREW_CELLAR"); cellar != "" {
+		patterns = append(patterns, filepath.Join(cellar, "vcluster", "*", "bin", "vcluster"))
+	}
+	patterns = append(patterns, systemImportPatterns...)
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(pattern)
+		found = append(found, matches...)
+	}
+
+	root, _ := config.GetVCEnvRoot()
+	var binaries []string
+	for _, path := range found {
+		fi, err := os.Stat(path)
+		if err != nil || fi.IsDir() || fi.Mode()&0o111 == 0 {
+			continue
+		}
+		if root != "" && strings.HasPrefix(path, filepath.Clean(root)+string(filepath.Separator)) {
+			continue
+		}
+		binaries = append(binaries, path)
+	}
+	return binaries
+}
@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/user/vc-env/internal/config"
+	"github.com/user/vc-env/internal/github"
+	"github.com/user/vc-env/internal/platform"
+)
+
+// writeFakeVCluster writes a script reporting version to dir/vcluster and
+// returns its path.
+func writeFakeVCluster(t *testing.T, dir, version string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "vcluster")
+	script := fmt.Sprintf("#!/bin/sh\necho \"vcluster version %s\"\n", version)
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestImport_Discover(t *testing.T) {
+	root := setupInstallFrom(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ASDF_DATA_DIR", "")
+	t.Setenv("HOMEBREW_CELLAR", "")
+	saved := systemImportPatterns
+	systemImportPatterns = nil
+	t.Cleanup(func() { systemImportPatterns = saved })
+
+	info, err := platform.Detect()
+	if err != nil {
+		t.Skipf("unsupported platform: %v", err)
+	}
+
+	// 0.21.1 matches the release, 0.20.0 was modified, 0.19.0 publishes no
+	// checksums.
+	onPath := writeFakeVCluster(t, filepath.Join(t.TempDir(), "bin"), "0.21.1")
+	linkDir := t.TempDir()
+	if err := os.Symlink(onPath, filepath.Join(linkDir, "vcluster")); err != nil {
+		t.Fatal(err)
+	}
+	writeFakeVCluster(t, filepath.Join(root, "shims"), "0.0.1")
+	modified := writeFakeVCluster(t, filepath.Join(home, ".asdf", "installs", "vcluster", "0.20.0", "bin"), "0.20.0")
+	unverified := writeFakeVCluster(t, filepath.Join(home, ".local", "bin"), "0.19.0")
+	t.Setenv("PATH", strings.Join([]string{filepath.Join(root, "shims"), filepath.Dir(onPath), linkDir}, string(os.PathListSeparator)))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/loft-sh/vcluster/releases/download/v0.21.1/checksums.txt":
+			sum, _ := fileSHA256(onPath)
+			fmt.Fprintf(w, "%s  %s\n", sum, platform.BinaryName(info))
+		case "/loft-sh/vcluster/releases/download/v0.20.0/checksums.txt":
+			fmt.Fprintf(w, "%s  %s\n", strings.Repeat("0", 64), platform.BinaryName(info))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := &github.Client{BaseURL: server.URL, DownloadBaseURL: server.URL, HTTPClient: server.Client()}
+
+	var importErr error
+	out := captureStdout(t, func() {
+		importErr = importWithClient(client, nil, false)
+	})
+	if importErr == nil || !strings.Contains(importErr.Error(), "1 vcluster binaries could not be imported") {
+		t.Fatalf("expected the modified binary to be rejected, got %v", importErr)
+	}
+	if !strings.Contains(out, "Imported vcluster 0.21.1 from "+onPath+" (checksum verified)") {
+		t.Errorf("expected verified import of 0.21.1, got %q", out)
+	}
+	if !strings.Contains(out, "Imported vcluster 0.19.0 from "+unverified+" (not verified") {
+		t.Errorf("expected unverified import of 0.19.0, got %q", out)
+	}
+
+	for _, v := range []string{"0.21.1", "0.19.0"} {
+		if installed, _ := config.IsVersionInstalled(v); !installed {
+			t.Errorf("expected %s to be installed", v)
+		}
+	}
+	for _, v := range []string{"0.20.0", "0.0.1"} {
+		if installed, _ := config.IsVersionInstalled(v); installed {
+			t.Errorf("expected %s not to be installed", v)
+		}
+	}
+	if _, ok, _ := config.ReadVersionMeta(filepath.Join(root, "versions", "0.21.1")); ok {
+		t.Error("verified import should not record metadata")
+	}
+	meta, ok, err := config.ReadVersionMeta(filepath.Join(root, "versions", "0.19.0"))
+	if err != nil || !ok || meta.Origin != unverified || meta.ReportedVersion != "0.19.0" {
+		t.Errorf("unexpected metadata for unverified import: %+v, %v, %v", meta, ok, err)
+	}
+	if _, err := os.Stat(modified); err != nil {
+		t.Errorf("rejected binary should be left in place: %v", err)
+	}
+}
+
+func TestImport_RemoveOriginal(t *testing.T) {
+	root := setupInstallFrom(t)
+	t.Setenv("VCENV_OFFLINE", "1")
+	src := writeFakeVCluster(t, t.TempDir(), "0.21.1")
+
+	out := captureStdout(t, func() {
+		if err := Import([]string{filepath.Dir(src)}, true); err != nil {
+			t.Fatalf("Import: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Removed "+src) {
+		t.Errorf("expected removal to be reported, got %q", out)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("expected original to be removed, got %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "versions", "0.21.1", "vcluster"))
+	if err != nil || !strings.Contains(string(data), "0.21.1") {
+		t.Fatalf("binary not imported: %q (%v)", data, err)
+	}
+
+	// A second import of the same version is a no-op.
+	again := writeFakeVCluster(t, t.TempDir(), "0.21.1")
+	out = captureStdout(t, func() {
+		if err := Import([]string{again}, false); err != nil {
+			t.Fatalf("Import: %v", err)
+		}
+	})
+	if !strings.Contains(out, "already installed") {
+		t.Errorf("expected already-installed notice, got %q", out)
+	}
+}
@@ -1,12 +1,12 @@
 package commands
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"fmt"
 	"os"
 	"strings"
 
+	"github.com/user/vc-env/internal/archive"
+	"github.com/user/vc-env/internal/cache"
 	"github.com/user/vc-env/internal/config"
 	"github.com/user/vc-env/internal/github"
 	"github.com/user/vc-env/internal/platform"
@@ -24,18 +24,68 @@ func installWithClient(client *github.Client, version string, silent bool) error
 		return err
 	}
 
-	// If no version specified, fetch latest
+	offline, offlineReason := offlineMode()
+
+	// If no version specified, install the preferred version of the version
+	// file in effect, if any.  "system" needs no install, so the first other
+	// candidate is used; a file that only names "system" leaves nothing to do.
 	if version == "" {
-		latest, err := client.GetLatestRelease()
-		if err != nil {
-			return fmt.Errorf("failed to fetch latest version: %w", err)
+		if r, ok := versionFileResolution(); ok {
+			for _, c := range r.Candidates {
+				if config.ExpandAlias(c) != config.SystemVersion {
+					version = c
+					break
+				}
+			}
+			if version == "" {
+				if !silent {
+					fmt.Printf("Version from %s: %s (the vcluster on PATH); nothing to install\n", r.Origin, config.SystemVersion)
+				}
+				return nil
+			}
+			if !silent {
+				fmt.Printf("Version from %s: %s\n", r.Origin, version)
+			}
+		}
+	}
+
+	// Otherwise fetch latest.  Offline, the newest version known to the disk
+	// cache or the baseline is used instead.
+	if version == "" {
+		if offline {
+			stable, _, err := getRemoteVersions(client)
+			if err != nil || len(stable) == 0 {
+				return fmt.Errorf("cannot determine the latest version: %s", offlineReason)
+			}
+			version = stable[0]
+		} else {
+			latest, err := client.GetLatestRelease()
+			recordNetworkResult(err)
+			if err != nil {
+				return fmt.Errorf("failed to fetch latest version: %w", err)
+			}
+			version = latest
 		}
-		version = latest
 		if !silent {
 			fmt.Printf("Latest version: %s\n", version)
 		}
 	}
 
+	if target := config.ExpandAlias(version); target != version {
+		if !silent {
+			fmt.Printf("Alias %s: %s\n", version, target)
+		}
+		version = target
+	}
+
+	if version == config.SystemVersion {
+		return fmt.Errorf("%q refers to the vcluster on PATH and cannot be installed", version)
+	}
+
+	if err := checkPolicy(version); err != nil {
+		return err
+	}
+
 	// Check if already installed
 	installed, err := config.IsVersionInstalled(version)
 	if err != nil {
@@ -54,14 +104,104 @@ func installWithClient(client *github.Client, version string, silent bool) error
 		return fmt.Errorf("failed to detect platform: %w", err)
 	}
 
+	// Reuse a previously verified download when one is available.
+	store := newDownloadStoreForRoot()
+	if artifact, ok := store.Get(version, platform.Key(info)); ok {
+		if !silent {
+			fmt.Printf("Using cached download of vcluster %s for %s/%s\n", version, info.OS, info.Arch)
+		}
+		return installArtifact(version, artifact, silent)
+	}
+
+	if offline {
+		return fmt.Errorf("cannot install vcluster %s: %s and no local copy is available", version, offlineReason)
+	}
+
+	artifact, err := downloadVerified(client, version, info, silent)
+	if err != nil {
+		return err
+	}
+
+	// Only artifacts that passed checksum verification are kept for reuse.
+	if artifact.Checksums != nil {
+		if err := store.Put(version, platform.Key(info), artifact); err != nil && !silent {
+			fmt.Fprintf(os.Stderr, "warning: could not write download cache: %v\n", err)
+		}
+	}
+
+	return installArtifact(version, artifact, silent)
+}
+
+// releaseAsset identifies the files of a release used to install it.
+type releaseAsset struct {
+	name         string // binary asset name
+	digest       string // "sha256:<hex>" reported by the API, or empty
+	checksumName string // checksums asset name, or empty if there is none
+}
+
+// resolveReleaseAsset selects the binary of version for info from the
+// release's asset list (see platform.AssetPatterns).  When the API cannot be
+// used it falls back to the conventional vcluster-<os>-<arch> and
+// checksums.txt names.  It fails when the release is known to have no
+// binary for info.
+func resolveReleaseAsset(client *github.Client, version string, info platform.Info) (releaseAsset, error) {
+	release, err := client.GetRelease(version)
+	recordNetworkResult(err)
+	if err != nil {
+		release = nil
+	}
+	return selectReleaseAsset(release, version, info)
+}
+
+// selectReleaseAsset is resolveReleaseAsset for a release that has already
+// been fetched, or nil when the API could not be used.
+func selectReleaseAsset(release *github.Release, version string, info platform.Info) (releaseAsset, error) {
+	fallback := releaseAsset{name: platform.BinaryName(info), checksumName: "checksums.txt"}
+	if release == nil || len(release.Assets) == 0 {
+		return fallback, nil
+	}
+
+	names := release.AssetNames()
+	name, ok := platform.MatchAsset(names, version, info)
+	if !ok {
+		if available := platform.AvailablePlatforms(names, version); len(available) > 0 {
+			return releaseAsset{}, fmt.Errorf("vcluster %s is not available for %s/%s; available platforms: %s",
+				version, info.OS, info.Arch, strings.Join(available, ", "))
+		}
+		return releaseAsset{}, fmt.Errorf("no asset of vcluster %s matches %s; release assets: %s",
+			version, strings.Join(platform.AssetPatterns(), ", "), strings.Join(names, ", "))
+	}
+
+	asset, _ := release.Asset(name)
+	checksumName, _ := platform.MatchChecksumAsset(names)
+	return releaseAsset{name: name, digest: asset.Digest, checksumName: checksumName}, nil
+}
+
+// downloadVerified downloads the vcluster binary for version and info and
+// validates it against the asset digest reported by the API and the
+// release's checksums file.  The returned artifact carries checksums only
+// when verification succeeded; a missing checksum is reported as a warning,
+// a mismatch as an error.
+func downloadVerified(client *github.Client, version string, info platform.Info, silent bool) (cache.Artifact, error) {
+	asset, err := resolveReleaseAsset(client, version, info)
+	if err != nil {
+		return cache.Artifact{}, err
+	}
+	return downloadAsset(client, version, info, asset, silent)
+}
+
+// downloadAsset is downloadVerified for an asset that has already been
+// resolved.
+func downloadAsset(client *github.Client, version string, info platform.Info, asset releaseAsset, silent bool) (cache.Artifact, error) {
 	// Construct download URL
-	url := client.DownloadURL(platform.DownloadPath(version, info))
+	url := client.DownloadURL(platform.ReleaseAssetPath(version, asset.name))
 	if !silent {
 		fmt.Printf("Downloading vcluster %s for %s/%s...\n", version, info.OS, info.Arch)
 	}
 
 	// Download binary with progress
 	var data []byte
+	var err error
 	if silent {
 		data, err = client.DownloadBinary(url)
 	} else {
@@ -79,36 +219,74 @@ func installWithClient(client *github.Client, version string, silent bool) error
 			}
 		})
 	}
+	recordNetworkResult(err)
 	if err != nil {
-		return fmt.Errorf("failed to download vcluster %s: %w", version, err)
+		return cache.Artifact{}, fmt.Errorf("failed to download vcluster %s: %w", version, err)
+	}
+
+	artifact := cache.Artifact{Name: asset.name, Data: data}
+	actualChecksum := cache.SHA256(data)
+
+	// Digest reported by the API
+	digestVerified := false
+	if expected, ok := cache.ParseDigest(asset.digest); ok {
+		if actualChecksum != expected {
+			return cache.Artifact{}, fmt.Errorf("digest mismatch for %s: expected %s, got %s", asset.name, cache.FormatDigest(expected), cache.FormatDigest(actualChecksum))
+		}
+		digestVerified = true
 	}
 
 	// Checksum validation
-	checksumPath := platform.ChecksumPath(version)
-	checksumUrl := client.DownloadURL(checksumPath)
-	checksumData, err := client.DownloadBinary(checksumUrl)
-	if err != nil {
-		if !silent {
+	if asset.checksumName == "" {
+		if !silent && !digestVerified {
+			fmt.Printf("Warning: vcluster %s publishes no checksums\n", version)
+		}
+	} else if checksumData, err := client.DownloadBinary(client.DownloadURL(platform.ReleaseAssetPath(version, asset.checksumName))); err != nil {
+		if !silent && !digestVerified {
 			fmt.Printf("Warning: could not download checksums for version %s: %v\n", version, err)
 		}
+	} else if expectedChecksum, err := github.FindChecksum(string(checksumData), asset.name); err != nil {
+		if !silent && !digestVerified {
+			fmt.Printf("Warning: could not find checksum for %s in %s\n", asset.name, asset.checksumName)
+		}
 	} else {
-		expectedChecksum, err := findChecksum(string(checksumData), platform.BinaryName(info))
-		if err != nil {
-			if !silent {
-				fmt.Printf("Warning: could not find checksum for %s in checksums.txt\n", platform.BinaryName(info))
-			}
-		} else {
-			actualChecksum := sha256.Sum256(data)
-			actualChecksumStr := hex.EncodeToString(actualChecksum[:])
-			if actualChecksumStr != expectedChecksum {
-				return fmt.Errorf("checksum mismatch: expected %s, got %s", expectedChecksum, actualChecksumStr)
-			}
-			if !silent {
-				fmt.Println("Checksum verified successfully")
-			}
+		if actualChecksum != expectedChecksum {
+			return cache.Artifact{}, fmt.Errorf("checksum mismatch: expected %s, got %s", expectedChecksum, actualChecksum)
 		}
+		artifact.Checksums = checksumData
+	}
+
+	// A verified digest alone is enough to keep the artifact; record it in
+	// checksums.txt form.
+	if artifact.Checksums == nil && digestVerified {
+		artifact.Checksums = []byte(fmt.Sprintf("%s  %s\n", actualChecksum, asset.name))
+	}
+	if !silent && artifact.Checksums != nil {
+		fmt.Println("Checksum verified successfully")
+	}
+	return artifact, nil
+}
+
+// installArtifact installs a verified release asset as version, unpacking
+// the vcluster executable first when the asset is an archive.  Archives are
+// only ever unpacked after their checksum has been verified.
+func installArtifact(version string, artifact cache.Artifact, silent bool) error {
+	if !archive.IsArchive(artifact.Name) {
+		return writeVersionBinary(version, artifact.Data, 0o755, silent)
+	}
+	file, err := archive.ExtractBinary(artifact.Name, artifact.Data, os.Getenv("VCENV_ARCHIVE_BINARY_PATH"))
+	if err != nil {
+		return fmt.Errorf("failed to unpack vcluster %s: %w", version, err)
+	}
+	if !silent {
+		fmt.Printf("Extracted %s from %s\n", file.Name, artifact.Name)
 	}
+	return writeVersionBinary(version, file.Data, file.Mode, silent)
+}
 
+// writeVersionBinary writes data as the vcluster binary of version with the
+// given permissions.
+func writeVersionBinary(version string, data []byte, perm os.FileMode, silent bool) error {
 	// Create version directory
 	versionDir, err := config.GetVersionDir(version)
 	if err != nil {
@@ -123,7 +301,10 @@ func installWithClient(client *github.Client, version string, silent bool) error
 	if err != nil {
 		return err
 	}
-	if err := os.WriteFile(binaryPath, data, 0o755); err != nil {
+	if err := os.WriteFile(binaryPath, data, perm); err != nil {
+		return fmt.Errorf("failed to write binary: %w", err)
+	}
+	if err := os.Chmod(binaryPath, perm); err != nil {
 		return fmt.Errorf("failed to write binary: %w", err)
 	}
 
@@ -133,13 +314,17 @@ func installWithClient(client *github.Client, version string, silent bool) error
 	return nil
 }
 
-func findChecksum(checksums, filename string) (string, error) {
-	lines := strings.Split(checksums, "\n")
-	for _, line := range lines {
-		parts := strings.Fields(line)
-		if len(parts) >= 2 && parts[1] == filename {
-			return parts[0], nil
-		}
+// versionFileResolution returns the resolution of the current directory when
+// it comes from a version file, whose first listed version is the one to
+// install.
+func versionFileResolution() (config.Resolution, bool) {
+	r, err := config.Resolve()
+	if err != nil || len(r.Candidates) == 0 {
+		return config.Resolution{}, false
+	}
+	switch r.Provider {
+	case config.ProviderVersionFile, config.ProviderToolVersions, config.ProviderGlobal:
+		return r, true
 	}
-	return "", fmt.Errorf("checksum not found for %s", filename)
+	return config.Resolution{}, false
 }
@@ -0,0 +1,193 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/user/vc-env/internal/archive"
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/config"
+	"github.com/user/vc-env/internal/github"
+)
+
+// probeTimeout bounds how long "vcluster version" may take when checking a
+// custom binary.
+const probeTimeout = 10 * time.Second
+
+// reportedVersionPattern finds the version in "vcluster version" output.
+var reportedVersionPattern = regexp.MustCompile(`v?\d+\.\d+\.\d+[0-9A-Za-z.+-]*`)
+
+// InstallFrom registers a vcluster binary from a local file or a URL as the
+// version name.
+func InstallFrom(file, url, sha256sum, name string, silent bool) error {
+	return installFromWithClient(github.NewClient(), file, url, sha256sum, name, silent)
+}
+
+func installFromWithClient(client *github.Client, file, url, sha256sum, name string, silent bool) error {
+	if err := config.RequireInit(); err != nil {
+		return err
+	}
+	if file != "" && url != "" {
+		return fmt.Errorf("--from and --url cannot be used together")
+	}
+	if name == "" {
+		return fmt.Errorf("version name not specified. Usage: vc-env install --from FILE --as NAME")
+	}
+	if err := config.ValidateVersionName(name); err != nil {
+		return err
+	}
+	if err := checkPolicy(name); err != nil {
+		return err
+	}
+	installed, err := config.IsVersionInstalled(name)
+	if err != nil {
+		return err
+	}
+	if installed {
+		return fmt.Errorf("version %s is already installed; run 'vc-env uninstall %s' first", name, name)
+	}
+
+	var data []byte
+	meta := config.VersionMeta{}
+	if file != "" {
+		meta.Source = config.SourceFile
+		if meta.Origin, err = filepath.Abs(file); err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", file, err)
+		}
+		if data, err = os.ReadFile(file); err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+	} else {
+		if sha256sum == "" {
+			return fmt.Errorf("--sha256 is required with --url")
+		}
+		if offline, reason := offlineMode(); offline {
+			return fmt.Errorf("cannot download %s: %s", url, reason)
+		}
+		meta.Source, meta.Origin = config.SourceURL, url
+		if !silent {
+			fmt.Printf("Downloading %s...\n", url)
+		}
+		data, err = client.DownloadBinary(url)
+		recordNetworkResult(err)
+		if err != nil {
+			return fmt.Errorf("failed to download %s: %w", url, err)
+		}
+	}
+
+	if sha256sum != "" {
+		expected, ok := cache.ParseDigest(sha256sum)
+		if !ok {
+			expected = strings.ToLower(sha256sum)
+		}
+		if actual := cache.SHA256(data); actual != expected {
+			return fmt.Errorf("checksum mismatch: expected %s, got %s", expected, actual)
+		}
+		if !silent {
+			fmt.Println("Checksum verified successfully")
+		}
+	}
+
+	perm := os.FileMode(0o755)
+	if assetName := path.Base(filepath.ToSlash(meta.Origin)); archive.IsArchive(assetName) {
+		extracted, err := archive.ExtractBinary(assetName, data, os.Getenv("VCENV_ARCHIVE_BINARY_PATH"))
+		if err != nil {
+			return fmt.Errorf("failed to unpack %s: %w", meta.Origin, err)
+		}
+		data, perm = extracted.Data, extracted.Mode
+	}
+
+	meta.SHA256 = cache.SHA256(data)
+	return installCustomBinary(name, data, perm, meta, false, silent)
+}
+
+// installCustomBinary stages a binary in a temporary directory under
+// $VCENV_ROOT, checks that "vcluster version" runs, records meta and moves
+// the directory into place with a single rename.  With replace, an existing
+// version of the same name is swapped out.
+func installCustomBinary(name string, data []byte, perm os.FileMode, meta config.VersionMeta, replace, silent bool) error {
+	root, _ := config.GetVCEnvRoot()
+	staging, err := os.MkdirTemp(root, ".install-*")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	binary := filepath.Join(staging, "vcluster")
+	if err := os.WriteFile(binary, data, perm); err != nil {
+		return fmt.Errorf("failed to write binary: %w", err)
+	}
+	if err := os.Chmod(binary, perm|0o100); err != nil {
+		return fmt.Errorf("failed to write binary: %w", err)
+	}
+
+	reported, err := probeBinary(binary)
+	if err != nil {
+		return fmt.Errorf("%s is not a usable vcluster binary: %w", meta.Origin, err)
+	}
+	meta.ReportedVersion = reported
+	meta.InstalledAt = time.Now().UTC()
+	if err := config.WriteVersionMeta(staging, meta); err != nil {
+		return fmt.Errorf("failed to write version metadata: %w", err)
+	}
+	if err := os.Chmod(staging, 0o755); err != nil {
+		return fmt.Errorf("failed to prepare version directory: %w", err)
+	}
+
+	versionDir, err := config.GetVersionDir(name)
+	if err != nil {
+		return err
+	}
+	// An existing version is moved aside first, as a directory cannot be
+	// renamed over a non-empty one, and restored if the install fails.
+	previous := ""
+	if replace {
+		previous = staging + ".old"
+		if err := os.Rename(versionDir, previous); os.IsNotExist(err) {
+			previous = ""
+		} else if err != nil {
+			return fmt.Errorf("failed to replace version %s: %w", name, err)
+		}
+	}
+	if err := os.Rename(staging, versionDir); err != nil {
+		if previous != "" {
+			_ = os.Rename(previous, versionDir)
+		}
+		return fmt.Errorf("failed to install version %s: %w", name, err)
+	}
+	if previous != "" {
+		_ = os.RemoveAll(previous)
+	}
+
+	if !silent {
+		if reported != "" {
+			fmt.Printf("Installed vcluster %s as %s\n", reported, name)
+		} else {
+			fmt.Printf("Installed vcluster as %s\n", name)
+		}
+	}
+	return nil
+}
+
+// probeBinary runs "<binary> version" and returns the version it reports, or
+// an empty string if the output contains none.
+func probeBinary(binary string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, binary, "version").CombinedOutput()
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("\"vcluster version\" did not finish within %s", probeTimeout)
+	}
+	if err != nil {
+		return "", fmt.Errorf("\"vcluster version\" failed: %w", err)
+	}
+	return strings.TrimPrefix(reportedVersionPattern.FindString(string(out)), "v"), nil
+}
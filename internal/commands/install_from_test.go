@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/config"
+	"github.com/user/vc-env/internal/github"
+)
+
+// fakeVCluster is a shell script that behaves like "vcluster version".
+const fakeVCluster = "#!/bin/sh\necho \"vcluster version 0.23.0-custom\"\n"
+
+// setupInstallFrom initialises a VCENV_ROOT and returns it.
+func setupInstallFrom(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	t.Setenv("VCENV_ROOT", root)
+	if err := os.MkdirAll(filepath.Join(root, "versions"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestInstallFrom_File(t *testing.T) {
+	root := setupInstallFrom(t)
+	src := filepath.Join(t.TempDir(), "vcluster-linux-amd64")
+	if err := os.WriteFile(src, []byte(fakeVCluster), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := InstallFrom(src, "", "", "0.23.0-custom", false); err != nil {
+			t.Fatalf("InstallFrom: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Installed vcluster 0.23.0-custom as 0.23.0-custom") {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	versionDir := filepath.Join(root, "versions", "0.23.0-custom")
+	data, err := os.ReadFile(filepath.Join(versionDir, "vcluster"))
+	if err != nil || string(data) != fakeVCluster {
+		t.Fatalf("binary not installed: %q (%v)", data, err)
+	}
+	meta, ok, err := config.ReadVersionMeta(versionDir)
+	if err != nil || !ok {
+		t.Fatalf("expected version metadata, got ok=%v err=%v", ok, err)
+	}
+	if meta.Source != config.SourceFile || meta.Origin != src || meta.SHA256 != cache.SHA256([]byte(fakeVCluster)) || meta.ReportedVersion != "0.23.0-custom" {
+		t.Fatalf("unexpected metadata %+v", meta)
+	}
+
+	if err := InstallFrom(src, "", "", "0.23.0-custom", true); err == nil || !strings.Contains(err.Error(), "already installed") {
+		t.Fatalf("expected already-installed error, got %v", err)
+	}
+}
+
+func TestInstallFrom_RejectsUnusableBinary(t *testing.T) {
+	root := setupInstallFrom(t)
+	src := filepath.Join(t.TempDir(), "broken")
+	if err := os.WriteFile(src, []byte("#!/bin/sh\nexit 3\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := InstallFrom(src, "", "", "broken", true)
+	if err == nil || !strings.Contains(err.Error(), "not a usable vcluster binary") {
+		t.Fatalf("expected probe failure, got %v", err)
+	}
+
+	// Nothing is left behind: no version and no staging directory.
+	entries, _ := os.ReadDir(root)
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".install-") {
+			t.Fatalf("staging directory %s was not removed", e.Name())
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "versions", "broken")); !os.IsNotExist(err) {
+		t.Fatal("version directory must not exist after a failed install")
+	}
+}
+
+func TestInstallFrom_URL(t *testing.T) {
+	root := setupInstallFrom(t)
+	t.Setenv("VCENV_OFFLINE", "")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(fakeVCluster))
+	}))
+	defer server.Close()
+	client := &github.Client{BaseURL: server.URL, DownloadBaseURL: server.URL, HTTPClient: server.Client()}
+	url := server.URL + "/builds/vcluster"
+
+	if err := installFromWithClient(client, "", url, "", "patched", true); err == nil || !strings.Contains(err.Error(), "--sha256") {
+		t.Fatalf("expected --sha256 to be required, got %v", err)
+	}
+	if err := installFromWithClient(client, "", url, strings.Repeat("0", 64), "patched", true); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+
+	if err := installFromWithClient(client, "", url, cache.SHA256([]byte(fakeVCluster)), "patched", true); err != nil {
+		t.Fatalf("install from URL: %v", err)
+	}
+	meta, ok, err := config.ReadVersionMeta(filepath.Join(root, "versions", "patched"))
+	if err != nil || !ok || meta.Source != config.SourceURL || meta.Origin != url {
+		t.Fatalf("unexpected metadata %+v (ok=%v, err=%v)", meta, ok, err)
+	}
+}
+
+func TestInstallFrom_InvalidArguments(t *testing.T) {
+	setupInstallFrom(t)
+	tests := []struct {
+		name, file, url, as, wantErr string
+	}{
+		{name: "missing name", file: "vcluster", wantErr: "version name not specified"},
+		{name: "unsafe name", file: "vcluster", as: "../evil", wantErr: "invalid version name"},
+		{name: "both sources", file: "vcluster", url: "http://example.invalid/vcluster", as: "x", wantErr: "cannot be used together"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := InstallFrom(tt.file, tt.url, "", tt.as, true)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
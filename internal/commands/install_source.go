@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/config"
+)
+
+const (
+	// vclusterCLIPackage is the main package of the vcluster CLI inside a
+	// vcluster checkout.
+	vclusterCLIPackage = "./cmd/vclusterctl"
+	// vclusterVersionVar is the variable the vcluster release build sets
+	// through -ldflags to embed the CLI version.
+	vclusterVersionVar = "github.com/loft-sh/vcluster/pkg/upgrade.version"
+)
+
+// InstallSource builds the vcluster CLI from a source checkout and installs
+// it as name, or as dev-<shortsha> when name is empty.  An existing version
+// named name is only replaced if it was built from the same checkout, and
+// the earlier dev-* builds of the checkout are removed.
+func InstallSource(dir, name string, silent bool) error {
+	if err := config.RequireInit(); err != nil {
+		return err
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	if fi, err := os.Stat(filepath.Join(dir, filepath.FromSlash(vclusterCLIPackage))); err != nil || !fi.IsDir() {
+		return fmt.Errorf("%s is not a vcluster checkout: %s not found", dir, vclusterCLIPackage)
+	}
+
+	revision, err := gitOutput(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to determine the revision of %s: %w", dir, err)
+	}
+	shortRevision, err := gitOutput(dir, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to determine the revision of %s: %w", dir, err)
+	}
+	// Same version string the release build derives from git.
+	version, err := gitOutput(dir, "describe", "--tags", "--always", "--dirty")
+	if err != nil {
+		return fmt.Errorf("failed to describe %s: %w", dir, err)
+	}
+	version = strings.TrimPrefix(version, "v")
+
+	if name == "" {
+		name = "dev-" + shortRevision
+	}
+	if err := config.ValidateVersionName(name); err != nil {
+		return err
+	}
+	if err := checkPolicy(name); err != nil {
+		return err
+	}
+	if err := checkReplaceableBuild(name, dir); err != nil {
+		return err
+	}
+
+	buildDir, err := os.MkdirTemp("", "vcenv-build-*")
+	if err != nil {
+		return fmt.Errorf("failed to create build directory: %w", err)
+	}
+	defer os.RemoveAll(buildDir)
+	output := filepath.Join(buildDir, "vcluster")
+
+	if !silent {
+		fmt.Printf("Building vcluster %s from %s...\n", version, dir)
+	}
+	cmd := exec.Command("go", "build", "-o", output,
+		"-ldflags", fmt.Sprintf("-s -w -X %s=%s", vclusterVersionVar, version),
+		vclusterCLIPackage)
+	cmd.Dir = dir
+	var buildLog bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &buildLog, &buildLog
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("go build failed: %w\n%s", err, strings.TrimSpace(buildLog.String()))
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		return fmt.Errorf("failed to read build output: %w", err)
+	}
+
+	meta := config.VersionMeta{
+		Source:   config.SourceBuild,
+		Origin:   dir,
+		SHA256:   cache.SHA256(data),
+		Revision: revision,
+	}
+	if err := installCustomBinary(name, data, 0o755, meta, true, silent); err != nil {
+		return err
+	}
+
+	return removePreviousBuilds(dir, name, silent)
+}
+
+// checkReplaceableBuild fails if version name is installed and was not built
+// from the checkout dir, so that a build never replaces a release or another
+// custom version.
+func checkReplaceableBuild(name, dir string) error {
+	versionDir, err := config.GetVersionDir(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(versionDir); os.IsNotExist(err) {
+		return nil
+	}
+	meta, ok, err := config.ReadVersionMeta(versionDir)
+	if err != nil {
+		return err
+	}
+	if !ok || meta.Source != config.SourceBuild || meta.Origin != dir {
+		return fmt.Errorf("version %s is already installed and was not built from %s\nUninstall it first, or choose another name with --as", name, dir)
+	}
+	return nil
+}
+
+// removePreviousBuilds uninstalls the automatically named dev-* versions
+// built from the checkout dir, other than keep.  Builds named with --as are
+// kept.
+func removePreviousBuilds(dir, keep string, silent bool) error {
+	root, _ := config.GetVCEnvRoot()
+	versionsDir := filepath.Join(root, "versions")
+	entries, err := os.ReadDir(versionsDir)
+	if err != nil {
+		return fmt.Errorf("failed to read versions directory: %w", err)
+	}
+	for _, e := range entries {
+		if !e.IsDir() || e.Name() == keep || !strings.HasPrefix(e.Name(), "dev-") {
+			continue
+		}
+		versionDir := filepath.Join(versionsDir, e.Name())
+		meta, ok, err := config.ReadVersionMeta(versionDir)
+		if err != nil || !ok || meta.Source != config.SourceBuild || meta.Origin != dir {
+			continue
+		}
+		if err := os.RemoveAll(versionDir); err != nil {
+			return fmt.Errorf("failed to remove previous build %s: %w", e.Name(), err)
+		}
+		if !silent {
+			fmt.Printf("Removed previous build %s\n", e.Name())
+		}
+	}
+	return nil
+}
+
+// gitOutput runs git in dir and returns its trimmed standard output.
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), msg)
+		}
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,134 @@
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/user/vc-env/internal/config"
+)
+
+// newFakeCheckout creates a git repository that looks like a vcluster
+// checkout: a Go module with the CLI main package and the version variable
+// set by -ldflags.
+func newFakeCheckout(t *testing.T) string {
+	t.Helper()
+	for _, tool := range []string{"go", "git"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s not available", tool)
+		}
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":            "module github.com/loft-sh/vcluster\n\ngo 1.21\n",
+		"pkg/upgrade/up.go": "package upgrade\n\nvar version = \"dev\"\n\nfunc Version() string { return version }\n",
+		"cmd/vclusterctl/main.go": "package main\n\nimport (\n\t\"fmt\"\n\n\t\"github.com/loft-sh/vcluster/pkg/upgrade\"\n)\n\n" +
+			"func main() { fmt.Println(\"vcluster version \" + upgrade.Version()) }\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git(t, dir, "init", "-q")
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "-m", "initial")
+	git(t, dir, "tag", "v0.24.0")
+	return dir
+}
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func TestInstallSource(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a Go binary")
+	}
+	checkout := newFakeCheckout(t)
+	root := setupInstallFrom(t)
+
+	if err := InstallSource(checkout, "", true); err != nil {
+		t.Fatalf("InstallSource: %v", err)
+	}
+	short, err := gitOutput(checkout, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := "dev-" + short
+	meta, ok, err := config.ReadVersionMeta(filepath.Join(root, "versions", first))
+	if err != nil || !ok {
+		t.Fatalf("expected %s to be installed with metadata (ok=%v, err=%v)", first, ok, err)
+	}
+	if meta.Source != config.SourceBuild || meta.Origin != checkout || meta.ReportedVersion != "0.24.0" {
+		t.Fatalf("unexpected metadata %+v", meta)
+	}
+
+	// A new commit produces a new dev version and replaces the old one.
+	if err := os.WriteFile(filepath.Join(checkout, "CHANGES"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git(t, checkout, "add", "-A")
+	git(t, checkout, "commit", "-q", "-m", "change")
+
+	if err := InstallSource(checkout, "", true); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	short, _ = gitOutput(checkout, "rev-parse", "--short", "HEAD")
+	if _, err := os.Stat(filepath.Join(root, "versions", "dev-"+short, "vcluster")); err != nil {
+		t.Fatalf("expected new dev build: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "versions", first)); !os.IsNotExist(err) {
+		t.Fatal("expected the previous dev build to be removed")
+	}
+
+	// Rebuilding under an explicit name replaces it in place.
+	for range 2 {
+		if err := InstallSource(checkout, "mine", true); err != nil {
+			t.Fatalf("InstallSource --as: %v", err)
+		}
+	}
+	meta, _, _ = config.ReadVersionMeta(filepath.Join(root, "versions", "mine"))
+	if !strings.HasPrefix(meta.ReportedVersion, "0.24.0-1-g") {
+		t.Fatalf("expected git describe version, got %q", meta.ReportedVersion)
+	}
+
+	// A later dev build keeps the build named with --as.
+	if err := InstallSource(checkout, "", true); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "versions", "mine", "vcluster")); err != nil {
+		t.Fatalf("expected the named build to be kept: %v", err)
+	}
+
+	// A version that was not built from the checkout is never replaced.
+	writeFakeVCluster(t, filepath.Join(root, "versions", "0.22.0"), "0.22.0")
+	err = InstallSource(checkout, "0.22.0", true)
+	if err == nil || !strings.Contains(err.Error(), "already installed and was not built from") {
+		t.Fatalf("expected a refusal to replace 0.22.0, got %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(root, "versions", "0.22.0", "vcluster"))
+	if !strings.Contains(string(data), "vcluster version 0.22.0") {
+		t.Fatalf("expected the release binary to be kept, got %q", data)
+	}
+}
+
+func TestInstallSource_NotACheckout(t *testing.T) {
+	setupInstallFrom(t)
+	err := InstallSource(t.TempDir(), "", true)
+	if err == nil || !strings.Contains(err.Error(), "not a vcluster checkout") {
+		t.Fatalf("expected checkout error, got %v", err)
+	}
+}
@@ -1,17 +1,24 @@
 package commands
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/user/vc-env/internal/cache"
 	"github.com/user/vc-env/internal/github"
+	"github.com/user/vc-env/internal/platform"
 )
 
 func TestInstall(t *testing.T) {
@@ -122,4 +129,317 @@ func TestInstall(t *testing.T) {
 			t.Fatal("binary was not written")
 		}
 	})
+
+	t.Run("offline fails fast for missing version", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("VCENV_ROOT", tmpDir)
+		t.Setenv("VCENV_OFFLINE", "1")
+		if err := os.MkdirAll(filepath.Join(tmpDir, "versions"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		called := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer server.Close()
+
+		client := &github.Client{
+			BaseURL:         server.URL,
+			DownloadBaseURL: server.URL,
+			HTTPClient:      server.Client(),
+		}
+
+		err := installWithClient(client, "0.31.0", true)
+		if err == nil {
+			t.Fatal("expected error when installing offline")
+		}
+		if !strings.Contains(err.Error(), "offline") {
+			t.Errorf("expected offline error, got %v", err)
+		}
+		if called {
+			t.Fatal("expected no network call in offline mode")
+		}
+	})
+
+	t.Run("reinstall is served from the download cache", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("VCENV_ROOT", tmpDir)
+		if err := os.MkdirAll(filepath.Join(tmpDir, "versions"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		version := "0.31.0"
+		binaryData := []byte("fake binary content")
+		checksum := sha256.Sum256(binaryData)
+		checksumStr := hex.EncodeToString(checksum[:])
+
+		downloads := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "checksums.txt") {
+				fmt.Fprintf(w, "%s  vcluster-linux-amd64\n%s  vcluster-linux-arm64\n%s  vcluster-darwin-amd64\n%s  vcluster-darwin-arm64\n", checksumStr, checksumStr, checksumStr, checksumStr)
+				return
+			}
+			if strings.Contains(r.URL.Path, "/releases/download/") {
+				downloads++
+			}
+			_, _ = w.Write(binaryData)
+		}))
+		defer server.Close()
+
+		client := &github.Client{
+			BaseURL:         server.URL,
+			DownloadBaseURL: server.URL,
+			HTTPClient:      server.Client(),
+		}
+
+		if err := installWithClient(client, version, true); err != nil {
+			t.Fatalf("first install: %v", err)
+		}
+		if err := os.RemoveAll(filepath.Join(tmpDir, "versions", version)); err != nil {
+			t.Fatal(err)
+		}
+
+		// A reinstall must not touch the network, even in offline mode.
+		t.Setenv("VCENV_OFFLINE", "1")
+		if err := installWithClient(client, version, true); err != nil {
+			t.Fatalf("reinstall: %v", err)
+		}
+		if downloads != 1 {
+			t.Fatalf("expected exactly one binary download, got %d", downloads)
+		}
+		data, err := os.ReadFile(filepath.Join(tmpDir, "versions", version, "vcluster"))
+		if err != nil {
+			t.Fatalf("binary was not written: %v", err)
+		}
+		if string(data) != string(binaryData) {
+			t.Fatalf("unexpected binary content %q", data)
+		}
+	})
+}
+
+// newAssetReleaseServer serves a release of version whose assets are given
+// by name and content, with digests computed from the content unless
+// overridden in digests.
+func newAssetReleaseServer(t *testing.T, version string, assets map[string][]byte, digests map[string]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/loft-sh/vcluster/releases/tags/v"+version {
+			release := github.Release{TagName: "v" + version}
+			for name, data := range assets {
+				digest, ok := digests[name]
+				if !ok {
+					digest = cache.Digest(data)
+				}
+				release.Assets = append(release.Assets, github.Asset{Name: name, Size: int64(len(data)), Digest: digest})
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(release)
+			return
+		}
+		if data, ok := assets[path.Base(r.URL.Path)]; ok && strings.Contains(r.URL.Path, "/download/v"+version+"/") {
+			_, _ = w.Write(data)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+}
+
+func TestInstall_ReleaseAssets(t *testing.T) {
+	host, err := platform.Detect()
+	if err != nil {
+		t.Skipf("unsupported platform: %v", err)
+	}
+	setup := func(t *testing.T) string {
+		tmpDir := t.TempDir()
+		t.Setenv("VCENV_ROOT", tmpDir)
+		t.Setenv("VCENV_DOWNLOAD_CACHE_DIR", "")
+		if err := os.MkdirAll(filepath.Join(tmpDir, "versions"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		return tmpDir
+	}
+	clientFor := func(server *httptest.Server) *github.Client {
+		return &github.Client{BaseURL: server.URL, DownloadBaseURL: server.URL, HTTPClient: server.Client()}
+	}
+
+	t.Run("selects the asset with a custom pattern and verifies its digest", func(t *testing.T) {
+		tmpDir := setup(t)
+		t.Setenv("VCENV_ASSET_PATTERN", "vcluster_{version}_{os}_{arch}")
+		name := fmt.Sprintf("vcluster_0.40.0_%s_%s", host.OS, host.Arch)
+		server := newAssetReleaseServer(t, "0.40.0", map[string][]byte{name: []byte("custom-named binary")}, nil)
+		defer server.Close()
+
+		if err := installWithClient(clientFor(server), "0.40.0", true); err != nil {
+			t.Fatalf("install: %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(tmpDir, "versions", "0.40.0", "vcluster"))
+		if err != nil || string(data) != "custom-named binary" {
+			t.Fatalf("unexpected binary %q (%v)", data, err)
+		}
+		// The digest alone is enough to keep the download for reuse.
+		if artifact, ok := newDownloadStoreForRoot().Get("0.40.0", platform.Key(host)); !ok || artifact.Name != name {
+			t.Fatalf("expected %s in the download cache", name)
+		}
+	})
+
+	t.Run("unpacks archive assets", func(t *testing.T) {
+		tmpDir := setup(t)
+		t.Setenv("VCENV_ASSET_PATTERN", "vcluster_{version}_{os}_{arch}.tar.gz")
+		t.Setenv("VCENV_ARCHIVE_BINARY_PATH", "")
+
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		content := "binary from archive"
+		if err := tw.WriteHeader(&tar.Header{Name: "vcluster", Mode: 0o750, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = tw.Write([]byte(content))
+		_ = tw.Close()
+		_ = gz.Close()
+
+		name := fmt.Sprintf("vcluster_0.40.0_%s_%s.tar.gz", host.OS, host.Arch)
+		checksums := fmt.Sprintf("%s  %s\n", cache.SHA256(buf.Bytes()), name)
+		server := newAssetReleaseServer(t, "0.40.0", map[string][]byte{
+			name:            buf.Bytes(),
+			"checksums.txt": []byte(checksums),
+		}, nil)
+		defer server.Close()
+
+		if err := installWithClient(clientFor(server), "0.40.0", true); err != nil {
+			t.Fatalf("install: %v", err)
+		}
+		binaryPath := filepath.Join(tmpDir, "versions", "0.40.0", "vcluster")
+		data, err := os.ReadFile(binaryPath)
+		if err != nil || string(data) != content {
+			t.Fatalf("unexpected binary %q (%v)", data, err)
+		}
+		if fi, err := os.Stat(binaryPath); err != nil || fi.Mode().Perm() != 0o750 {
+			t.Fatalf("expected archive permissions to be preserved, got %v (%v)", fi.Mode(), err)
+		}
+	})
+
+	t.Run("rejects a digest mismatch", func(t *testing.T) {
+		setup(t)
+		t.Setenv("VCENV_ASSET_PATTERN", "")
+		name := platform.BinaryName(host)
+		server := newAssetReleaseServer(t, "0.40.0",
+			map[string][]byte{name: []byte("tampered")},
+			map[string]string{name: cache.FormatDigest(strings.Repeat("0", 64))})
+		defer server.Close()
+
+		err := installWithClient(clientFor(server), "0.40.0", true)
+		if err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+			t.Fatalf("expected digest mismatch, got %v", err)
+		}
+	})
+
+	t.Run("lists available platforms when the host has no asset", func(t *testing.T) {
+		setup(t)
+		t.Setenv("VCENV_ASSET_PATTERN", "")
+		assets := map[string][]byte{"checksums.txt": []byte("")}
+		for _, p := range []string{"linux-amd64", "linux-arm64", "darwin-amd64", "darwin-arm64"} {
+			if p != platform.Key(host) {
+				assets["vcluster-"+p] = []byte(p)
+			}
+		}
+		server := newAssetReleaseServer(t, "0.40.0", assets, nil)
+		defer server.Close()
+
+		err := installWithClient(clientFor(server), "0.40.0", true)
+		if err == nil {
+			t.Fatal("expected error for missing platform")
+		}
+		msg := err.Error()
+		if !strings.Contains(msg, "not available for "+host.OS+"/"+host.Arch) || !strings.Contains(msg, "available platforms:") {
+			t.Fatalf("unexpected error %q", msg)
+		}
+		listed := msg[strings.Index(msg, "available platforms:"):]
+		if strings.Contains(listed, host.OS+"/"+host.Arch) || strings.Count(listed, "/") != 3 {
+			t.Fatalf("expected the three other platforms to be listed, got %q", listed)
+		}
+	})
+	t.Run("installs the first version of the version file", func(t *testing.T) {
+		tmpDir := setup(t)
+		t.Setenv("VCENV_ASSET_PATTERN", "")
+		t.Setenv("VCENV_VERSION", "")
+		t.Setenv("VCENV_RESOLVE_ORDER", "")
+		workDir := t.TempDir()
+		// The fallback is installed, so it is the active version, but
+		// install targets the preferred one.
+		if err := os.MkdirAll(filepath.Join(tmpDir, "versions", "0.39.2"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, "versions", "0.39.2", "vcluster"), []byte("old"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		content := "# migrating to 0.40\n0.40.0\n0.39.2 # fallback\n"
+		if err := os.WriteFile(filepath.Join(workDir, ".vcluster-version"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		origDir, _ := os.Getwd()
+		if err := os.Chdir(workDir); err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = os.Chdir(origDir) }()
+
+		server := newAssetReleaseServer(t, "0.40.0", map[string][]byte{platform.BinaryName(host): []byte("new")}, nil)
+		defer server.Close()
+
+		output := captureStdout(t, func() {
+			if err := installWithClient(clientFor(server), "", false); err != nil {
+				t.Fatalf("install: %v", err)
+			}
+		})
+		if !strings.Contains(output, "Version from "+filepath.Join(workDir, ".vcluster-version")+": 0.40.0") {
+			t.Fatalf("expected the version file to be reported, got %q", output)
+		}
+		if data, err := os.ReadFile(filepath.Join(tmpDir, "versions", "0.40.0", "vcluster")); err != nil || string(data) != "new" {
+			t.Fatalf("expected 0.40.0 to be installed, got %q (%v)", data, err)
+		}
+	})
+	t.Run("skips system in the version file", func(t *testing.T) {
+		tmpDir := setup(t)
+		t.Setenv("VCENV_ASSET_PATTERN", "")
+		t.Setenv("VCENV_VERSION", "")
+		t.Setenv("VCENV_RESOLVE_ORDER", "")
+		workDir := t.TempDir()
+		versionFile := filepath.Join(workDir, ".vcluster-version")
+		if err := os.WriteFile(versionFile, []byte("system\n0.40.0\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		origDir, _ := os.Getwd()
+		if err := os.Chdir(workDir); err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = os.Chdir(origDir) }()
+
+		server := newAssetReleaseServer(t, "0.40.0", map[string][]byte{platform.BinaryName(host): []byte("new")}, nil)
+		defer server.Close()
+
+		output := captureStdout(t, func() {
+			if err := installWithClient(clientFor(server), "", false); err != nil {
+				t.Fatalf("install: %v", err)
+			}
+		})
+		if !strings.Contains(output, "Version from "+versionFile+": 0.40.0") {
+			t.Fatalf("expected the version after system to be installed, got %q", output)
+		}
+		if _, err := os.Stat(filepath.Join(tmpDir, "versions", "0.40.0", "vcluster")); err != nil {
+			t.Fatalf("expected 0.40.0 to be installed: %v", err)
+		}
+
+		// A file that only names system leaves nothing to install.
+		if err := os.WriteFile(versionFile, []byte("system\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		output = captureStdout(t, func() {
+			if err := installWithClient(clientFor(server), "", false); err != nil {
+				t.Fatalf("install: %v", err)
+			}
+		})
+		if output != "Version from "+versionFile+": system (the vcluster on PATH); nothing to install\n" {
+			t.Fatalf("unexpected output %q", output)
+		}
+	})
 }
@@ -32,9 +32,31 @@ func List() error {
 
 	versions = semver.SortDescending(versions)
 
+	if desc, ok := describeSystemVersion(); ok {
+		fmt.Println(desc)
+	}
+	advisories := cachedAdvisories()
+	window, err := supportWindow(nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
 	for _, v := range versions {
-		fmt.Println(v)
+		fmt.Println(annotate(v, supportNote(window, v), vulnerabilityNote(advisories, v)))
 	}
 
 	return nil
 }
+
+// describeSystemVersion returns a "system (<path>, <version>)" line for the
+// vcluster found on PATH outside vc-env, and false if there is none.
+func describeSystemVersion() (string, bool) {
+	path, err := config.FindSystemBinary()
+	if err != nil {
+		return "", false
+	}
+	reported, err := probeBinary(path)
+	if err != nil || reported == "" {
+		reported = "unknown version"
+	}
+	return fmt.Sprintf("%s (%s, %s)", config.SystemVersion, path, reported), true
+}
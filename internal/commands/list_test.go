@@ -50,6 +50,7 @@ func TestList(t *testing.T) {
 	t.Run("lists installed versions with prereleases sorted newest to oldest", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		t.Setenv("VCENV_ROOT", tmpDir)
+		t.Setenv("VCENV_SUPPORT_WINDOW", "")
 
 		// Create versions including a pre-release
 		for _, v := range []string{"0.31.1-alpha", "0.31.1", "0.31.0", "0.1.0"} {
@@ -66,8 +67,9 @@ func TestList(t *testing.T) {
 		})
 
 		lines := strings.Split(strings.TrimSpace(output), "\n")
-		// Expect: 0.31.1, 0.31.1-alpha, 0.31.0, 0.1.0
-		expected := []string{"0.31.1", "0.31.1-alpha", "0.31.0", "0.1.0"}
+		// Expect: 0.31.1, 0.31.1-alpha, 0.31.0, 0.1.0, which is past
+		// end-of-life.
+		expected := []string{"0.31.1", "0.31.1-alpha", "0.31.0", "0.1.0  (eol)"}
 		if len(lines) != len(expected) {
 			t.Fatalf("expected %d versions, got %d: %v", len(expected), len(lines), lines)
 		}
@@ -97,3 +99,29 @@ func TestList(t *testing.T) {
 		}
 	})
 }
+
+func TestList_SystemVersion(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("VCENV_ROOT", tmpDir)
+	if err := os.MkdirAll(filepath.Join(tmpDir, "versions", "0.31.0"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	systemDir := t.TempDir()
+	systemBinary := filepath.Join(systemDir, "vcluster")
+	if err := os.WriteFile(systemBinary, []byte("#!/bin/sh\necho 'vcluster version 0.20.1'\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", filepath.Join(tmpDir, "shims")+string(os.PathListSeparator)+systemDir)
+
+	output := captureStdout(t, func() {
+		if err := List(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	want := "system (" + systemBinary + ", 0.20.1)"
+	if len(lines) != 2 || lines[0] != want || lines[1] != "0.31.0" {
+		t.Fatalf("expected %q followed by 0.31.0, got %q", want, lines)
+	}
+}
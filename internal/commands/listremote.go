@@ -2,6 +2,7 @@ package commands
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/user/vc-env/internal/github"
 )
@@ -17,6 +18,13 @@ Results are cached on disk (at $VCENV_ROOT/cache/releases.json) for one hour
 by default to avoid redundant network requests.  Set VCENV_CACHE_TTL to a Go
 duration string (e.g. "30m", "24h") to override the TTL.
 
+In offline mode (--offline or VCENV_OFFLINE=1) the disk cache or the built-in
+baseline is served without any network access.
+
+Versions outside the support window (VCENV_SUPPORT_WINDOW, by default the
+latest 3 minor lines) are marked "(deprecated)" or "(eol)", and versions
+denied by the version policy (VCENV_POLICY) with "(denied: <reason>)".
+
 Flags:
   --prerelease   Include pre-release versions (e.g. alpha, beta, rc)
   -h, --help      Show this help message`)
@@ -41,8 +49,22 @@ func listRemoteWithClient(client *github.Client, includePrerelease bool) error {
 		versions = pre
 	}
 
+	p, err := loadPolicy()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	window, err := supportWindow(stable)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
 	for _, v := range versions {
-		fmt.Println(v)
+		denied := ""
+		if p != nil {
+			if violation := p.Check(v); violation != nil {
+				denied = "denied: " + violation.Reason
+			}
+		}
+		fmt.Println(annotate(v, supportNote(window, v), denied))
 	}
 	return nil
 }
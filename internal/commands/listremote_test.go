@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -178,6 +179,105 @@ func TestListRemote_NoCache_NetworkFails_FallsBackToBaseline(t *testing.T) {
 	}
 }
 
+func TestListRemote_Offline_ServesStaleCacheWithoutNetwork(t *testing.T) {
+	root := t.TempDir()
+	c := cache.NewWithTTL(root+"/cache", -1)
+	if err := c.Save([]string{"0.30.0"}, []string{"0.30.0"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	t.Setenv("VCENV_ROOT", root)
+	t.Setenv("VCENV_CACHE_TTL", "1ns")
+	t.Setenv("VCENV_OFFLINE", "1")
+
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	client := &github.Client{
+		BaseURL:    server.URL,
+		HTTPClient: server.Client(),
+	}
+
+	out := captureStdout(t, func() {
+		if err := listRemoteWithClient(client, false); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	if called {
+		t.Fatal("expected no network call in offline mode")
+	}
+	if strings.TrimSpace(out) != "0.30.0" {
+		t.Errorf("expected stale cache contents, got: %s", out)
+	}
+}
+
+func TestListRemote_RepeatedFailures_SwitchToOffline(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("VCENV_ROOT", root)
+	t.Setenv("VCENV_CACHE_TTL", "1ns")
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	server.Close() // every request now fails to connect
+
+	client := &github.Client{
+		BaseURL:    server.URL,
+		HTTPClient: &http.Client{Timeout: 100 * time.Millisecond},
+	}
+
+	oldStderr := os.Stderr
+	devNull, _ := os.Open(os.DevNull)
+	os.Stderr = devNull
+	defer func() { os.Stderr = oldStderr }()
+
+	for i := 0; i < 3; i++ {
+		_ = captureStdout(t, func() {
+			_ = listRemoteWithClient(client, false)
+		})
+	}
+
+	if offline, _ := offlineMode(); !offline {
+		t.Fatal("expected automatic offline mode after repeated connection failures")
+	}
+}
+
+func TestListRemote_CertificateErrors_StayOnline(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("VCENV_ROOT", root)
+	t.Setenv("VCENV_CACHE_TTL", "1ns")
+
+	// The server is reachable but its certificate is not trusted.
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	client := &github.Client{
+		BaseURL:    server.URL,
+		HTTPClient: &http.Client{Timeout: time.Second},
+	}
+
+	oldStderr := os.Stderr
+	devNull, _ := os.Open(os.DevNull)
+	os.Stderr = devNull
+	defer func() { os.Stderr = oldStderr }()
+
+	for i := 0; i < 3; i++ {
+		_ = captureStdout(t, func() {
+			_ = listRemoteWithClient(client, false)
+		})
+	}
+
+	if offline, _ := offlineMode(); offline {
+		t.Fatal("expected certificate errors not to switch to offline mode")
+	}
+}
+
 func TestListRemote_OutputSortedDescending(t *testing.T) {
 	t.Setenv("VCENV_ROOT", t.TempDir())
 
@@ -8,9 +8,10 @@ import (
 )
 
 // Local manages the local (directory-level) vcluster version.
-// With a version argument: verifies it's installed and writes .vcluster-version.
+// With a version argument: verifies it's installed and writes .vcluster-version,
+// or the vcluster line of .tool-versions when toolVersions is set.
 // Without argument: reads and prints the local version or errors.
-func Local(version string) error {
+func Local(version string, toolVersions bool) error {
 	if err := config.RequireInit(); err != nil {
 		return err
 	}
@@ -29,19 +30,43 @@ func Local(version string) error {
 		return nil
 	}
 
-	// Verify version is installed
-	installed, err := config.IsVersionInstalled(version)
-	if err != nil {
+	// Verify version is installed.  Aliases are written as given, so that
+	// moving the alias later also moves this directory.
+	if err := requireInstalled(version); err != nil {
 		return err
 	}
-	if !installed {
-		return fmt.Errorf("version %s not installed", version)
+	if err := checkPolicy(config.ExpandAlias(version)); err != nil {
+		return err
+	}
+
+	if toolVersions {
+		if data, err := os.ReadFile(config.LocalVersionFileName); err == nil && len(config.ParseVersionFile(data)) > 0 {
+			fmt.Fprintf(os.Stderr, "warning: %s in this directory takes precedence over %s\n", config.LocalVersionFileName, config.ToolVersionsFileName)
+		}
+		return writeToolVersion(config.ToolVersionsFileName, version)
 	}
 
 	// Write .vcluster-version in current directory
-	if err := os.WriteFile(".vcluster-version", []byte(version+"\n"), 0o644); err != nil {
+	if err := os.WriteFile(config.LocalVersionFileName, []byte(version+"\n"), 0o644); err != nil {
 		return fmt.Errorf("failed to write .vcluster-version: %w", err)
 	}
 
 	return nil
 }
+
+// writeToolVersion sets the vcluster line of the .tool-versions file at path,
+// keeping the entries of other tools.
+func writeToolVersion(path, version string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	perm := os.FileMode(0o644)
+	if fi, err := os.Stat(path); err == nil {
+		perm = fi.Mode().Perm()
+	}
+	if err := os.WriteFile(path, config.SetToolVersion(data, version), perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -10,7 +10,7 @@ import (
 func TestLocal(t *testing.T) {
 	t.Run("fails when not initialized", func(t *testing.T) {
 		t.Setenv("VCENV_ROOT", "")
-		err := Local("0.31.0")
+		err := Local("0.31.0", false)
 		if err == nil {
 			t.Fatal("expected error when not initialized")
 		}
@@ -23,7 +23,7 @@ func TestLocal(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		err := Local("0.32.0")
+		err := Local("0.32.0", false)
 		if err == nil {
 			t.Fatal("expected error when version not installed")
 		}
@@ -53,7 +53,7 @@ func TestLocal(t *testing.T) {
 		}
 		defer func() { _ = os.Chdir(origDir) }()
 
-		err := Local("0.31.0")
+		err := Local("0.31.0", false)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -88,7 +88,7 @@ func TestLocal(t *testing.T) {
 		defer func() { _ = os.Chdir(origDir) }()
 
 		output := captureStdout(t, func() {
-			err := Local("")
+			err := Local("", false)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -114,7 +114,7 @@ func TestLocal(t *testing.T) {
 		}
 		defer func() { _ = os.Chdir(origDir) }()
 
-		err := Local("")
+		err := Local("", false)
 		if err == nil {
 			t.Fatal("expected error when no local version configured")
 		}
@@ -122,4 +122,43 @@ func TestLocal(t *testing.T) {
 			t.Fatalf("expected 'no local version configured' error, got: %v", err)
 		}
 	})
+
+	t.Run("updates the vcluster line of .tool-versions", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("VCENV_ROOT", tmpDir)
+		versionDir := filepath.Join(tmpDir, "versions", "0.31.0")
+		if err := os.MkdirAll(versionDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(versionDir, "vcluster"), []byte("binary"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		workDir := t.TempDir()
+		toolVersions := "golang 1.24.4\nvcluster 0.30.0 # pinned\nkubectl 1.30.0\n"
+		if err := os.WriteFile(filepath.Join(workDir, ".tool-versions"), []byte(toolVersions), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		origDir, _ := os.Getwd()
+		if err := os.Chdir(workDir); err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = os.Chdir(origDir) }()
+
+		if err := Local("0.31.0", true); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(workDir, ".tool-versions"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "golang 1.24.4\nvcluster 0.31.0 # pinned\nkubectl 1.30.0\n"
+		if string(data) != want {
+			t.Fatalf("expected %q, got %q", want, data)
+		}
+		if _, err := os.Stat(filepath.Join(workDir, ".vcluster-version")); !os.IsNotExist(err) {
+			t.Fatalf("expected no .vcluster-version to be written, got %v", err)
+		}
+	})
 }
@@ -0,0 +1,337 @@
+package commands
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/config"
+	"github.com/user/vc-env/internal/github"
+	"github.com/user/vc-env/internal/mirror"
+	"github.com/user/vc-env/internal/platform"
+	"github.com/user/vc-env/internal/semver"
+)
+
+// MirrorHelp prints the help message for the mirror command.
+func MirrorHelp() {
+	fmt.Println(`Usage: vc-env mirror sync --dir DIR [--versions CONSTRAINT] [--since VERSION]
+                          [--platform OS/ARCH[,...]] [--prerelease]
+       vc-env mirror serve [--dir DIR] [--addr ADDR]
+
+sync downloads vcluster releases into DIR using the same layout as GitHub
+release downloads (loft-sh/vcluster/releases/download/vX/...), plus an
+index.json listing every mirrored release.  Serve DIR with any static web
+server and set VCENV_DOWNLOAD_BASE_URL to its URL on vc-env clients.
+Re-running sync is incremental: files already present are verified against
+checksums.txt and only missing or corrupt files are downloaded.
+
+serve runs an HTTP server that answers the GitHub release API endpoints used
+by vc-env as well as the download paths, for a mirror directory or, without
+--dir, for the versions installed in $VCENV_ROOT.  Set both
+VCENV_API_BASE_URL and VCENV_DOWNLOAD_BASE_URL to its URL on clients.
+
+Flags for sync:
+  --dir          Mirror directory (required)
+  --versions     Version constraint, e.g. "0.21.x" or ">=0.20.0, <0.22.0" (default: all)
+  --since        Only versions at or above this one, e.g. "0.19"
+  --platform     Comma-separated list of platforms (default: current platform)
+  --prerelease   Include pre-release versions
+
+Flags for serve:
+  --dir          Mirror directory (default: $VCENV_ROOT/versions)
+  --addr         Listen address (default: ` + defaultMirrorAddr + `)
+
+  -h, --help     Show this help message`)
+}
+
+// defaultMirrorAddr is the listen address of "mirror serve".  It is loopback
+// only; pass e.g. --addr :8080 to serve other machines.
+const defaultMirrorAddr = "127.0.0.1:8080"
+
+// MirrorSync downloads the selected releases into a mirror directory.
+func MirrorSync(dir, constraint, since string, platforms []string, includePrerelease bool) error {
+	return mirrorSyncWithClient(github.NewClient(), dir, constraint, since, platforms, includePrerelease)
+}
+
+// MirrorServe serves a mirror directory, or the installed versions when dir
+// is empty, over HTTP until the server fails.
+func MirrorServe(dir, addr string) error {
+	if addr == "" {
+		addr = defaultMirrorAddr
+	}
+	handler, desc, err := newMirrorHandler(dir)
+	if err != nil {
+		return err
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid listen address %q: %w", addr, err)
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	url := "http://" + net.JoinHostPort(host, port)
+	fmt.Printf("Serving %s on %s\n", desc, url)
+	fmt.Printf("Point clients at it with:\n  export VCENV_API_BASE_URL=%s\n  export VCENV_DOWNLOAD_BASE_URL=%s\n", url, url)
+
+	return http.ListenAndServe(addr, handler)
+}
+
+// newMirrorHandler returns the handler for "mirror serve" and a description
+// of what it serves.
+func newMirrorHandler(dir string) (http.Handler, string, error) {
+	if dir != "" {
+		fi, err := os.Stat(dir)
+		if err != nil || !fi.IsDir() {
+			return nil, "", fmt.Errorf("mirror directory %s does not exist", dir)
+		}
+		return mirror.NewHandler(mirror.NewDirSource(dir)), "mirror " + dir, nil
+	}
+
+	if err := config.RequireInit(); err != nil {
+		return nil, "", err
+	}
+	root, _ := config.GetVCEnvRoot()
+	info, err := platform.Detect()
+	if err != nil {
+		return nil, "", err
+	}
+	versionsDir := filepath.Join(root, "versions")
+	src := mirror.NewVersionsSource(versionsDir, info)
+	return mirror.NewHandler(src), fmt.Sprintf("installed versions in %s (%s)", versionsDir, platform.Key(info)), nil
+}
+
+// mirrorSyncStats counts what a sync did.
+type mirrorSyncStats struct {
+	downloaded int
+	upToDate   int
+	skipped    int
+}
+
+// mirrorSyncWithClient is the testable core of MirrorSync.
+func mirrorSyncWithClient(client *github.Client, dir, constraint, since string, platforms []string, includePrerelease bool) error {
+	if dir == "" {
+		return fmt.Errorf("mirror directory not specified. Usage: vc-env mirror sync --dir DIR")
+	}
+
+	filters, err := parseVersionFilters(constraint, since)
+	if err != nil {
+		return err
+	}
+	infos, err := parsePlatforms(platforms)
+	if err != nil {
+		return err
+	}
+
+	stable, pre, err := getRemoteVersions(client)
+	if err != nil {
+		return err
+	}
+	candidates := stable
+	if includePrerelease {
+		candidates = pre
+	}
+
+	var selected []string
+	for _, v := range candidates {
+		if matchesAll(filters, v) {
+			selected = append(selected, v)
+		}
+	}
+	if len(selected) == 0 {
+		return fmt.Errorf("no releases match the given constraints")
+	}
+
+	idx, err := mirror.LoadIndex(dir)
+	if err != nil {
+		return err
+	}
+
+	var stats mirrorSyncStats
+	for _, v := range selected {
+		fmt.Printf("vcluster %s\n", v)
+		rel, ok, err := syncMirrorRelease(client, dir, v, infos, idx, &stats)
+		if err != nil {
+			return err
+		}
+		if ok {
+			idx.Upsert(rel)
+		}
+	}
+
+	if err := idx.Save(dir); err != nil {
+		return err
+	}
+
+	fmt.Printf("Mirrored %d releases into %s (%d downloaded, %d up to date, %d unavailable)\n",
+		len(selected), dir, stats.downloaded, stats.upToDate, stats.skipped)
+	return nil
+}
+
+// parseVersionFilters parses the --versions constraint and the --since lower
+// bound.  Both are optional.
+func parseVersionFilters(constraint, since string) ([]semver.Constraint, error) {
+	var filters []semver.Constraint
+	if constraint != "" {
+		c, err := semver.ParseConstraint(constraint)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, c)
+	}
+	if since != "" {
+		c, err := semver.ParseConstraint(">=" + since)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, c)
+	}
+	return filters, nil
+}
+
+// matchesAll reports whether version satisfies every filter.
+func matchesAll(filters []semver.Constraint, version string) bool {
+	v := semver.Parse(version)
+	for _, f := range filters {
+		if !f.Check(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// syncMirrorRelease brings one release of the mirror up to date and returns
+// its index entry.  Assets mirrored earlier for other platforms are kept.
+// It returns false when the release has no checksums and was skipped.
+func syncMirrorRelease(client *github.Client, dir, version string, infos []platform.Info, idx *mirror.Index, stats *mirrorSyncStats) (mirror.IndexRelease, bool, error) {
+	rel := mirror.IndexRelease{
+		TagName:    "v" + version,
+		Prerelease: semver.Parse(version).PreRelease != "",
+	}
+	assets := map[string]mirror.IndexAsset{}
+	for _, existing := range idx.Releases {
+		if existing.TagName == rel.TagName {
+			for _, a := range existing.Assets {
+				if _, err := os.Stat(mirror.AssetPath(dir, version, a.Name)); err == nil {
+					assets[a.Name] = a
+				}
+			}
+		}
+	}
+
+	offline, offlineReason := offlineMode()
+
+	// The binary and checksums names are selected from the release's asset
+	// list (see VCENV_ASSET_PATTERN).  Offline, the assets mirrored earlier
+	// stand in for it.
+	var release *github.Release
+	if !offline {
+		r, err := client.GetRelease(version)
+		recordNetworkResult(err)
+		if err == nil {
+			release = r
+		}
+	} else if len(assets) > 0 {
+		release = &github.Release{TagName: rel.TagName}
+		for name := range assets {
+			release.Assets = append(release.Assets, github.Asset{Name: name})
+		}
+	}
+	checksumName := "checksums.txt"
+	if release != nil && len(release.Assets) > 0 {
+		name, ok := platform.MatchChecksumAsset(release.AssetNames())
+		if !ok {
+			fmt.Println("  checksums: not published, skipping release")
+			stats.skipped++
+			return rel, false, nil
+		}
+		checksumName = name
+	}
+
+	checksumsPath := mirror.AssetPath(dir, version, checksumName)
+	checksums, err := os.ReadFile(checksumsPath)
+	if err != nil {
+		if offline {
+			return rel, false, fmt.Errorf("cannot mirror vcluster %s: %s", version, offlineReason)
+		}
+		checksums, err = client.DownloadBinary(client.DownloadURL(platform.ReleaseAssetPath(version, checksumName)))
+		recordNetworkResult(err)
+		if err != nil && !github.IsConnectionError(err) {
+			// Without checksums nothing can be verified; leave the release out.
+			fmt.Printf("  %s: unavailable (%v), skipping release\n", checksumName, err)
+			stats.skipped++
+			return rel, false, nil
+		}
+		if err != nil {
+			return rel, false, fmt.Errorf("failed to download checksums for vcluster %s: %w", version, err)
+		}
+		if err := cache.WriteFileAtomic(checksumsPath, checksums, 0o644); err != nil {
+			return rel, false, err
+		}
+	}
+	assets[checksumName] = mirrorAsset(checksumName, checksums)
+
+	store := newDownloadStoreForRoot()
+	for _, info := range infos {
+		asset, err := selectReleaseAsset(release, version, info)
+		if err != nil {
+			fmt.Printf("  %s/%s: not published for this release, skipping\n", info.OS, info.Arch)
+			stats.skipped++
+			continue
+		}
+		name := asset.name
+		expected, err := github.FindChecksum(string(checksums), name)
+		if err != nil {
+			fmt.Printf("  %s: not published for this release, skipping\n", name)
+			stats.skipped++
+			continue
+		}
+
+		path := mirror.AssetPath(dir, version, name)
+		if data, err := os.ReadFile(path); err == nil && cache.SHA256(data) == expected {
+			fmt.Printf("  %s: up to date\n", name)
+			assets[name] = mirrorAsset(name, data)
+			stats.upToDate++
+			continue
+		}
+
+		var data []byte
+		if artifact, ok := store.Get(version, platform.Key(info)); ok && cache.SHA256(artifact.Data) == expected {
+			data = artifact.Data
+		} else {
+			if offline {
+				return rel, false, fmt.Errorf("cannot mirror %s of vcluster %s: %s", name, version, offlineReason)
+			}
+			data, err = client.DownloadBinary(client.DownloadURL(platform.ReleaseAssetPath(version, name)))
+			recordNetworkResult(err)
+			if err != nil {
+				return rel, false, fmt.Errorf("failed to download %s of vcluster %s: %w", name, version, err)
+			}
+		}
+		if actual := cache.SHA256(data); actual != expected {
+			return rel, false, fmt.Errorf("checksum mismatch for %s of vcluster %s: expected %s, got %s", name, version, expected, actual)
+		}
+		if err := cache.WriteFileAtomic(path, data, 0o755); err != nil {
+			return rel, false, err
+		}
+		fmt.Printf("  %s: downloaded\n", name)
+		assets[name] = mirrorAsset(name, data)
+		stats.downloaded++
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(assets)) {
+		rel.Assets = append(rel.Assets, assets[name])
+	}
+	return rel, true, nil
+}
+
+// mirrorAsset builds an index entry for an asset.
+func mirrorAsset(name string, data []byte) mirror.IndexAsset {
+	return mirror.IndexAsset{Name: name, Size: int64(len(data)), Digest: cache.Digest(data)}
+}
@@ -0,0 +1,201 @@
+package commands
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/github"
+	"github.com/user/vc-env/internal/mirror"
+	"github.com/user/vc-env/internal/platform"
+)
+
+func TestMirrorSync(t *testing.T) {
+	server := newReleaseServer(t)
+	defer server.Close()
+	client := &github.Client{
+		BaseURL:         server.URL,
+		DownloadBaseURL: server.URL,
+		HTTPClient:      server.Client(),
+	}
+
+	root := t.TempDir()
+	t.Setenv("VCENV_ROOT", root)
+	t.Setenv("VCENV_DOWNLOAD_CACHE_DIR", "")
+	versions := []string{"0.22.0", "0.21.1", "0.21.0", "0.20.0"}
+	if err := cache.NewWithTTL(root+"/cache", time.Hour).Save(versions, versions); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	platforms := []string{"linux/amd64", "darwin/arm64"}
+	out := captureStdout(t, func() {
+		if err := mirrorSyncWithClient(client, dir, "0.21.x", "", platforms, false); err != nil {
+			t.Fatalf("mirror sync: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Mirrored 2 releases") || !strings.Contains(out, "4 downloaded") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+
+	data, err := os.ReadFile(mirror.AssetPath(dir, "0.21.1", "vcluster-darwin-arm64"))
+	if err != nil {
+		t.Fatalf("expected mirrored binary: %v", err)
+	}
+	if string(data) != "v0.21.1 vcluster-darwin-arm64" {
+		t.Fatalf("unexpected binary content %q", data)
+	}
+	if _, err := os.Stat(mirror.AssetPath(dir, "0.22.0", "checksums.txt")); !os.IsNotExist(err) {
+		t.Fatal("0.22.0 does not match the constraint and must not be mirrored")
+	}
+
+	idx, err := mirror.LoadIndex(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.Releases) != 2 || idx.Releases[0].TagName != "v0.21.1" {
+		t.Fatalf("unexpected index releases: %+v", idx.Releases)
+	}
+	if n := len(idx.Releases[0].Assets); n != 3 {
+		t.Fatalf("expected 3 assets (2 binaries + checksums), got %d", n)
+	}
+
+	// A second run is incremental.
+	out = captureStdout(t, func() {
+		if err := mirrorSyncWithClient(client, dir, "0.21.x", "", platforms, false); err != nil {
+			t.Fatalf("second mirror sync: %v", err)
+		}
+	})
+	if !strings.Contains(out, "0 downloaded, 4 up to date") {
+		t.Fatalf("expected incremental sync, got %q", out)
+	}
+
+	// Corrupt files are replaced.
+	if err := os.WriteFile(mirror.AssetPath(dir, "0.21.0", "vcluster-linux-amd64"), []byte("garbage"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	out = captureStdout(t, func() {
+		if err := mirrorSyncWithClient(client, dir, "", "0.21", platforms, false); err != nil {
+			t.Fatalf("third mirror sync: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Mirrored 3 releases") || !strings.Contains(out, "3 downloaded") {
+		t.Fatalf("expected the corrupt file and 0.22.0 to be downloaded, got %q", out)
+	}
+}
+
+func TestMirrorSync_AssetPattern(t *testing.T) {
+	t.Setenv("VCENV_ASSET_PATTERN", "vcluster_{version}_{os}_{arch}{ext}")
+	binary := []byte("custom vcluster")
+	checksums := []byte(cache.SHA256(binary) + "  vcluster_0.22.0_linux_amd64\n")
+	server := newAssetReleaseServer(t, "0.22.0", map[string][]byte{
+		"vcluster_0.22.0_linux_amd64": binary,
+		"vcluster_checksums.txt":      checksums,
+	}, nil)
+	defer server.Close()
+	client := &github.Client{BaseURL: server.URL, DownloadBaseURL: server.URL, HTTPClient: server.Client()}
+
+	root := t.TempDir()
+	t.Setenv("VCENV_ROOT", root)
+	t.Setenv("VCENV_DOWNLOAD_CACHE_DIR", "")
+	if err := cache.NewWithTTL(root+"/cache", time.Hour).Save([]string{"0.22.0"}, []string{"0.22.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	out := captureStdout(t, func() {
+		if err := mirrorSyncWithClient(client, dir, "", "", []string{"linux/amd64"}, false); err != nil {
+			t.Fatalf("mirror sync: %v", err)
+		}
+	})
+	if !strings.Contains(out, "1 downloaded") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+	for name, want := range map[string][]byte{"vcluster_0.22.0_linux_amd64": binary, "vcluster_checksums.txt": checksums} {
+		data, err := os.ReadFile(mirror.AssetPath(dir, "0.22.0", name))
+		if err != nil || string(data) != string(want) {
+			t.Fatalf("expected %s to be mirrored, got %q (%v)", name, data, err)
+		}
+	}
+}
+
+func TestMirrorSync_RequiresDir(t *testing.T) {
+	if err := MirrorSync("", "", "", nil, false); err == nil {
+		t.Fatal("expected error without --dir")
+	}
+}
+
+func TestMirrorSync_InvalidConstraint(t *testing.T) {
+	t.Setenv("VCENV_ROOT", t.TempDir())
+	if err := MirrorSync(t.TempDir(), ">=", "", nil, false); err == nil {
+		t.Fatal("expected error for invalid constraint")
+	}
+}
+
+func TestMirrorServe_InstallAndListRemote(t *testing.T) {
+	host, err := platform.Detect()
+	if err != nil {
+		t.Skipf("unsupported platform: %v", err)
+	}
+
+	// A mirror with one release for the current platform.
+	dir := t.TempDir()
+	name := platform.BinaryName(host)
+	binary := []byte("mirrored vcluster")
+	checksums := []byte(cache.SHA256(binary) + "  " + name + "\n")
+	if err := cache.WriteFileAtomic(mirror.AssetPath(dir, "0.21.1", name), binary, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.WriteFileAtomic(mirror.AssetPath(dir, "0.21.1", "checksums.txt"), checksums, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	idx := &mirror.Index{}
+	idx.Upsert(mirror.IndexRelease{TagName: "v0.21.1", Assets: []mirror.IndexAsset{mirrorAsset(name, binary), mirrorAsset("checksums.txt", checksums)}})
+	if err := idx.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	handler, _, err := newMirrorHandler(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	client := &github.Client{BaseURL: server.URL, DownloadBaseURL: server.URL, HTTPClient: server.Client()}
+
+	root := t.TempDir()
+	t.Setenv("VCENV_ROOT", root)
+	t.Setenv("VCENV_DOWNLOAD_CACHE_DIR", "")
+	if err := os.MkdirAll(filepath.Join(root, "versions"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := listRemoteWithClient(client, false); err != nil {
+			t.Fatalf("list-remote: %v", err)
+		}
+	})
+	if !strings.Contains(out, "0.21.1") {
+		t.Fatalf("expected mirrored release in list-remote output, got %q", out)
+	}
+
+	_ = captureStdout(t, func() {
+		if err := installWithClient(client, "0.21.1", true); err != nil {
+			t.Fatalf("install: %v", err)
+		}
+	})
+	data, err := os.ReadFile(filepath.Join(root, "versions", "0.21.1", "vcluster"))
+	if err != nil || string(data) != string(binary) {
+		t.Fatalf("expected mirrored binary to be installed, got %q (%v)", data, err)
+	}
+}
+
+func TestNewMirrorHandler_MissingDir(t *testing.T) {
+	if _, _, err := newMirrorHandler(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatal("expected error for missing mirror directory")
+	}
+}
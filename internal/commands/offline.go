@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/config"
+	"github.com/user/vc-env/internal/github"
+)
+
+// offlineMode reports whether network access is disabled, together with a
+// short human-readable reason.  Offline mode is either requested explicitly
+// (--offline / VCENV_OFFLINE=1) or entered automatically for a while after
+// repeated connection failures.
+func offlineMode() (bool, string) {
+	if config.IsOffline() {
+		return true, "offline mode enabled"
+	}
+	if until, ok := newNetworkStateForRoot().OfflineUntil(); ok {
+		return true, fmt.Sprintf("network unreachable, offline until %s", until.Local().Format("15:04"))
+	}
+	return false, ""
+}
+
+// recordNetworkResult updates the persisted network state after a request.
+// Only connection-level failures count towards automatic offline mode; HTTP
+// errors such as rate limiting prove the network is reachable.
+func recordNetworkResult(err error) {
+	state := newNetworkStateForRoot()
+	if err == nil || !github.IsConnectionError(err) {
+		_ = state.RecordSuccess()
+		return
+	}
+	tripped, saveErr := state.RecordFailure()
+	if saveErr != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not record network state: %v\n", saveErr)
+		return
+	}
+	if tripped {
+		fmt.Fprintln(os.Stderr, "warning: repeated connection failures; switching to offline mode for a while")
+	}
+}
+
+// newNetworkStateForRoot creates a NetworkState rooted at $VCENV_ROOT/cache.
+// If VCENV_ROOT is not set failures are not persisted.
+func newNetworkStateForRoot() *cache.NetworkState {
+	root, ok := config.GetVCEnvRoot()
+	if !ok {
+		return cache.NewNetworkState("")
+	}
+	return cache.NewNetworkState(filepath.Join(root, "cache"))
+}
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/config"
+	"github.com/user/vc-env/internal/github"
+	"github.com/user/vc-env/internal/pins"
+	"github.com/user/vc-env/internal/semver"
+)
+
+// OutdatedHelp prints the help message for the outdated command.
+func OutdatedHelp() {
+	fmt.Println(`Usage: vc-env outdated [flags] [PATH...]
+
+Compare the pinned and installed vcluster versions with the remote release
+list, and report for each outdated one the latest patch release of its minor
+line and the latest release overall.
+
+Pinned versions are VCENV_VERSION, the global version file and the local
+version files (.vcluster-version and .tool-versions) found under each PATH.
+Without a PATH the local version file in effect for the current directory is
+used.
+
+Exits with status 3 when a version is outdated, so that it can gate CI.
+
+Flags:
+  --ignore-prerelease  Ignore pre-release versions when looking for newer
+                       releases
+  -h, --help           Show this help message`)
+}
+
+// Outdated reports the pinned and installed versions that have newer
+// releases.
+func Outdated(paths []string, ignorePrerelease bool) error {
+	return outdatedWithClient(github.NewClient(), paths, ignorePrerelease)
+}
+
+// outdatedWithClient is the testable core of Outdated.
+func outdatedWithClient(client *github.Client, paths []string, ignorePrerelease bool) error {
+	if err := config.RequireInit(); err != nil {
+		return err
+	}
+	targets, err := outdatedTargets(paths)
+	if err != nil {
+		return err
+	}
+
+	stable, pre, err := getRemoteVersions(client)
+	if err != nil {
+		return err
+	}
+	releases := pre
+	if ignorePrerelease {
+		releases = stable
+	}
+	latest := cache.NewestVersion(releases)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	versions := targets.versions()
+	checked, outdated := 0, 0
+	for _, v := range versions {
+		if !semver.IsValid(v) {
+			continue
+		}
+		checked++
+		if latest == "" || !semver.Less(semver.Parse(v), semver.Parse(latest)) {
+			continue
+		}
+		if outdated == 0 {
+			fmt.Fprintln(w, "VERSION\tLATEST PATCH\tLATEST\tUSED BY")
+		}
+		outdated++
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", v, latestPatch(releases, v), latest, strings.Join(targets[v], ", "))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if outdated == 0 {
+		fmt.Printf("All %d pinned and installed versions are up to date.\n", checked)
+		return nil
+	}
+	return &ExitError{Code: ExitOutdated, Err: fmt.Errorf("%d pinned or installed version(s) outdated", outdated)}
+}
+
+// outdatedTargets returns the versions pinned by VCENV_VERSION, the global
+// version file and the local version files under paths, and the installed
+// versions, each with the places it is used from.  Without paths the local
+// version file in effect for the current directory is used.
+func outdatedTargets(paths []string) (versionUses, error) {
+	targets := versionUses{}
+	targets.addInstalled()
+	if v := os.Getenv("VCENV_VERSION"); v != "" {
+		targets.add(v, "VCENV_VERSION")
+	}
+	targets.addGlobal()
+
+	if len(paths) == 0 {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		if _, path, err := config.FindLocalVersionFileFrom(cwd); err == nil && pins.IsVersionFile(filepath.Base(path)) {
+			paths = []string{path}
+		}
+	}
+	found, err := pins.Find(paths, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, pin := range found {
+		for _, v := range pin.Versions {
+			targets.add(v, pin.Path)
+		}
+	}
+	return targets, nil
+}
+
+// latestPatch returns the newest of releases in the minor line of version,
+// or version itself if none is newer.
+func latestPatch(releases []string, version string) string {
+	v := semver.Parse(version)
+	newest := v
+	for _, r := range releases {
+		candidate := semver.Parse(r)
+		if candidate.Major == v.Major && candidate.Minor == v.Minor && semver.Less(newest, candidate) {
+			newest = candidate
+		}
+	}
+	return newest.Original
+}
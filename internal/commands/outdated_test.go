@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/github"
+)
+
+func TestOutdated(t *testing.T) {
+	setup := func(t *testing.T) (string, string) {
+		t.Helper()
+		tmpDir := t.TempDir()
+		t.Setenv("VCENV_ROOT", tmpDir)
+		t.Setenv("VCENV_VERSION", "")
+		t.Setenv("VCENV_RESOLVE_ORDER", "")
+		stable := []string{"0.22.1", "0.22.0", "0.21.3", "0.21.0", "0.20.2"}
+		pre := []string{"0.23.0-alpha.1", "0.22.1", "0.22.0", "0.21.3", "0.21.0", "0.20.2"}
+		if err := cache.NewWithTTL(filepath.Join(tmpDir, "cache"), time.Hour).Save(stable, pre); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(tmpDir, "versions", "0.22.1"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		projects := t.TempDir()
+		for dir, content := range map[string]string{
+			"a/.vcluster-version": "0.21.0\n",
+			"b/.tool-versions":    "vcluster 0.22.1\n",
+		} {
+			path := filepath.Join(projects, dir)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return tmpDir, projects
+	}
+	client := &github.Client{BaseURL: "http://127.0.0.1:0"}
+
+	t.Run("reports outdated pins", func(t *testing.T) {
+		_, projects := setup(t)
+		t.Setenv("VCENV_VERSION", "0.20.2")
+		var err error
+		out := captureStdout(t, func() {
+			err = outdatedWithClient(client, []string{projects}, true)
+		})
+		var exitErr *ExitError
+		if !errors.As(err, &exitErr) || exitErr.Code != ExitOutdated {
+			t.Fatalf("expected an ExitError with code %d, got %v", ExitOutdated, err)
+		}
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("expected a header and 2 rows, got %q", out)
+		}
+		if fields := strings.Fields(lines[1]); len(fields) != 4 || fields[0] != "0.21.0" || fields[1] != "0.21.3" || fields[2] != "0.22.1" ||
+			fields[3] != filepath.Join(projects, "a", ".vcluster-version") {
+			t.Fatalf("unexpected row %q", lines[1])
+		}
+		if fields := strings.Fields(lines[2]); fields[0] != "0.20.2" || fields[1] != "0.20.2" || fields[3] != "VCENV_VERSION" {
+			t.Fatalf("unexpected row %q", lines[2])
+		}
+	})
+
+	t.Run("prereleases count unless ignored", func(t *testing.T) {
+		root, _ := setup(t)
+		var err error
+		out := captureStdout(t, func() {
+			err = outdatedWithClient(client, nil, false)
+		})
+		if err == nil || !strings.Contains(out, "0.23.0-alpha.1") {
+			t.Fatalf("expected 0.22.1 to be outdated by a prerelease, got %q (%v)", out, err)
+		}
+
+		if err := os.WriteFile(filepath.Join(root, "version"), []byte("0.22.1\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		out = captureStdout(t, func() {
+			err = outdatedWithClient(client, nil, true)
+		})
+		if err != nil || out != "All 1 pinned and installed versions are up to date.\n" {
+			t.Fatalf("unexpected result %q (%v)", out, err)
+		}
+	})
+}
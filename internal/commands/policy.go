@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/user/vc-env/internal/config"
+	"github.com/user/vc-env/internal/policy"
+)
+
+// policyFileName is the policy read from $VCENV_ROOT when VCENV_POLICY is
+// not set.
+const policyFileName = "policy.json"
+
+// loadPolicy returns the version policy in effect, or nil if none is
+// configured.  The policy is read from VCENV_POLICY, a file path or an
+// http(s) URL, or else from $VCENV_ROOT/policy.json if it exists.
+func loadPolicy() (*policy.Policy, error) {
+	root, _ := config.GetVCEnvRoot()
+	source := os.Getenv("VCENV_POLICY")
+	if source == "" {
+		if root == "" {
+			return nil, nil
+		}
+		source = filepath.Join(root, policyFileName)
+		if _, err := os.Stat(source); os.IsNotExist(err) {
+			return nil, nil
+		}
+	}
+
+	cacheDir := ""
+	if root != "" {
+		cacheDir = filepath.Join(root, "cache")
+	}
+	offline, _ := offlineMode()
+	return policy.Load(source, cacheDir, offline)
+}
+
+// checkPolicy returns an error when version is denied by an enforced policy.
+// A policy in warn mode only prints a warning.  A policy that is configured
+// but cannot be loaded is an error, so that it cannot be bypassed by making
+// it unreachable.
+func checkPolicy(version string) error {
+	p, err := loadPolicy()
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return nil
+	}
+	v := p.Check(version)
+	if v == nil {
+		return nil
+	}
+	if p.Enforced() {
+		return v
+	}
+	fmt.Fprintf(os.Stderr, "warning: %v\n", v)
+	return nil
+}
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/github"
+)
+
+func TestPolicy(t *testing.T) {
+	setup := func(t *testing.T, policy string) string {
+		t.Helper()
+		tmpDir := t.TempDir()
+		t.Setenv("VCENV_ROOT", tmpDir)
+		t.Setenv("VCENV_POLICY", "")
+		t.Setenv("VCENV_VERSION", "")
+		t.Setenv("VCENV_RESOLVE_ORDER", "")
+		for _, v := range []string{"0.19.2", "0.21.0"} {
+			versionDir := filepath.Join(tmpDir, "versions", v)
+			if err := os.MkdirAll(versionDir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(versionDir, "vcluster"), []byte("binary"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, "policy.json"), []byte(policy), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return tmpDir
+	}
+
+	t.Run("enforce mode refuses denied versions", func(t *testing.T) {
+		setup(t, `{"denied": [{"version": "0.19.2", "reason": "CVE-2026-0001"}]}`)
+		want := "version 0.19.2 is denied by policy: CVE-2026-0001"
+		if err := Install("0.19.2", true); err == nil || err.Error() != want {
+			t.Fatalf("install: expected %q, got %v", want, err)
+		}
+		src := filepath.Join(t.TempDir(), "vcluster")
+		if err := os.WriteFile(src, []byte(fakeVCluster), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := InstallFrom(src, "", "", "0.19.2", true); err == nil || err.Error() != want {
+			t.Fatalf("install --from: expected %q, got %v", want, err)
+		}
+		if err := Global("0.19.2"); err == nil || err.Error() != want {
+			t.Fatalf("global: expected %q, got %v", want, err)
+		}
+		if err := Shell("0.19.2"); err == nil || err.Error() != want {
+			t.Fatalf("shell: expected %q, got %v", want, err)
+		}
+
+		t.Setenv("VCENV_VERSION", "0.19.2")
+		if err := WhichShim(); err == nil || err.Error() != want {
+			t.Fatalf("shim: expected %q, got %v", want, err)
+		}
+		t.Setenv("VCENV_VERSION", "0.21.0")
+		if err := WhichShim(); err != nil {
+			t.Fatalf("shim: unexpected error for an allowed version: %v", err)
+		}
+	})
+
+	t.Run("warn mode allows denied versions", func(t *testing.T) {
+		setup(t, `{"mode": "warn", "allowed": [">=0.20.0"]}`)
+		workDir := t.TempDir()
+		origDir, _ := os.Getwd()
+		if err := os.Chdir(workDir); err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = os.Chdir(origDir) }()
+
+		if err := Local("0.19.2", false); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(workDir, ".vcluster-version")); err != nil {
+			t.Fatalf("expected .vcluster-version to be written: %v", err)
+		}
+	})
+
+	t.Run("an unreadable policy is an error", func(t *testing.T) {
+		setup(t, `{"mode": "audit"}`)
+		if err := Install("0.21.0", true); err == nil || !strings.Contains(err.Error(), "unknown mode") {
+			t.Fatalf("expected an invalid policy error, got %v", err)
+		}
+	})
+
+	t.Run("list-remote annotates denied versions", func(t *testing.T) {
+		root := setup(t, `{"denied": [{"version": "0.30.0", "reason": "CVE-2026-0002"}]}`)
+		if err := cache.NewWithTTL(filepath.Join(root, "cache"), time.Hour).Save([]string{"0.31.0", "0.30.0"}, nil); err != nil {
+			t.Fatal(err)
+		}
+		out := captureStdout(t, func() {
+			if err := listRemoteWithClient(&github.Client{BaseURL: "http://127.0.0.1:0"}, false); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+		if out != "0.31.0\n0.30.0  (denied: CVE-2026-0002)\n" {
+			t.Fatalf("unexpected output %q", out)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/user/vc-env/internal/config"
+)
+
+// ResolveHelp prints the help message for the resolve command.
+func ResolveHelp() {
+	fmt.Println(`Usage: vc-env resolve [--explain]
+
+Print the vcluster version that is active in the current directory.
+
+Flags:
+  --explain   Print every step taken: the resolve order, each directory and
+              file checked, files that were skipped and why, the environment
+              variables seen, and which source won
+  -h, --help  Show this help message
+
+The order of the sources is set by VCENV_RESOLVE_ORDER.  The upward search
+for version files stops below the directories listed in the colon-separated
+VCENV_CEILING_DIRECTORIES.`)
+}
+
+// Resolve prints the active version, and with explain every step the
+// resolver took to find it.
+func Resolve(explain bool) error {
+	if err := config.RequireInit(); err != nil {
+		return err
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	if !explain {
+		r, err := config.ResolveFrom(dir)
+		if err != nil {
+			return err
+		}
+		fmt.Println(r.Version)
+		return nil
+	}
+
+	r, steps, err := config.ResolveExplained(dir)
+	for _, step := range steps {
+		fmt.Println(step)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println(r.Version)
+	return nil
+}
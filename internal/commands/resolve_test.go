@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("VCENV_ROOT", tmpDir)
+	t.Setenv("VCENV_VERSION", "")
+	t.Setenv("VCENV_RESOLVE_ORDER", "")
+	if err := os.MkdirAll(filepath.Join(tmpDir, "versions"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	workDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workDir, ".vcluster-version"), []byte("0.31.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	origDir, _ := os.Getwd()
+	if err := os.Chdir(workDir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(origDir) }()
+
+	output := captureStdout(t, func() {
+		if err := Resolve(false); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	if strings.TrimSpace(output) != "0.31.0" {
+		t.Fatalf("expected 0.31.0, got %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		if err := Resolve(true); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if lines[len(lines)-1] != "0.31.0" || !strings.Contains(output, "VCENV_VERSION is not set") || !strings.Contains(output, "resolved 0.31.0 from") {
+		t.Fatalf("unexpected explanation %q", output)
+	}
+
+	t.Setenv("VCENV_RESOLVE_ORDER", "env")
+	output = captureStdout(t, func() {
+		if err := Resolve(true); err == nil {
+			t.Fatal("expected an error when no provider supplies a version")
+		}
+	})
+	if !strings.Contains(output, "no provider supplied a version") {
+		t.Fatalf("expected the explanation to be printed on failure, got %q", output)
+	}
+}
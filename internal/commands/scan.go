@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/user/vc-env/internal/config"
+	"github.com/user/vc-env/internal/github"
+	"github.com/user/vc-env/internal/pins"
+)
+
+// ScanHelp prints the help message for the scan command.
+func ScanHelp() {
+	fmt.Println(`Usage: vc-env scan [DIR...]
+
+List every .vcluster-version and .tool-versions file under each DIR (default:
+the current directory) with the version it selects and whether that version
+is installed.
+
+Hidden directories, dependency directories such as node_modules, and paths
+ignored by .gitignore files are skipped.
+
+Install the missing versions with: vc-env install --all-pinned DIR...
+
+Flags:
+  -h, --help  Show this help message`)
+}
+
+// Scan lists the version files under dirs and the install status of the
+// versions they select.
+func Scan(dirs []string) error {
+	if err := config.RequireInit(); err != nil {
+		return err
+	}
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	found, err := pins.Find(dirs, true)
+	if err != nil {
+		return err
+	}
+	if len(found) == 0 {
+		fmt.Printf("No .vcluster-version or .tool-versions file found under %s.\n", strings.Join(dirs, ", "))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tVERSION\tSTATUS")
+	missing := 0
+	for _, pin := range found {
+		version := config.SelectVersion(pin.Versions)
+		status := "installed"
+		if version == config.SystemVersion {
+			status = "system"
+		} else if installed, err := config.IsVersionInstalled(version); err != nil || !installed {
+			status = "not installed"
+			missing++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", pin.Path, version, status)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if missing > 0 {
+		fmt.Printf("\n%d pin(s) not installed. Install them with: vc-env install --all-pinned %s\n", missing, strings.Join(dirs, " "))
+	}
+	return nil
+}
+
+// InstallAllPinned installs the versions selected by the version files under
+// dirs that are not installed yet.
+func InstallAllPinned(dirs []string, silent bool) error {
+	return installAllPinnedWithClient(github.NewClient(), dirs, silent)
+}
+
+// installAllPinnedWithClient is the testable core of InstallAllPinned.  A
+// version that fails to install does not stop the others.
+func installAllPinnedWithClient(client *github.Client, dirs []string, silent bool) error {
+	if err := config.RequireInit(); err != nil {
+		return err
+	}
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	found, err := pins.Find(dirs, true)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	seen := map[string]bool{}
+	for _, pin := range found {
+		version := config.SelectVersion(pin.Versions)
+		if seen[version] || version == config.SystemVersion {
+			continue
+		}
+		seen[version] = true
+		if installed, err := config.IsVersionInstalled(version); err == nil && !installed {
+			missing = append(missing, version)
+		}
+	}
+	if len(missing) == 0 {
+		fmt.Printf("All %d pinned version(s) are installed.\n", len(seen))
+		return nil
+	}
+
+	var failed []string
+	for _, version := range missing {
+		if err := installWithClient(client, version, silent); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to install %s: %v\n", version, err)
+			failed = append(failed, version)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to install %d of %d pinned version(s): %s", len(failed), len(missing), strings.Join(failed, ", "))
+	}
+	fmt.Printf("Installed %d pinned version(s).\n", len(missing))
+	return nil
+}
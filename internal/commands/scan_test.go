@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/user/vc-env/internal/github"
+	"github.com/user/vc-env/internal/platform"
+)
+
+func TestScan(t *testing.T) {
+	host, err := platform.Detect()
+	if err != nil {
+		t.Skipf("unsupported platform: %v", err)
+	}
+	tmpDir := t.TempDir()
+	t.Setenv("VCENV_ROOT", tmpDir)
+	t.Setenv("VCENV_DOWNLOAD_CACHE_DIR", "")
+	t.Setenv("VCENV_POLICY", "")
+	writeFakeVCluster(t, filepath.Join(tmpDir, "versions", "0.21.0"), "0.21.0")
+
+	src := t.TempDir()
+	for path, content := range map[string]string{
+		".gitignore":                        "dist/\n",
+		"api/.vcluster-version":             "0.21.0\n",
+		"web/.vcluster-version":             "0.40.0\n",
+		"web/node_modules/x/.tool-versions": "vcluster 0.1.0\n",
+		"dist/.vcluster-version":            "0.1.0\n",
+	} {
+		path = filepath.Join(src, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := captureStdout(t, func() {
+		if err := Scan([]string{src}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected a header, 2 pins and a hint, got %q", out)
+	}
+	if fields := strings.Fields(lines[1]); len(fields) != 3 || fields[0] != filepath.Join(src, "api", ".vcluster-version") || fields[2] != "installed" {
+		t.Fatalf("unexpected row %q", lines[1])
+	}
+	if fields := strings.Fields(lines[2]); len(fields) != 4 || fields[1] != "0.40.0" || fields[2]+" "+fields[3] != "not installed" {
+		t.Fatalf("unexpected row %q", lines[2])
+	}
+	if !strings.Contains(lines[4], "vc-env install --all-pinned "+src) {
+		t.Fatalf("unexpected hint %q", lines[4])
+	}
+
+	server := newAssetReleaseServer(t, "0.40.0", map[string][]byte{platform.BinaryName(host): []byte("binary")}, nil)
+	defer server.Close()
+	client := &github.Client{BaseURL: server.URL, DownloadBaseURL: server.URL, HTTPClient: server.Client()}
+	out = captureStdout(t, func() {
+		if err := installAllPinnedWithClient(client, []string{src}, true); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	if out != "Installed 1 pinned version(s).\n" {
+		t.Fatalf("unexpected output %q", out)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "versions", "0.40.0", "vcluster")); err != nil {
+		t.Fatalf("expected 0.40.0 to be installed: %v", err)
+	}
+
+	out = captureStdout(t, func() {
+		if err := installAllPinnedWithClient(client, []string{src}, true); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	if out != "All 2 pinned version(s) are installed.\n" {
+		t.Fatalf("unexpected output %q", out)
+	}
+}
@@ -26,12 +26,11 @@ func Shell(version string) error {
 	}
 
 	// Verify version is installed
-	installed, err := config.IsVersionInstalled(version)
-	if err != nil {
+	if err := requireInstalled(version); err != nil {
 		return err
 	}
-	if !installed {
-		return fmt.Errorf("version %s not installed", version)
+	if err := checkPolicy(config.ExpandAlias(version)); err != nil {
+		return err
 	}
 
 	// Output export command for the shell function wrapper to eval
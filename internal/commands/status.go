@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/user/vc-env/internal/config"
@@ -22,14 +23,32 @@ func Status() error {
 
 	fmt.Fprintf(w, "VCENV_ROOT:\t%s\n", root)
 
+	if order, err := config.ResolveOrder(); err != nil {
+		fmt.Fprintf(w, "Resolve order:\t%v\n", err)
+	} else {
+		fmt.Fprintf(w, "Resolve order:\t%s\n", strings.Join(order, ", "))
+	}
+
 	version, source := getActiveVersionWithSource()
 	if version == "" {
 		fmt.Fprintf(w, "Active version:\tnone\n")
 	} else {
 		fmt.Fprintf(w, "Active version:\t%s (set by %s)\n", version, source)
-		binaryPath, _ := config.GetBinaryPath(version)
+		binaryPath, err := config.GetBinaryPath(version)
+		if err != nil {
+			binaryPath = "not found"
+		}
 		fmt.Fprintf(w, "Binary path:\t%s\n", binaryPath)
 	}
+	window, windowErr := supportWindow(nil)
+	if windowErr != nil {
+		fmt.Fprintf(w, "Support window:\t%v\n", windowErr)
+	} else if status, ok := window.Classify(version); ok {
+		fmt.Fprintf(w, "Support status:\t%s (the oldest supported line is %s)\n", status, window.Oldest)
+		if target := window.UpgradeTarget(version); target != "" {
+			fmt.Fprintf(w, "Recommended upgrade:\t%s\n", target)
+		}
+	}
 	w.Flush()
 
 	versionsDir := filepath.Join(root, "versions")
@@ -43,41 +62,49 @@ func Status() error {
 		}
 		installed = semver.SortDescending(installed)
 		fmt.Printf("\nInstalled versions (%d):\n", len(installed))
+		if desc, ok := describeSystemVersion(); ok {
+			if version == config.SystemVersion {
+				fmt.Printf("\t* %s\n", desc)
+			} else {
+				fmt.Printf("\t  %s\n", desc)
+			}
+		}
+		advisories := cachedAdvisories()
 		for _, v := range installed {
+			line := annotate(v, supportNote(window, v), vulnerabilityNote(advisories, v))
 			if v == version {
-				fmt.Printf("\t* %s\n", v)
+				fmt.Printf("\t* %s\n", line)
 			} else {
-				fmt.Printf("\t  %s\n", v)
+				fmt.Printf("\t  %s\n", line)
 			}
 		}
 	} else {
 		fmt.Println("\nInstalled versions: none")
 	}
 
+	if aliases, err := config.ListAliases(); err == nil && len(aliases) > 0 {
+		fmt.Printf("\nAliases (%d):\n", len(aliases))
+		for _, a := range aliases {
+			line := fmt.Sprintf("%s -> %s", a.Name, a.Version)
+			if installed, err := config.IsVersionInstalled(a.Version); err == nil && !installed && a.Version != config.SystemVersion {
+				line += " (not installed)"
+			}
+			fmt.Printf("\t%s\n", line)
+		}
+	}
+
 	return nil
 }
 
+// getActiveVersionWithSource resolves the active version and describes the
+// source that supplied it.  Both are empty when no version is configured.
 func getActiveVersionWithSource() (string, string) {
-	// 1. Check VCENV_VERSION env var (shell version)
-	if v := os.Getenv("VCENV_VERSION"); v != "" {
-		return v, "VCENV_VERSION environment variable"
-	}
-
-	// 2. Walk up directories looking for .vcluster-version (local version)
-	dir, err := os.Getwd()
-	if err == nil {
-		if v, err := config.FindLocalVersionFrom(dir); err == nil && v != "" {
-			return v, ".vcluster-version file"
-		}
+	r, err := config.Resolve()
+	if err != nil {
+		return "", ""
 	}
-
-	// 3. Check global version file
-	root, ok := config.GetVCEnvRoot()
-	if ok {
-		if v, err := config.ReadGlobalVersion(root); err == nil && v != "" {
-			return v, "global version file"
-		}
+	if r.Alias != "" {
+		return r.Version, fmt.Sprintf("%s via alias %s", r.Description, r.Alias)
 	}
-
-	return "", ""
+	return r.Version, r.Description
 }
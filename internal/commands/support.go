@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/config"
+	"github.com/user/vc-env/internal/support"
+)
+
+const (
+	// eolWarningsFileName records under $VCENV_ROOT/cache/ when the shim
+	// last warned about each end-of-life version.
+	eolWarningsFileName = "eol-warnings.json"
+
+	// eolWarningInterval is how often the shim warns about the same
+	// end-of-life version.
+	eolWarningInterval = 24 * time.Hour
+)
+
+// supportWindow returns the support window configured by
+// VCENV_SUPPORT_WINDOW.  When releases is nil the releases known to the
+// release cache, regardless of its age, or the built-in baseline are used, so
+// that no network access is needed.
+func supportWindow(releases []string) (*support.Window, error) {
+	if releases == nil {
+		releases = knownReleases()
+	}
+	w, err := support.NewWindow(os.Getenv("VCENV_SUPPORT_WINDOW"), releases)
+	if err != nil {
+		return nil, fmt.Errorf("VCENV_SUPPORT_WINDOW: %w", err)
+	}
+	return w, nil
+}
+
+// knownReleases returns the stable releases in the release cache, regardless
+// of its age, or the built-in baseline.  It never accesses the network.
+func knownReleases() []string {
+	if root, ok := config.GetVCEnvRoot(); ok {
+		if stable, _, ok := cache.New(filepath.Join(root, "cache")).LoadStale(); ok {
+			return stable
+		}
+	}
+	return cache.BaselineVersions()
+}
+
+// supportNote returns "deprecated" or "eol" for a version outside the
+// support window, or "" otherwise.  A nil window classifies nothing.
+func supportNote(w *support.Window, version string) string {
+	if w == nil {
+		return ""
+	}
+	if status, ok := w.Classify(version); ok && status != support.Supported {
+		return status
+	}
+	return ""
+}
+
+// annotate appends the non-empty notes to version, e.g.
+// "0.15.3  (eol; vulnerable: CVE-2026-0001)".
+func annotate(version string, notes ...string) string {
+	var kept []string
+	for _, n := range notes {
+		if n != "" {
+			kept = append(kept, n)
+		}
+	}
+	if len(kept) == 0 {
+		return version
+	}
+	return fmt.Sprintf("%s  (%s)", version, strings.Join(kept, "; "))
+}
+
+// warnEOL prints a one-line warning to stderr when version is end-of-life,
+// at most once per eolWarningInterval for each version.  It is used by the
+// shim, so it never fails.
+func warnEOL(version string) {
+	w, err := supportWindow(nil)
+	if err != nil {
+		return
+	}
+	if status, ok := w.Classify(version); !ok || status != support.EOL {
+		return
+	}
+
+	root, ok := config.GetVCEnvRoot()
+	if !ok {
+		return
+	}
+	path := filepath.Join(root, "cache", eolWarningsFileName)
+	warned := map[string]time.Time{}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &warned)
+	}
+	if time.Since(warned[version]) < eolWarningInterval {
+		return
+	}
+
+	msg := fmt.Sprintf("vc-env: vcluster %s is end-of-life (the oldest supported line is %s)", version, w.Oldest)
+	if target := w.UpgradeTarget(version); target != "" {
+		msg += "; upgrade with: vc-env install " + target
+	}
+	fmt.Fprintln(os.Stderr, msg)
+
+	warned[version] = time.Now().UTC()
+	if data, err := json.MarshalIndent(warned, "", "  "); err == nil {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err == nil {
+			_ = os.WriteFile(path, data, 0o644)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/github"
+)
+
+func TestSupportWindow(t *testing.T) {
+	setup := func(t *testing.T) string {
+		t.Helper()
+		tmpDir := t.TempDir()
+		t.Setenv("VCENV_ROOT", tmpDir)
+		t.Setenv("VCENV_SUPPORT_WINDOW", "2")
+		t.Setenv("VCENV_POLICY", "")
+		t.Setenv("VCENV_VERSION", "")
+		t.Setenv("VCENV_RESOLVE_ORDER", "")
+		releases := []string{"0.22.1", "0.22.0", "0.21.3", "0.21.0", "0.20.2", "0.15.3"}
+		if err := cache.NewWithTTL(filepath.Join(tmpDir, "cache"), time.Hour).Save(releases, releases); err != nil {
+			t.Fatal(err)
+		}
+		return tmpDir
+	}
+
+	t.Run("list-remote classifies versions", func(t *testing.T) {
+		setup(t)
+		out := captureStdout(t, func() {
+			if err := listRemoteWithClient(&github.Client{BaseURL: "http://127.0.0.1:0"}, false); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+		want := "0.22.1\n0.22.0\n0.21.3\n0.21.0\n0.20.2  (deprecated)\n0.15.3  (eol)\n"
+		if out != want {
+			t.Fatalf("expected %q, got %q", want, out)
+		}
+	})
+
+	t.Run("status recommends an upgrade", func(t *testing.T) {
+		root := setup(t)
+		writeFakeVCluster(t, filepath.Join(root, "versions", "0.20.2"), "0.20.2")
+		t.Setenv("VCENV_VERSION", "0.20.2")
+		out := captureStdout(t, func() {
+			if err := Status(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+		if !strings.Contains(out, "deprecated (the oldest supported line is 0.21)") ||
+			!strings.Contains(out, "Recommended upgrade:  0.21.3") ||
+			!strings.Contains(out, "* 0.20.2  (deprecated)") {
+			t.Fatalf("unexpected output %q", out)
+		}
+	})
+
+	t.Run("the shim warns about EOL versions once a day", func(t *testing.T) {
+		root := setup(t)
+		writeFakeVCluster(t, filepath.Join(root, "versions", "0.15.3"), "0.15.3")
+		t.Setenv("VCENV_VERSION", "0.15.3")
+
+		runShim := func() string {
+			var stderrBuf bytes.Buffer
+			oldStderr := os.Stderr
+			r, w, _ := os.Pipe()
+			os.Stderr = w
+			captureStdout(t, func() {
+				if err := WhichShim(); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			})
+			_ = w.Close()
+			os.Stderr = oldStderr
+			_, _ = io.Copy(&stderrBuf, r)
+			return stderrBuf.String()
+		}
+
+		want := "vc-env: vcluster 0.15.3 is end-of-life (the oldest supported line is 0.21); upgrade with: vc-env install 0.21.3\n"
+		if got := runShim(); got != want {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+		if got := runShim(); got != "" {
+			t.Fatalf("expected the warning to be throttled, got %q", got)
+		}
+	})
+}
@@ -17,6 +17,10 @@ func Uninstall(version string) error {
 		return fmt.Errorf("version argument is required. Usage: vc-env uninstall <version>")
 	}
 
+	if version == config.SystemVersion {
+		return fmt.Errorf("%q refers to the vcluster on PATH and cannot be uninstalled", version)
+	}
+
 	// Check if version is installed
 	installed, err := config.IsVersionInstalled(version)
 	if err != nil {
@@ -27,8 +27,13 @@ func Upgrade() error {
 	}
 
 	// 2. Fetch latest vc-env release from GitHub.
+	if offline, reason := offlineMode(); offline {
+		return fmt.Errorf("cannot upgrade vc-env: %s", reason)
+	}
 	client := github.NewClient()
+	client.BaseURL = github.DefaultBaseURL // mirrors only serve vcluster releases
 	latestVersion, err := client.GetLatestReleaseFor(vcenvRepo)
+	recordNetworkResult(err)
 	if err != nil {
 		return fmt.Errorf("failed to fetch latest vc-env release: %w", err)
 	}
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/config"
 	"github.com/user/vc-env/internal/github"
 )
 
@@ -25,6 +26,9 @@ import (
 //     unavailable and no disk cache exists, the hardcoded list is returned
 //     with a warning printed to stderr.  This ensures the command never
 //     returns an empty list due to a transient network failure.
+//
+// In offline mode (see offlineMode) layer 2 is skipped entirely: the stale
+// cache or the baseline is served without touching the network.
 func getRemoteVersions(client *github.Client) (stable []string, prerelease []string, err error) {
 	c := newCacheForRoot()
 
@@ -37,7 +41,17 @@ func getRemoteVersions(client *github.Client) (stable []string, prerelease []str
 	// Read the stale cache (ignoring TTL) so we can use it as the merge base
 	// and as the anchor for the delta fetch.  If no stale cache exists we fall
 	// back to the hardcoded baseline.
-	staleStable, stalePre, hasStale := loadStaleCache(c)
+	staleStable, stalePre, hasStale := c.LoadStale()
+
+	if offline, reason := offlineMode(); offline {
+		if !config.IsOffline() {
+			fmt.Fprintf(os.Stderr, "warning: %s; showing cached/baseline data\n", reason)
+		}
+		if hasStale {
+			return staleStable, stalePre, nil
+		}
+		return cache.BaselineVersions(), cache.BaselinePrereleaseVersions(), nil
+	}
 
 	stableAnchor := cache.BaselineNewest()
 	prereleaseAnchor := cache.BaselineNewest()
@@ -47,8 +61,15 @@ func getRemoteVersions(client *github.Client) (stable []string, prerelease []str
 	}
 
 	// Fetch only the releases newer than our anchor.
+	// The pre-release fetch is skipped when the first one already failed so
+	// that an unreachable network costs a single timeout, not two.
 	deltaStable, errStable := client.ListReleasesSince(stableAnchor, false)
-	deltaPre, errPre := client.ListReleasesSince(prereleaseAnchor, true)
+	var deltaPre []string
+	errPre := errStable
+	if errStable == nil {
+		deltaPre, errPre = client.ListReleasesSince(prereleaseAnchor, true)
+	}
+	recordNetworkResult(errPre)
 
 	if errStable != nil || errPre != nil {
 		// ── Layer 3: network unavailable — fall back to baseline / stale cache ──
@@ -93,12 +114,17 @@ func newCacheForRoot() *cache.Cache {
 	return cache.New(root + "/cache")
 }
 
-// loadStaleCache reads the cache file ignoring the TTL.  It returns the
-// version lists and true if the file exists and is parseable, regardless of
-// age.
-func loadStaleCache(c *cache.Cache) (versions []string, prereleaseVersions []string, ok bool) {
-	// We exploit the fact that Cache.Load respects the TTL, so we create a
-	// temporary cache instance with a very large TTL to bypass expiry.
-	staleReader := cache.NewWithTTL(c.Dir(), 1<<62) // effectively infinite TTL
-	return staleReader.Load()
+// newDownloadStoreForRoot creates the download store used by install.  It
+// lives at $VCENV_ROOT/cache/downloads unless VCENV_DOWNLOAD_CACHE_DIR points
+// elsewhere, which lets several VCENV_ROOTs on one host share downloads.
+// Without either, the store is disabled.
+func newDownloadStoreForRoot() *cache.DownloadStore {
+	if dir := os.Getenv("VCENV_DOWNLOAD_CACHE_DIR"); dir != "" {
+		return cache.NewDownloadStore(dir)
+	}
+	root := os.Getenv("VCENV_ROOT")
+	if root == "" {
+		return cache.NewDownloadStore("")
+	}
+	return cache.NewDownloadStore(root + "/cache/downloads")
 }
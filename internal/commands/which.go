@@ -2,8 +2,10 @@ package commands
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/user/vc-env/internal/config"
+	"github.com/user/vc-env/internal/github"
 )
 
 // Which prints the absolute path to the active vcluster binary.
@@ -25,3 +27,71 @@ func Which() error {
 	fmt.Println(binaryPath)
 	return nil
 }
+
+// WhichShim prints the path to the binary the vcluster shim should run.
+// Unlike Which, it fails when that binary is missing, with a message meant
+// for the user of the shim.  A missing version named by a global channel
+// configured with auto-install is installed first.
+func WhichShim() error {
+	return whichShimWithClient(github.NewClient())
+}
+
+func whichShimWithClient(client *github.Client) error {
+	if err := config.RequireInit(); err != nil {
+		return err
+	}
+
+	r, err := config.Resolve()
+	if err != nil {
+		return err
+	}
+	version := r.Version
+
+	if version == config.SystemVersion {
+		binaryPath, err := config.FindSystemBinary()
+		if err != nil {
+			return fmt.Errorf("version system is selected but no vcluster was found in PATH")
+		}
+		fmt.Println(binaryPath)
+		return nil
+	}
+
+	if err := checkPolicy(version); err != nil {
+		return err
+	}
+
+	installed, err := config.IsVersionInstalled(version)
+	if err != nil {
+		return err
+	}
+	if !installed && isAutoInstallChannel(r) {
+		// stdout is read by the shim, so progress goes to stderr.
+		fmt.Fprintf(os.Stderr, "vc-env: installing vcluster %s from channel %s\n", version, r.Origin)
+		if err := installWithClient(client, version, true); err != nil {
+			return err
+		}
+		installed = true
+	}
+	if !installed {
+		return fmt.Errorf("version %s is not installed\nInstall it with: vc-env install %s", version, version)
+	}
+	warnEOL(version)
+
+	binaryPath, err := config.GetBinaryPath(version)
+	if err != nil {
+		return err
+	}
+	fmt.Println(binaryPath)
+	return nil
+}
+
+// isAutoInstallChannel reports whether r was supplied by a global channel
+// configured with auto-install.
+func isAutoInstallChannel(r config.Resolution) bool {
+	if r.Provider != config.ProviderGlobal {
+		return false
+	}
+	root, _ := config.GetVCEnvRoot()
+	cfg, err := config.ReadChannel(root)
+	return err == nil && cfg.AutoInstall && cfg.URL == r.Origin
+}
@@ -91,3 +91,32 @@ func TestWhich(t *testing.T) {
 		}
 	})
 }
+
+func TestWhichShim(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("VCENV_ROOT", tmpDir)
+	t.Setenv("VCENV_RESOLVE_ORDER", "")
+	versionDir := filepath.Join(tmpDir, "versions", "0.31.0")
+	if err := os.MkdirAll(versionDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(versionDir, "vcluster"), []byte("binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("VCENV_VERSION", "0.31.0")
+	output := captureStdout(t, func() {
+		if err := WhichShim(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	if strings.TrimSpace(output) != filepath.Join(versionDir, "vcluster") {
+		t.Fatalf("unexpected output %q", output)
+	}
+
+	t.Setenv("VCENV_VERSION", "0.32.0")
+	err := WhichShim()
+	if err == nil || !strings.Contains(err.Error(), "version 0.32.0 is not installed") {
+		t.Fatalf("expected not-installed error, got %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// AliasesDirName is the directory under $VCENV_ROOT that holds one file per
+// alias, named after the alias and containing the version it stands for.
+const AliasesDirName = "aliases"
+
+// aliasNamePattern matches valid alias names.  Names start with a letter so
+// that they cannot be mistaken for versions.
+var aliasNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]*$`)
+
+// versionLikePattern matches names such as "v0.21" that look like versions.
+var versionLikePattern = regexp.MustCompile(`^v\d`)
+
+// Alias is a user-defined name for a version.
+type Alias struct {
+	Name    string
+	Version string
+}
+
+// ValidateAliasName returns an error if name cannot be used as an alias.
+func ValidateAliasName(name string) error {
+	if !aliasNamePattern.MatchString(name) || versionLikePattern.MatchString(name) {
+		return fmt.Errorf("invalid alias name %q: use letters, digits, '.', '_' and '-', starting with a letter other than a version prefix", name)
+	}
+	if name == SystemVersion {
+		return fmt.Errorf("%q is reserved and cannot be used as an alias", name)
+	}
+	return nil
+}
+
+// GetAliasesDir returns the path to $VCENV_ROOT/aliases.
+func GetAliasesDir() (string, error) {
+	root, ok := GetVCEnvRoot()
+	if !ok {
+		return "", fmt.Errorf("VCENV_ROOT is not set")
+	}
+	return filepath.Join(root, AliasesDirName), nil
+}
+
+// ReadAlias returns the version the alias name stands for.  The error
+// satisfies os.IsNotExist when there is no such alias.
+func ReadAlias(name string) (string, error) {
+	if err := ValidateAliasName(name); err != nil {
+		return "", err
+	}
+	dir, err := GetAliasesDir()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return "", err
+	}
+	version := strings.TrimSpace(string(data))
+	if version == "" {
+		return "", fmt.Errorf("alias %s is empty", name)
+	}
+	return version, nil
+}
+
+// ExpandAlias returns the version that version stands for when it is an
+// alias, and version itself otherwise.
+func ExpandAlias(version string) string {
+	if target, err := ReadAlias(version); err == nil {
+		return target
+	}
+	return version
+}
+
+// WriteAlias points the alias name at version, replacing any previous
+// target.  Aliases cannot point at other aliases.
+func WriteAlias(name, version string) error {
+	if err := ValidateAliasName(name); err != nil {
+		return err
+	}
+	if ExpandAlias(version) != version {
+		return fmt.Errorf("%s is an alias; aliases must point at a version", version)
+	}
+	dir, err := GetAliasesDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create aliases directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(version+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write alias %s: %w", name, err)
+	}
+	return nil
+}
+
+// RemoveAlias deletes the alias name.
+func RemoveAlias(name string) error {
+	if err := ValidateAliasName(name); err != nil {
+		return err
+	}
+	dir, err := GetAliasesDir()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dir, name)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("alias %s does not exist", name)
+		}
+		return fmt.Errorf("failed to remove alias %s: %w", name, err)
+	}
+	return nil
+}
+
+// ListAliases returns all aliases, sorted by name.  Files in the aliases
+// directory that are not valid aliases are ignored.
+func ListAliases() ([]Alias, error) {
+	dir, err := GetAliasesDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read aliases directory: %w", err)
+	}
+	var aliases []Alias
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		version, err := ReadAlias(entry.Name())
+		if err != nil {
+			continue
+		}
+		aliases = append(aliases, Alias{Name: entry.Name(), Version: version})
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Name < aliases[j].Name })
+	return aliases, nil
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestValidateAliasName(t *testing.T) {
+	for _, name := range []string{"prod", "team-a.staging", "Dev_2"} {
+		if err := ValidateAliasName(name); err != nil {
+			t.Errorf("ValidateAliasName(%q) = %v, want nil", name, err)
+		}
+	}
+	for _, name := range []string{"", "0.21.1", "v0.21", "system", "../prod", "a b", "-x"} {
+		if err := ValidateAliasName(name); err == nil {
+			t.Errorf("ValidateAliasName(%q) = nil, want an error", name)
+		}
+	}
+}
+
+func TestAliases(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("VCENV_ROOT", root)
+
+	if aliases, err := ListAliases(); err != nil || len(aliases) != 0 {
+		t.Fatalf("expected no aliases, got %v (%v)", aliases, err)
+	}
+	if err := WriteAlias("prod", "0.21.1"); err != nil {
+		t.Fatalf("WriteAlias: %v", err)
+	}
+	if err := WriteAlias("staging", "0.22.0"); err != nil {
+		t.Fatalf("WriteAlias: %v", err)
+	}
+	if err := WriteAlias("next", "staging"); err == nil {
+		t.Fatal("expected an alias pointing at an alias to be rejected")
+	}
+
+	if v := ExpandAlias("prod"); v != "0.21.1" {
+		t.Fatalf("ExpandAlias(prod) = %s", v)
+	}
+	if v := ExpandAlias("0.20.0"); v != "0.20.0" {
+		t.Fatalf("ExpandAlias(0.20.0) = %s", v)
+	}
+	want := []Alias{{Name: "prod", Version: "0.21.1"}, {Name: "staging", Version: "0.22.0"}}
+	if aliases, err := ListAliases(); err != nil || !reflect.DeepEqual(aliases, want) {
+		t.Fatalf("ListAliases() = %v (%v), want %v", aliases, err, want)
+	}
+
+	if err := RemoveAlias("prod"); err != nil {
+		t.Fatalf("RemoveAlias: %v", err)
+	}
+	if _, err := ReadAlias("prod"); !os.IsNotExist(err) {
+		t.Fatalf("expected prod to be gone, got %v", err)
+	}
+	if err := RemoveAlias("prod"); err == nil {
+		t.Fatal("expected an error removing a missing alias")
+	}
+}
+
+func TestResolveFrom_Alias(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("VCENV_ROOT", root)
+	t.Setenv("VCENV_VERSION", "")
+	t.Setenv("VCENV_RESOLVE_HELM", "")
+	t.Setenv("VCENV_RESOLVE_ORDER", "")
+	installFake(t, root, "0.21.1")
+	if err := WriteAlias("prod", "0.21.1"); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".vcluster-version"), []byte("staging\nprod\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := ResolveFrom(dir)
+	if err != nil || r.Version != "0.21.1" || r.Alias != "prod" {
+		t.Fatalf("expected 0.21.1 via prod, got %+v (%v)", r, err)
+	}
+
+	// Moving the alias moves every directory that uses it.
+	installFake(t, root, "0.22.0")
+	if err := WriteAlias("prod", "0.22.0"); err != nil {
+		t.Fatal(err)
+	}
+	if r, _ := ResolveFrom(dir); r.Version != "0.22.0" {
+		t.Fatalf("expected 0.22.0 after moving the alias, got %+v", r)
+	}
+
+	t.Setenv("VCENV_VERSION", "prod")
+	if r, _ := ResolveFrom(dir); r.Version != "0.22.0" || r.Provider != ProviderEnv || r.Alias != "prod" {
+		t.Fatalf("expected VCENV_VERSION to expand the alias, got %+v", r)
+	}
+}
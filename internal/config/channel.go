@@ -0,0 +1,61 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ChannelFileName is the file under $VCENV_ROOT that configures a release
+// channel as the global version (see package channel).
+const ChannelFileName = "channel.json"
+
+// ChannelConfig is the global release channel set by "vc-env global
+// --channel".
+type ChannelConfig struct {
+	// URL is the address of the channel file.
+	URL string `json:"url"`
+
+	// AutoInstall makes the shim install the version the channel names
+	// when it is missing.
+	AutoInstall bool `json:"auto_install,omitempty"`
+}
+
+// ReadChannel returns the channel configured under root.  The error
+// satisfies os.IsNotExist when no channel is configured.
+func ReadChannel(root string) (ChannelConfig, error) {
+	var cfg ChannelConfig
+	data, err := os.ReadFile(filepath.Join(root, ChannelFileName))
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid %s: %w", ChannelFileName, err)
+	}
+	if cfg.URL == "" {
+		return cfg, fmt.Errorf("invalid %s: no url", ChannelFileName)
+	}
+	return cfg, nil
+}
+
+// WriteChannel configures cfg as the global channel under root.
+func WriteChannel(root string, cfg ChannelConfig) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(root, ChannelFileName), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ChannelFileName, err)
+	}
+	return nil
+}
+
+// RemoveChannel removes the global channel configured under root, if any.
+func RemoveChannel(root string) error {
+	err := os.Remove(filepath.Join(root, ChannelFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", ChannelFileName, err)
+	}
+	return nil
+}
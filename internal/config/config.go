@@ -46,86 +46,94 @@ func RequireInit() error {
 	return nil
 }
 
-// ResolveVersion determines which vcluster version to use based on priority:
-//  1. VCENV_VERSION environment variable (shell version)
-//  2. .vcluster-version file in current or parent directories (local version)
-//  3. $VCENV_ROOT/version file (global version)
-//
-// Returns the version string and nil error, or empty string and error if no version is configured.
-func ResolveVersion() (string, error) {
-	// 1. Check VCENV_VERSION env var (shell version)
-	if v := os.Getenv("VCENV_VERSION"); v != "" {
-		return strings.TrimSpace(v), nil
-	}
+// IsOffline reports whether network access has been disabled explicitly via
+// the VCENV_OFFLINE environment variable (set by the --offline flag).
+func IsOffline() bool {
+	return envEnabled("VCENV_OFFLINE")
+}
 
-	// 2. Walk up directories looking for .vcluster-version (local version)
-	if v, err := findLocalVersion(); err == nil && v != "" {
-		return v, nil
-	}
+// IsHelmResolutionEnabled reports whether local version resolution also
+// reads the vcluster chart version from Chart.lock and Chart.yaml, as
+// enabled by the VCENV_RESOLVE_HELM environment variable.
+func IsHelmResolutionEnabled() bool {
+	return envEnabled("VCENV_RESOLVE_HELM")
+}
 
-	// 3. Check global version file
-	if v, err := readGlobalVersion(); err == nil && v != "" {
-		return v, nil
+// envEnabled reports whether the environment variable name is set to a
+// true value.
+func envEnabled(name string) bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(name))) {
+	case "1", "true", "yes", "on":
+		return true
 	}
-
-	return "", fmt.Errorf("no vcluster version configured. Set a version using 'vc-env shell', 'vc-env local', or 'vc-env global'")
+	return false
 }
 
-// findLocalVersion walks up from the current working directory looking for
-// a .vcluster-version file.
-func findLocalVersion() (string, error) {
-	dir, err := os.Getwd()
+// LocalVersionFileName is the file written by "vc-env local".
+const LocalVersionFileName = ".vcluster-version"
+
+// ReadGlobalVersion reads the global version from the given root directory.
+// When the file lists several versions, the first installed one is returned
+// (see SelectVersion).
+// Exported for testing.
+func ReadGlobalVersion(root string) (string, error) {
+	versions, err := ReadGlobalVersions(root)
 	if err != nil {
 		return "", err
 	}
-	return FindLocalVersionFrom(dir)
+	return SelectVersion(versions), nil
 }
 
-// FindLocalVersionFrom walks up from the given directory looking for
-// a .vcluster-version file. Exported for testing.
-func FindLocalVersionFrom(dir string) (string, error) {
-	for {
-		versionFile := filepath.Join(dir, ".vcluster-version")
-		data, err := os.ReadFile(versionFile)
-		if err == nil {
-			v := strings.TrimSpace(string(data))
-			if v != "" {
-				return v, nil
-			}
-		}
-
-		parent := filepath.Dir(dir)
-		if parent == dir {
-			// Reached filesystem root
-			break
-		}
-		dir = parent
+// ReadGlobalVersions reads all versions listed in $VCENV_ROOT/version, in
+// order.
+func ReadGlobalVersions(root string) ([]string, error) {
+	versionFile := filepath.Join(root, "version")
+	data, err := os.ReadFile(versionFile)
+	if err != nil {
+		return nil, err
+	}
+	versions := ParseVersionFile(data)
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("global version file is empty")
 	}
-	return "", fmt.Errorf("no .vcluster-version file found")
+	return versions, nil
 }
 
-// readGlobalVersion reads the global version from $VCENV_ROOT/version.
-func readGlobalVersion() (string, error) {
-	root, ok := GetVCEnvRoot()
-	if !ok {
-		return "", fmt.Errorf("VCENV_ROOT not set")
+// ParseVersionFile returns the versions listed in the content of a
+// .vcluster-version or global version file, in order of preference.  Each
+// line holds a version; blank lines and "#" comments are ignored.
+func ParseVersionFile(data []byte) []string {
+	var versions []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		versions = append(versions, strings.Fields(line)...)
 	}
-	return ReadGlobalVersion(root)
+	return versions
 }
 
-// ReadGlobalVersion reads the global version from the given root directory.
-// Exported for testing.
-func ReadGlobalVersion(root string) (string, error) {
-	versionFile := filepath.Join(root, "version")
-	data, err := os.ReadFile(versionFile)
-	if err != nil {
-		return "", err
+// SelectVersion returns the first of versions that is installed, so that a
+// version file can list fallbacks.  When none is installed the first version
+// is returned, which is the one to install.  Aliases are expanded.
+func SelectVersion(versions []string) string {
+	if len(versions) == 0 {
+		return ""
 	}
-	v := strings.TrimSpace(string(data))
-	if v == "" {
-		return "", fmt.Errorf("global version file is empty")
+	i := selectCandidate(versions)
+	if i < 0 {
+		i = 0
+	}
+	return ExpandAlias(versions[i])
+}
+
+// selectCandidate returns the index of the first of versions that is
+// installed, after expanding aliases, or -1 if none is.
+func selectCandidate(versions []string) int {
+	for i, v := range versions {
+		if installed, err := IsVersionInstalled(ExpandAlias(v)); err == nil && installed {
+			return i
+		}
 	}
-	return v, nil
+	return -1
 }
 
 // GetVersionDir returns the path to the directory for a specific version.
@@ -138,7 +146,11 @@ func GetVersionDir(version string) (string, error) {
 }
 
 // GetBinaryPath returns the path to the vcluster binary for a specific version.
+// For SystemVersion it returns the vcluster found on PATH.
 func GetBinaryPath(version string) (string, error) {
+	if version == SystemVersion {
+		return FindSystemBinary()
+	}
 	versionDir, err := GetVersionDir(version)
 	if err != nil {
 		return "", err
@@ -146,8 +158,13 @@ func GetBinaryPath(version string) (string, error) {
 	return filepath.Join(versionDir, "vcluster"), nil
 }
 
-// IsVersionInstalled checks if a specific version is installed.
+// IsVersionInstalled checks if a specific version is installed.  The system
+// version counts as installed when a vcluster binary is found on PATH.
 func IsVersionInstalled(version string) (bool, error) {
+	if version == SystemVersion {
+		_, err := FindSystemBinary()
+		return err == nil, nil
+	}
 	binaryPath, err := GetBinaryPath(version)
 	if err != nil {
 		return false, err
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -199,6 +200,134 @@ func TestFindLocalVersionFrom(t *testing.T) {
 		}
 	})
 
+	t.Run("reads vcluster from .tool-versions", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		childDir := filepath.Join(tmpDir, "subdir")
+		if err := os.MkdirAll(childDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, ".tool-versions"), []byte("golang 1.24.4\nvcluster 0.21.1\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		// A .tool-versions without a vcluster line is skipped.
+		if err := os.WriteFile(filepath.Join(childDir, ".tool-versions"), []byte("kubectl 1.30.0\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		v, file, err := FindLocalVersionFileFrom(childDir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if v != "0.21.1" || file != filepath.Join(tmpDir, ".tool-versions") {
+			t.Fatalf("expected 0.21.1 from the parent .tool-versions, got %s from %s", v, file)
+		}
+	})
+
+	t.Run("prefers .vcluster-version in the same directory", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(tmpDir, ".tool-versions"), []byte("vcluster 0.21.1\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, ".vcluster-version"), []byte("0.22.0\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		v, err := FindLocalVersionFrom(tmpDir)
+		if err != nil || v != "0.22.0" {
+			t.Fatalf("expected 0.22.0, got %s (%v)", v, err)
+		}
+	})
+
+	t.Run("nearer .tool-versions wins over parent .vcluster-version", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		childDir := filepath.Join(tmpDir, "subdir")
+		if err := os.MkdirAll(childDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, ".vcluster-version"), []byte("0.22.0\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(childDir, ".tool-versions"), []byte("vcluster 0.21.1\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		v, err := FindLocalVersionFrom(childDir)
+		if err != nil || v != "0.21.1" {
+			t.Fatalf("expected 0.21.1, got %s (%v)", v, err)
+		}
+	})
+
+	t.Run("reads the vcluster chart version when enabled", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		childDir := filepath.Join(tmpDir, "templates")
+		if err := os.MkdirAll(childDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		chart := "apiVersion: v2\nname: platform\ndependencies:\n  - name: vcluster\n    version: 0.21.1\n    repository: https://charts.loft.sh\n"
+		if err := os.WriteFile(filepath.Join(tmpDir, "Chart.yaml"), []byte(chart), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		t.Setenv("VCENV_RESOLVE_HELM", "")
+		if _, err := FindLocalVersionFrom(childDir); err == nil {
+			t.Fatal("expected Chart.yaml to be ignored unless VCENV_RESOLVE_HELM is set")
+		}
+
+		t.Setenv("VCENV_RESOLVE_HELM", "1")
+		v, file, err := FindLocalVersionFileFrom(childDir)
+		if err != nil || v != "0.21.1" || file != filepath.Join(tmpDir, "Chart.yaml") {
+			t.Fatalf("expected 0.21.1 from Chart.yaml, got %s from %s (%v)", v, file, err)
+		}
+
+		// Version files in the same directory take precedence.
+		if err := os.WriteFile(filepath.Join(tmpDir, ".tool-versions"), []byte("vcluster 0.20.0\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if v, err := FindLocalVersionFrom(childDir); err != nil || v != "0.20.0" {
+			t.Fatalf("expected 0.20.0 from .tool-versions, got %s (%v)", v, err)
+		}
+	})
+
+	t.Run("uses the first installed of several versions", func(t *testing.T) {
+		root := t.TempDir()
+		t.Setenv("VCENV_ROOT", root)
+		installFake(t, root, "0.21.3")
+		tmpDir := t.TempDir()
+		content := "# prefer 0.22.0 once everyone upgraded\n0.22.0\n\n0.21.3  # fallback\n"
+		if err := os.WriteFile(filepath.Join(tmpDir, ".vcluster-version"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		if v, err := FindLocalVersionFrom(tmpDir); err != nil || v != "0.21.3" {
+			t.Fatalf("expected the installed fallback 0.21.3, got %s (%v)", v, err)
+		}
+		installFake(t, root, "0.22.0")
+		if v, err := FindLocalVersionFrom(tmpDir); err != nil || v != "0.22.0" {
+			t.Fatalf("expected 0.22.0 once installed, got %s (%v)", v, err)
+		}
+	})
+
+	t.Run("uses the first version when none is installed", func(t *testing.T) {
+		t.Setenv("VCENV_ROOT", t.TempDir())
+		tmpDir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(tmpDir, ".vcluster-version"), []byte("0.22.0\n0.21.3\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if v, err := FindLocalVersionFrom(tmpDir); err != nil || v != "0.22.0" {
+			t.Fatalf("expected 0.22.0, got %s (%v)", v, err)
+		}
+	})
+
+	t.Run("ignores a file with only comments", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(tmpDir, ".vcluster-version"), []byte("# no version yet\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if v, err := FindLocalVersionFrom(tmpDir); err == nil {
+			t.Fatalf("expected no version, got %s", v)
+		}
+	})
+
 	t.Run("returns error when no version file found", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		_, err := FindLocalVersionFrom(tmpDir)
@@ -224,6 +353,24 @@ func TestReadGlobalVersion(t *testing.T) {
 		}
 	})
 
+	t.Run("skips comments and versions that are not installed", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("VCENV_ROOT", tmpDir)
+		installFake(t, tmpDir, "0.21.3")
+		if err := os.WriteFile(filepath.Join(tmpDir, "version"), []byte("# default\n0.22.0\n0.21.3\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		v, err := ReadGlobalVersion(tmpDir)
+		if err != nil || v != "0.21.3" {
+			t.Fatalf("expected 0.21.3, got %s (%v)", v, err)
+		}
+		versions, err := ReadGlobalVersions(tmpDir)
+		if err != nil || !reflect.DeepEqual(versions, []string{"0.22.0", "0.21.3"}) {
+			t.Fatalf("unexpected versions %v (%v)", versions, err)
+		}
+	})
+
 	t.Run("returns error when file missing", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		_, err := ReadGlobalVersion(tmpDir)
@@ -315,3 +462,58 @@ func TestIsVersionInstalled(t *testing.T) {
 		}
 	})
 }
+
+func TestIsOffline(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{"", false},
+		{"0", false},
+		{"false", false},
+		{"1", true},
+		{"true", true},
+		{"YES", true},
+	}
+
+	for _, tt := range tests {
+		t.Run("value_"+tt.value, func(t *testing.T) {
+			t.Setenv("VCENV_OFFLINE", tt.value)
+			if got := IsOffline(); got != tt.expected {
+				t.Fatalf("IsOffline() with %q = %v, want %v", tt.value, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseVersionFile(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{"single version", "0.22.0\n", []string{"0.22.0"}},
+		{"no trailing newline", "0.22.0", []string{"0.22.0"}},
+		{"fallbacks and comments", "# migration window\n0.22.0\n\n0.21.3 # until Q3\r\n", []string{"0.22.0", "0.21.3"}},
+		{"only comments", "# nothing\n\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseVersionFile([]byte(tt.data)); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseVersionFile(%q) = %v, want %v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+// installFake creates a placeholder binary for version under root.
+func installFake(t *testing.T, root, version string) {
+	t.Helper()
+	dir := filepath.Join(root, "versions", version)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "vcluster"), []byte("binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// VersionMetaFileName is the name of the metadata file kept inside a version
+// directory for versions that were not installed from a vcluster release.
+const VersionMetaFileName = ".vcenv-meta.json"
+
+// Version sources recorded in VersionMeta.
+const (
+	SourceFile  = "file"
+	SourceURL   = "url"
+	SourceBuild = "source"
+)
+
+// VersionMeta describes where an installed version came from.
+type VersionMeta struct {
+	// Source is one of SourceFile, SourceURL or SourceBuild.
+	Source string `json:"source"`
+	// Origin is the file path, URL or source checkout the binary came from.
+	Origin string `json:"origin"`
+	// SHA256 is the hex digest of the installed binary.
+	SHA256 string `json:"sha256"`
+	// ReportedVersion is the version printed by "vcluster version".
+	ReportedVersion string `json:"reported_version,omitempty"`
+	// Revision is the source revision of a binary built from a checkout.
+	Revision    string    `json:"revision,omitempty"`
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// versionNamePattern restricts custom version names to a single safe path
+// element.
+var versionNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]*$`)
+
+// ValidateVersionName checks that name can be used as a version directory.
+func ValidateVersionName(name string) error {
+	if !versionNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid version name %q: use letters, digits, '.', '_', '+' and '-'", name)
+	}
+	if name == SystemVersion {
+		return fmt.Errorf("%q is a reserved version name", name)
+	}
+	return nil
+}
+
+// ReadVersionMeta reads the metadata of an installed version from its
+// directory.  It returns false when the version has no metadata, which is
+// the case for versions installed from vcluster releases.
+func ReadVersionMeta(versionDir string) (VersionMeta, bool, error) {
+	data, err := os.ReadFile(filepath.Join(versionDir, VersionMetaFileName))
+	if os.IsNotExist(err) {
+		return VersionMeta{}, false, nil
+	}
+	if err != nil {
+		return VersionMeta{}, false, err
+	}
+	var meta VersionMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return VersionMeta{}, false, fmt.Errorf("invalid version metadata: %w", err)
+	}
+	return meta, true, nil
+}
+
+// WriteVersionMeta writes the metadata of a version into versionDir.
+func WriteVersionMeta(versionDir string, meta VersionMeta) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(versionDir, VersionMetaFileName), append(data, '\n'), 0o644)
+}
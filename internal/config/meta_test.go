@@ -0,0 +1,42 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateVersionName(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"0.23.0-custom": true,
+		"dev-1a2b3c4":   true,
+		"v0.21.1+patch": true,
+		"":              false,
+		"..":            false,
+		"a/b":           false,
+		"-rf":           false,
+		"name with sp":  false,
+	} {
+		if err := ValidateVersionName(name); (err == nil) != valid {
+			t.Errorf("ValidateVersionName(%q) = %v, want valid=%v", name, err, valid)
+		}
+	}
+}
+
+func TestVersionMetaRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	if _, ok, err := ReadVersionMeta(dir); ok || err != nil {
+		t.Fatalf("expected no metadata, got ok=%v err=%v", ok, err)
+	}
+
+	want := VersionMeta{Source: SourceURL, Origin: "https://example.com/vcluster", SHA256: "abc", InstalledAt: time.Now().UTC().Truncate(time.Second)}
+	if err := WriteVersionMeta(dir, want); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := ReadVersionMeta(dir)
+	if err != nil || !ok {
+		t.Fatalf("ReadVersionMeta: ok=%v err=%v", ok, err)
+	}
+	if got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,520 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/channel"
+	"github.com/user/vc-env/internal/helm"
+)
+
+// Names of the version providers, as listed in VCENV_RESOLVE_ORDER.
+const (
+	ProviderEnv          = "env"
+	ProviderVersionFile  = "version-file"
+	ProviderToolVersions = "tool-versions"
+	ProviderHelm         = "helm"
+	ProviderKubeContext  = "kube-context"
+	ProviderGlobal       = "global"
+	ProviderRemote       = "remote-default"
+)
+
+// KubeContextsFileName is the file under $VCENV_ROOT that maps kube contexts
+// to versions for the kube-context provider.
+const KubeContextsFileName = "kube-contexts"
+
+// Resolution is the outcome of version resolution.
+type Resolution struct {
+	Version string
+	// Provider is the name of the provider that supplied Version.
+	Provider string
+	// Origin is the file the version was read from, if any.
+	Origin string
+	// Description says where the version came from, e.g. ".tool-versions file".
+	Description string
+	// Candidates lists every version the source named, in order of
+	// preference; Version is the first installed one among them.
+	Candidates []string
+	// Alias is the alias through which Version was named, if any.
+	Alias string
+}
+
+// provider supplies a version from one source.  Providers that read files
+// from the directory tree implement inDir; all others implement lookup.
+// Both record what they looked at in the explainer, which may be nil.
+type provider struct {
+	lookup func(e *explainer) (Resolution, bool)
+	inDir  func(dir string, e *explainer) (Resolution, bool)
+}
+
+// explainer collects the steps of a resolution for "vc-env resolve
+// --explain".  A nil explainer records nothing.
+type explainer struct {
+	steps []string
+}
+
+func (e *explainer) printf(format string, args ...any) {
+	if e != nil {
+		e.steps = append(e.steps, fmt.Sprintf(format, args...))
+	}
+}
+
+var providers = map[string]provider{
+	ProviderEnv:          {lookup: lookupEnv},
+	ProviderVersionFile:  {inDir: versionFileInDir},
+	ProviderToolVersions: {inDir: toolVersionsInDir},
+	ProviderHelm:         {inDir: helmInDir},
+	ProviderKubeContext:  {lookup: lookupKubeContext},
+	ProviderGlobal:       {lookup: lookupGlobal},
+	ProviderRemote:       {lookup: lookupRemoteDefault},
+}
+
+// ResolveOrder returns the providers consulted by Resolve, in order.  The
+// order is read from the comma-separated VCENV_RESOLVE_ORDER; by default it
+// is env, version-file, tool-versions, helm (only with VCENV_RESOLVE_HELM)
+// and global.
+func ResolveOrder() ([]string, error) {
+	raw := strings.TrimSpace(os.Getenv("VCENV_RESOLVE_ORDER"))
+	if raw == "" {
+		order := []string{ProviderEnv, ProviderVersionFile, ProviderToolVersions}
+		if IsHelmResolutionEnabled() {
+			order = append(order, ProviderHelm)
+		}
+		return append(order, ProviderGlobal), nil
+	}
+
+	var order []string
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := providers[name]; !ok {
+			return nil, fmt.Errorf("invalid VCENV_RESOLVE_ORDER: unknown provider %q (valid: %s)", name, strings.Join(ProviderNames(), ", "))
+		}
+		order = append(order, name)
+	}
+	return order, nil
+}
+
+// ProviderNames returns the names of all version providers.
+func ProviderNames() []string {
+	return []string{ProviderEnv, ProviderVersionFile, ProviderToolVersions, ProviderHelm, ProviderKubeContext, ProviderGlobal, ProviderRemote}
+}
+
+// Resolve determines the active vcluster version for the current directory.
+func Resolve() (Resolution, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return Resolution{}, err
+	}
+	return ResolveFrom(dir)
+}
+
+// ResolveFrom determines the active vcluster version for dir by asking the
+// providers of ResolveOrder in turn.  Consecutive file providers are
+// evaluated together while walking up from dir, so that the nearest
+// directory with any of their files wins; within one directory their order
+// applies.
+func ResolveFrom(dir string) (Resolution, error) {
+	return resolveFrom(dir, nil)
+}
+
+// ResolveExplained is ResolveFrom, but also returns a description of every
+// step taken: the settings in effect, each file that was checked and why it
+// was skipped, and which source won.
+func ResolveExplained(dir string) (Resolution, []string, error) {
+	e := &explainer{}
+	r, err := resolveFrom(dir, e)
+	return r, e.steps, err
+}
+
+func resolveFrom(dir string, e *explainer) (Resolution, error) {
+	if raw := os.Getenv("VCENV_RESOLVE_ORDER"); raw != "" {
+		e.printf("VCENV_RESOLVE_ORDER=%s", raw)
+	} else {
+		e.printf("VCENV_RESOLVE_ORDER is not set; using the default order")
+	}
+	order, err := ResolveOrder()
+	if err != nil {
+		return Resolution{}, err
+	}
+	e.printf("order: %s", strings.Join(order, ", "))
+	if ceilings := CeilingDirectories(); len(ceilings) > 0 {
+		e.printf("VCENV_CEILING_DIRECTORIES: %s", strings.Join(ceilings, ", "))
+	}
+
+	for i := 0; i < len(order); i++ {
+		p := providers[order[i]]
+		if p.lookup != nil {
+			e.printf("[%s]", order[i])
+			if r, ok := p.lookup(e); ok {
+				e.printf("resolved %s from %s", r.Version, r.Description)
+				return r, nil
+			}
+			continue
+		}
+
+		var names []string
+		var walk []provider
+		for ; i < len(order) && providers[order[i]].inDir != nil; i++ {
+			names = append(names, order[i])
+			walk = append(walk, providers[order[i]])
+		}
+		i--
+		e.printf("[%s] walking up from %s", strings.Join(names, ", "), dir)
+		if r, ok := walkUp(dir, walk, e); ok {
+			e.printf("resolved %s from %s", r.Version, r.Origin)
+			return r, nil
+		}
+	}
+
+	e.printf("no provider supplied a version")
+	return Resolution{}, fmt.Errorf("no vcluster version configured. Set a version using 'vc-env shell', 'vc-env local', or 'vc-env global'")
+}
+
+// ResolveVersion determines which vcluster version to use; see ResolveFrom.
+// With the default order the priority is:
+//  1. VCENV_VERSION environment variable (shell version)
+//  2. .vcluster-version or .tool-versions file in current or parent directories (local version)
+//  3. $VCENV_ROOT/version file (global version)
+//
+// Returns the version string and nil error, or empty string and error if no version is configured.
+func ResolveVersion() (string, error) {
+	r, err := Resolve()
+	if err != nil {
+		return "", err
+	}
+	return r.Version, nil
+}
+
+// FindLocalVersionFrom walks up from the given directory looking for
+// a local version file. Exported for testing.
+func FindLocalVersionFrom(dir string) (string, error) {
+	v, _, err := FindLocalVersionFileFrom(dir)
+	return v, err
+}
+
+// FindLocalVersionFileFrom walks up from the given directory and returns the
+// version and path of the nearest local version file.  Within a directory a
+// non-empty .vcluster-version takes precedence over a vcluster line in
+// .tool-versions, which takes precedence over the vcluster chart pinned in
+// Chart.lock or Chart.yaml (see IsHelmResolutionEnabled); a file in a
+// nearer directory wins over any file in a parent.
+func FindLocalVersionFileFrom(dir string) (string, string, error) {
+	walk := []provider{providers[ProviderVersionFile], providers[ProviderToolVersions]}
+	if IsHelmResolutionEnabled() {
+		walk = append(walk, providers[ProviderHelm])
+	}
+	if r, ok := walkUp(dir, walk, nil); ok {
+		return r.Version, r.Origin, nil
+	}
+	return "", "", fmt.Errorf("no .vcluster-version or .tool-versions file found")
+}
+
+// walkUp asks the file providers in each directory from dir upwards and
+// returns the first version found.  The walk ends at the filesystem root or
+// below a directory listed in VCENV_CEILING_DIRECTORIES.
+func walkUp(dir string, walk []provider, e *explainer) (Resolution, bool) {
+	ceilings := CeilingDirectories()
+	for {
+		for _, p := range walk {
+			if r, ok := p.inDir(dir, e); ok {
+				return r, true
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			// Reached filesystem root
+			return Resolution{}, false
+		}
+		for _, c := range ceilings {
+			if parent == c {
+				e.printf("  stopped below ceiling directory %s", c)
+				return Resolution{}, false
+			}
+		}
+		dir = parent
+	}
+}
+
+// CeilingDirectories returns the absolute paths listed in
+// VCENV_CEILING_DIRECTORIES.  As with git's GIT_CEILING_DIRECTORIES, the
+// upward search for version files never enters these directories, although
+// a search starting in one of them still checks it.  Relative paths are
+// ignored.
+func CeilingDirectories() []string {
+	var dirs []string
+	for _, d := range filepath.SplitList(os.Getenv("VCENV_CEILING_DIRECTORIES")) {
+		if filepath.IsAbs(d) {
+			dirs = append(dirs, filepath.Clean(d))
+		}
+	}
+	return dirs
+}
+
+// readVersionFile reads a version file for a file provider, recording why
+// it was skipped when it is missing or unreadable.
+func readVersionFile(path string, e *explainer) ([]byte, bool) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		e.printf("  %s: not found", path)
+		return nil, false
+	}
+	if err != nil {
+		e.printf("  %s: unreadable (%v), skipped", path, err)
+		return nil, false
+	}
+	return data, true
+}
+
+func lookupEnv(e *explainer) (Resolution, bool) {
+	v := strings.TrimSpace(os.Getenv("VCENV_VERSION"))
+	if v == "" {
+		e.printf("  VCENV_VERSION is not set")
+		return Resolution{}, false
+	}
+	e.printf("  VCENV_VERSION=%s", describeCandidate(v))
+	return Resolution{Version: ExpandAlias(v), Provider: ProviderEnv, Description: "VCENV_VERSION environment variable", Alias: aliasOf(v)}, true
+}
+
+func versionFileInDir(dir string, e *explainer) (Resolution, bool) {
+	path := filepath.Join(dir, LocalVersionFileName)
+	data, ok := readVersionFile(path, e)
+	if !ok {
+		return Resolution{}, false
+	}
+	versions := ParseVersionFile(data)
+	if len(versions) == 0 {
+		e.printf("  %s: empty, skipped", path)
+		return Resolution{}, false
+	}
+	return fileResolution(versions, ProviderVersionFile, path, e), true
+}
+
+func toolVersionsInDir(dir string, e *explainer) (Resolution, bool) {
+	path := filepath.Join(dir, ToolVersionsFileName)
+	data, ok := readVersionFile(path, e)
+	if !ok {
+		return Resolution{}, false
+	}
+	versions := ToolVersions(data)
+	if len(versions) == 0 {
+		e.printf("  %s: no vcluster line, skipped", path)
+		return Resolution{}, false
+	}
+	return fileResolution(versions, ProviderToolVersions, path, e), true
+}
+
+func helmInDir(dir string, e *explainer) (Resolution, bool) {
+	v, path, ok := helm.FindVClusterVersion(dir)
+	if !ok {
+		for _, name := range []string{helm.LockFileName, helm.ChartFileName} {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				e.printf("  %s: no pinned loft-sh vcluster dependency, skipped", filepath.Join(dir, name))
+			}
+		}
+		return Resolution{}, false
+	}
+	return fileResolution([]string{v}, ProviderHelm, path, e), true
+}
+
+// fileResolution selects among the versions listed in the file at path.
+func fileResolution(versions []string, provider, path string, e *explainer) Resolution {
+	v, alias := selectExplained(path, versions, e)
+	return Resolution{Version: v, Provider: provider, Origin: path, Description: filepath.Base(path) + " file", Candidates: versions, Alias: alias}
+}
+
+// selectExplained is SelectVersion, recording the versions listed in source
+// and which were passed over because they are not installed.  It also
+// returns the alias the selected version was listed as, if any.
+func selectExplained(source string, versions []string, e *explainer) (string, string) {
+	i := selectCandidate(versions)
+	var listed []string
+	for _, c := range versions {
+		listed = append(listed, describeCandidate(c))
+	}
+	switch {
+	case len(versions) == 1:
+		e.printf("  %s: %s", source, listed[0])
+	case i < 0:
+		e.printf("  %s: %s; none installed, using %s", source, strings.Join(listed, ", "), versions[0])
+	default:
+		for j := 0; j < i; j++ {
+			listed[j] += " (not installed)"
+		}
+		e.printf("  %s: %s", source, strings.Join(listed[:i+1], ", "))
+	}
+	if i < 0 {
+		i = 0
+	}
+	return ExpandAlias(versions[i]), aliasOf(versions[i])
+}
+
+// describeCandidate returns v, followed by its target when it is an alias.
+func describeCandidate(v string) string {
+	if target := ExpandAlias(v); target != v {
+		return fmt.Sprintf("%s (alias for %s)", v, target)
+	}
+	return v
+}
+
+// aliasOf returns v when it is an alias, and an empty string otherwise.
+func aliasOf(v string) string {
+	if ExpandAlias(v) != v {
+		return v
+	}
+	return ""
+}
+
+func lookupGlobal(e *explainer) (Resolution, bool) {
+	root, ok := GetVCEnvRoot()
+	if !ok {
+		e.printf("  VCENV_ROOT is not set")
+		return Resolution{}, false
+	}
+	path := filepath.Join(root, "version")
+	versions, err := ReadGlobalVersions(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			e.printf("  %s: not found", path)
+			return lookupChannel(root, e)
+		}
+		e.printf("  %s: %v, skipped", path, err)
+		return Resolution{}, false
+	}
+	v, alias := selectExplained(path, versions, e)
+	return Resolution{Version: v, Provider: ProviderGlobal, Origin: path, Description: "global version file", Candidates: versions, Alias: alias}, true
+}
+
+// lookupChannel returns the version named by the global release channel,
+// which is used when no global version file exists.
+func lookupChannel(root string, e *explainer) (Resolution, bool) {
+	path := filepath.Join(root, ChannelFileName)
+	cfg, err := ReadChannel(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			e.printf("  %s: not found", path)
+		} else {
+			e.printf("  %s: %v, skipped", path, err)
+		}
+		return Resolution{}, false
+	}
+	v, err := ChannelVersion(root, cfg)
+	if err != nil {
+		e.printf("  channel %s: %v, skipped", cfg.URL, err)
+		return Resolution{}, false
+	}
+	e.printf("  channel %s: %s", cfg.URL, v)
+	return Resolution{Version: v, Provider: ProviderGlobal, Origin: cfg.URL, Description: "global channel " + cfg.URL}, true
+}
+
+// ChannelVersion returns the version named by the channel cfg, using the
+// channel cache under root.  Offline, or while automatic offline mode is in
+// effect, only the cached version is used.
+func ChannelVersion(root string, cfg ChannelConfig) (string, error) {
+	cacheDir := filepath.Join(root, "cache")
+	_, backoff := cache.NewNetworkState(cacheDir).OfflineUntil()
+	return channel.New(cfg.URL, cacheDir).Version(IsOffline() || backoff)
+}
+
+// lookupKubeContext maps the current kube context to a version through
+// $VCENV_ROOT/kube-contexts.  Each line of that file holds a context name,
+// in which "*" matches any text, and a version; the first match wins.
+func lookupKubeContext(e *explainer) (Resolution, bool) {
+	root, ok := GetVCEnvRoot()
+	if !ok {
+		e.printf("  VCENV_ROOT is not set")
+		return Resolution{}, false
+	}
+	context := CurrentKubeContext()
+	if context == "" {
+		e.printf("  no current kube context")
+		return Resolution{}, false
+	}
+	e.printf("  current kube context: %s", context)
+	path := filepath.Join(root, KubeContextsFileName)
+	data, ok := readVersionFile(path, e)
+	if !ok {
+		return Resolution{}, false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !matchPattern(fields[0], context) {
+			continue
+		}
+		e.printf("  %s: %s matches, %s", path, fields[0], describeCandidate(fields[1]))
+		return Resolution{
+			Version:     ExpandAlias(fields[1]),
+			Provider:    ProviderKubeContext,
+			Origin:      path,
+			Description: fmt.Sprintf("kube context %s", context),
+			Alias:       aliasOf(fields[1]),
+		}, true
+	}
+	e.printf("  %s: no entry matches, skipped", path)
+	return Resolution{}, false
+}
+
+// CurrentKubeContext returns the current-context of the kubeconfig, read
+// from the files in KUBECONFIG or ~/.kube/config.  As with kubectl, the
+// first file that sets it wins.
+func CurrentKubeContext() string {
+	files := filepath.SplitList(os.Getenv("KUBECONFIG"))
+	if len(files) == 0 {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		files = []string{filepath.Join(home, ".kube", "config")}
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if v, ok := strings.CutPrefix(line, "current-context:"); ok {
+				if v = strings.Trim(strings.TrimSpace(v), `"'`); v != "" {
+					return v
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// matchPattern reports whether name matches pattern, in which "*" matches
+// any text, including "/" and ":" as found in cloud provider context names.
+func matchPattern(pattern, name string) bool {
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	ok, _ := regexp.MatchString(expr, name)
+	return ok
+}
+
+// lookupRemoteDefault returns the newest stable release known to the release
+// cache, regardless of its age, or the built-in baseline.  It never accesses
+// the network.
+func lookupRemoteDefault(e *explainer) (Resolution, bool) {
+	v := ""
+	if root, ok := GetVCEnvRoot(); ok {
+		if stable, _, ok := cache.New(filepath.Join(root, "cache")).LoadStale(); ok {
+			v = cache.NewestVersion(stable)
+		}
+	}
+	if v == "" {
+		v = cache.BaselineNewest()
+	}
+	if v == "" {
+		e.printf("  no known releases")
+		return Resolution{}, false
+	}
+	e.printf("  newest known release: %s", v)
+	return Resolution{Version: v, Provider: ProviderRemote, Description: "newest known release"}, true
+}
@@ -0,0 +1,228 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveOrder(t *testing.T) {
+	t.Setenv("VCENV_RESOLVE_HELM", "")
+	t.Setenv("VCENV_RESOLVE_ORDER", "")
+	order, err := ResolveOrder()
+	want := []string{ProviderEnv, ProviderVersionFile, ProviderToolVersions, ProviderGlobal}
+	if err != nil || !reflect.DeepEqual(order, want) {
+		t.Fatalf("default order = %v, %v; want %v", order, err, want)
+	}
+
+	t.Setenv("VCENV_RESOLVE_HELM", "1")
+	order, _ = ResolveOrder()
+	want = []string{ProviderEnv, ProviderVersionFile, ProviderToolVersions, ProviderHelm, ProviderGlobal}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("order with VCENV_RESOLVE_HELM = %v; want %v", order, want)
+	}
+
+	t.Setenv("VCENV_RESOLVE_ORDER", " version-file, kube-context ,,global")
+	order, _ = ResolveOrder()
+	want = []string{ProviderVersionFile, ProviderKubeContext, ProviderGlobal}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("configured order = %v; want %v", order, want)
+	}
+
+	t.Setenv("VCENV_RESOLVE_ORDER", "env,shell")
+	if _, err := ResolveOrder(); err == nil || !strings.Contains(err.Error(), `unknown provider "shell"`) {
+		t.Fatalf("expected an unknown provider error, got %v", err)
+	}
+}
+
+func TestResolveFrom(t *testing.T) {
+	setup := func(t *testing.T) (string, string) {
+		t.Helper()
+		root := t.TempDir()
+		t.Setenv("VCENV_ROOT", root)
+		t.Setenv("VCENV_VERSION", "")
+		t.Setenv("VCENV_RESOLVE_HELM", "")
+		t.Setenv("VCENV_RESOLVE_ORDER", "")
+		t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+		if err := os.WriteFile(filepath.Join(root, "version"), []byte("0.19.0\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return root, t.TempDir()
+	}
+
+	t.Run("reports the provider and origin", func(t *testing.T) {
+		_, dir := setup(t)
+		if err := os.WriteFile(filepath.Join(dir, ".tool-versions"), []byte("vcluster 0.21.1\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		r, err := ResolveFrom(dir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := Resolution{Version: "0.21.1", Provider: ProviderToolVersions, Origin: filepath.Join(dir, ".tool-versions"), Description: ".tool-versions file", Candidates: []string{"0.21.1"}}
+		if !reflect.DeepEqual(r, want) {
+			t.Fatalf("got %+v, want %+v", r, want)
+		}
+	})
+
+	t.Run("separated file providers walk separately", func(t *testing.T) {
+		_, dir := setup(t)
+		child := filepath.Join(dir, "child")
+		if err := os.MkdirAll(child, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, ".vcluster-version"), []byte("0.20.0\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(child, ".tool-versions"), []byte("vcluster 0.21.1\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		// Adjacent providers: the nearer .tool-versions wins.
+		if r, _ := ResolveFrom(child); r.Version != "0.21.1" {
+			t.Fatalf("expected 0.21.1, got %+v", r)
+		}
+		// Separated by env: all .vcluster-version files are checked first.
+		t.Setenv("VCENV_RESOLVE_ORDER", "version-file,env,tool-versions")
+		if r, _ := ResolveFrom(child); r.Version != "0.20.0" {
+			t.Fatalf("expected 0.20.0, got %+v", r)
+		}
+	})
+
+	t.Run("env can be left out", func(t *testing.T) {
+		_, dir := setup(t)
+		t.Setenv("VCENV_VERSION", "0.22.0")
+		t.Setenv("VCENV_RESOLVE_ORDER", "version-file,global")
+		if r, _ := ResolveFrom(dir); r.Version != "0.19.0" || r.Provider != ProviderGlobal {
+			t.Fatalf("expected the global version, got %+v", r)
+		}
+	})
+
+	t.Run("kube context", func(t *testing.T) {
+		root, dir := setup(t)
+		kubeconfig := filepath.Join(t.TempDir(), "config")
+		if err := os.WriteFile(kubeconfig, []byte("apiVersion: v1\ncurrent-context: \"vcluster_tenant-a_team_prod\"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing")+string(os.PathListSeparator)+kubeconfig)
+		mapping := "# context  version\nkind-* 0.20.0\nvcluster_*_prod 0.21.1\n"
+		if err := os.WriteFile(filepath.Join(root, KubeContextsFileName), []byte(mapping), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("VCENV_RESOLVE_ORDER", "kube-context,global")
+
+		r, err := ResolveFrom(dir)
+		if err != nil || r.Version != "0.21.1" || r.Description != "kube context vcluster_tenant-a_team_prod" {
+			t.Fatalf("unexpected resolution %+v (%v)", r, err)
+		}
+	})
+
+	t.Run("remote default", func(t *testing.T) {
+		_, dir := setup(t)
+		t.Setenv("VCENV_RESOLVE_ORDER", "remote-default")
+		r, err := ResolveFrom(dir)
+		if err != nil || r.Provider != ProviderRemote || r.Version == "" {
+			t.Fatalf("expected the newest known release, got %+v (%v)", r, err)
+		}
+	})
+
+	t.Run("explains skipped fallbacks", func(t *testing.T) {
+		root, dir := setup(t)
+		installFake(t, root, "0.21.3")
+		if err := os.WriteFile(filepath.Join(dir, ".vcluster-version"), []byte("0.22.0\n0.21.3\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		r, steps, err := ResolveExplained(dir)
+		if err != nil || r.Version != "0.21.3" || !reflect.DeepEqual(r.Candidates, []string{"0.22.0", "0.21.3"}) {
+			t.Fatalf("unexpected resolution %+v (%v)", r, err)
+		}
+		want := filepath.Join(dir, ".vcluster-version") + ": 0.22.0 (not installed), 0.21.3"
+		if !strings.Contains(strings.Join(steps, "\n"), want) {
+			t.Fatalf("explanation lacks %q:\n%s", want, strings.Join(steps, "\n"))
+		}
+	})
+
+	t.Run("invalid order", func(t *testing.T) {
+		_, dir := setup(t)
+		t.Setenv("VCENV_RESOLVE_ORDER", "nope")
+		if _, err := ResolveFrom(dir); err == nil {
+			t.Fatal("expected an error for an invalid order")
+		}
+	})
+}
+
+func TestCeilingDirectories(t *testing.T) {
+	base := t.TempDir()
+	project := filepath.Join(base, "home", "user", "project")
+	if err := os.MkdirAll(project, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(base, "home", ".vcluster-version"), []byte("0.1.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("VCENV_CEILING_DIRECTORIES", "")
+	if v, err := FindLocalVersionFrom(project); err != nil || v != "0.1.0" {
+		t.Fatalf("expected the stray file to be found without a ceiling, got %q (%v)", v, err)
+	}
+
+	home := filepath.Join(base, "home")
+	t.Setenv("VCENV_CEILING_DIRECTORIES", "relative/dir"+string(os.PathListSeparator)+home+"/")
+	if got := CeilingDirectories(); !reflect.DeepEqual(got, []string{home}) {
+		t.Fatalf("CeilingDirectories() = %v", got)
+	}
+	if v, err := FindLocalVersionFrom(project); err == nil {
+		t.Fatalf("expected the walk to stop below %s, got %q", home, v)
+	}
+	// A search starting in the ceiling directory still checks it.
+	if v, err := FindLocalVersionFrom(home); err != nil || v != "0.1.0" {
+		t.Fatalf("expected the ceiling directory itself to be checked, got %q (%v)", v, err)
+	}
+}
+
+func TestResolveExplained(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("VCENV_ROOT", root)
+	t.Setenv("VCENV_VERSION", "")
+	t.Setenv("VCENV_RESOLVE_HELM", "")
+	t.Setenv("VCENV_RESOLVE_ORDER", "")
+	if err := os.WriteFile(filepath.Join(root, "version"), []byte("0.19.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	project := t.TempDir()
+	child := filepath.Join(project, "child")
+	if err := os.MkdirAll(child, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(child, ".vcluster-version"), []byte("\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(child, ".tool-versions"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VCENV_CEILING_DIRECTORIES", filepath.Dir(project))
+
+	r, steps, err := ResolveExplained(child)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.Version != "0.19.0" || r.Provider != ProviderGlobal {
+		t.Fatalf("expected the global version, got %+v", r)
+	}
+	explanation := strings.Join(steps, "\n")
+	for _, want := range []string{
+		"order: env, version-file, tool-versions, global",
+		"VCENV_VERSION is not set",
+		filepath.Join(child, ".vcluster-version") + ": empty, skipped",
+		filepath.Join(child, ".tool-versions") + ": unreadable",
+		filepath.Join(project, ".vcluster-version") + ": not found",
+		"stopped below ceiling directory " + filepath.Dir(project),
+		"resolved 0.19.0 from global version file",
+	} {
+		if !strings.Contains(explanation, want) {
+			t.Errorf("explanation lacks %q:\n%s", want, explanation)
+		}
+	}
+}
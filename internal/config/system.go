@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SystemVersion is the reserved version name that selects a vcluster binary
+// found on PATH outside of vc-env, as pyenv's "system" does.
+const SystemVersion = "system"
+
+// FindSystemBinary returns the first vcluster executable on PATH, skipping
+// the vc-env shims directory and anything that resolves to the shim itself.
+func FindSystemBinary() (string, error) {
+	var shimsDir, shim string
+	if root, ok := GetVCEnvRoot(); ok {
+		shimsDir = filepath.Clean(filepath.Join(root, "shims"))
+		shim = filepath.Join(shimsDir, "vcluster")
+	}
+	shimInfo, _ := os.Stat(shim)
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			dir = "."
+		}
+		if shimsDir != "" && filepath.Clean(dir) == shimsDir {
+			continue
+		}
+		candidate := filepath.Join(dir, "vcluster")
+		fi, err := os.Stat(candidate)
+		if err != nil || fi.IsDir() || fi.Mode()&0o111 == 0 {
+			continue
+		}
+		if shimInfo != nil && os.SameFile(fi, shimInfo) {
+			continue
+		}
+		return candidate, nil
+	}
+	return "", fmt.Errorf("no system vcluster found in PATH")
+}
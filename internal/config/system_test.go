@@ -0,0 +1,49 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindSystemBinary(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("VCENV_ROOT", root)
+
+	shims := filepath.Join(root, "shims")
+	systemDir := t.TempDir()
+	notExecutable := t.TempDir()
+	for _, p := range []struct {
+		dir  string
+		mode os.FileMode
+	}{{shims, 0o755}, {systemDir, 0o755}, {notExecutable, 0o644}} {
+		if err := os.MkdirAll(p.dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(p.dir, "vcluster"), []byte("#!/bin/sh\n"), p.mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sep := string(os.PathListSeparator)
+	t.Setenv("PATH", strings.Join([]string{shims, notExecutable, systemDir}, sep))
+	got, err := FindSystemBinary()
+	if err != nil {
+		t.Fatalf("FindSystemBinary: %v", err)
+	}
+	if got != filepath.Join(systemDir, "vcluster") {
+		t.Fatalf("expected the system binary, got %s", got)
+	}
+	if path, err := GetBinaryPath(SystemVersion); err != nil || path != got {
+		t.Fatalf("GetBinaryPath(system) = %s, %v", path, err)
+	}
+
+	t.Setenv("PATH", shims)
+	if _, err := FindSystemBinary(); err == nil {
+		t.Fatal("expected error when only the shim is on PATH")
+	}
+	if installed, err := IsVersionInstalled(SystemVersion); err != nil || installed {
+		t.Fatalf("expected system to be reported as not installed, got %v, %v", installed, err)
+	}
+}
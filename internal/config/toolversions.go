@@ -0,0 +1,52 @@
+package config
+
+import (
+	"strings"
+)
+
+// ToolVersionsFileName is the asdf version file.  Its "vcluster" line is
+// honored as a local version, after .vcluster-version in the same directory.
+const ToolVersionsFileName = ".tool-versions"
+
+// toolVersionsTool is the tool name of vcluster in .tool-versions.
+const toolVersionsTool = "vcluster"
+
+// ToolVersions returns all versions on the vcluster line of a .tool-versions
+// file, in order of preference, or nil if there is no vcluster line.  asdf
+// allows a line to list several versions; the first installed one is used.
+func ToolVersions(data []byte) []string {
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == toolVersionsTool {
+			return fields[1:]
+		}
+	}
+	return nil
+}
+
+// SetToolVersion returns the content of a .tool-versions file with the
+// vcluster line set to version.  An existing line is replaced in place,
+// keeping its trailing comment; otherwise the line is appended.  All other
+// lines are kept unchanged.
+func SetToolVersion(data []byte, version string) []byte {
+	content := string(data)
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		entry, comment, hasComment := strings.Cut(line, "#")
+		fields := strings.Fields(entry)
+		if len(fields) == 0 || fields[0] != toolVersionsTool {
+			continue
+		}
+		lines[i] = toolVersionsTool + " " + version
+		if hasComment {
+			lines[i] += " #" + comment
+		}
+		return []byte(strings.Join(lines, "\n"))
+	}
+
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return []byte(content + toolVersionsTool + " " + version + "\n")
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestToolVersions(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"vcluster line", "golang 1.24.4\nvcluster 0.21.1\n", []string{"0.21.1"}},
+		{"fallback versions", "vcluster 0.22.0 0.21.3\n", []string{"0.22.0", "0.21.3"}},
+		{"comments and blanks", "# tools\n\n  vcluster   0.21.1  # pinned\n", []string{"0.21.1"}},
+		{"commented out", "# vcluster 0.20.0\nkubectl 1.30.0\n", nil},
+		{"name without version", "vcluster\n", nil},
+		{"other tool with prefix", "vcluster-pro 0.1.0\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToolVersions([]byte(tt.content)); !slices.Equal(got, tt.want) {
+				t.Fatalf("ToolVersions(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetToolVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"empty file", "", "vcluster 0.22.0\n"},
+		{"append", "golang 1.24.4", "golang 1.24.4\nvcluster 0.22.0\n"},
+		{"replace in place", "golang 1.24.4\nvcluster 0.21.1\nkubectl 1.30.0\n", "golang 1.24.4\nvcluster 0.22.0\nkubectl 1.30.0\n"},
+		{"keep comment", "vcluster 0.21.1 # pinned\n", "vcluster 0.22.0 # pinned\n"},
+		{"ignore commented out", "# vcluster 0.20.0\n", "# vcluster 0.20.0\nvcluster 0.22.0\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(SetToolVersion([]byte(tt.content), "0.22.0")); got != tt.want {
+				t.Fatalf("SetToolVersion(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/user/vc-env/internal/semver"
+)
+
+// Advisory represents a published GitHub security advisory of the vcluster
+// repository.
+type Advisory struct {
+	GHSAID          string          `json:"ghsa_id"`
+	CVEID           string          `json:"cve_id"`
+	Summary         string          `json:"summary"`
+	Severity        string          `json:"severity"`
+	HTMLURL         string          `json:"html_url"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities"`
+}
+
+// Vulnerability is a range of affected versions of an advisory.
+// VulnerableVersionRange uses the GitHub syntax, e.g. ">= 0.19.0, < 0.19.5",
+// and PatchedVersions names the version(s) that fix it.
+type Vulnerability struct {
+	VulnerableVersionRange string `json:"vulnerable_version_range"`
+	PatchedVersions        string `json:"patched_versions"`
+}
+
+// ID returns the CVE identifier of the advisory, or its GHSA identifier if
+// no CVE was assigned.
+func (a Advisory) ID() string {
+	if a.CVEID != "" {
+		return a.CVEID
+	}
+	return a.GHSAID
+}
+
+// Affects returns the vulnerability of the advisory that version falls in.
+// Versions that are not valid semantic versions, such as custom builds, are
+// never affected.  Ranges that cannot be parsed are skipped; InvalidRanges
+// lists them.
+func (a Advisory) Affects(version string) (Vulnerability, bool) {
+	if !semver.IsValid(version) {
+		return Vulnerability{}, false
+	}
+	v := semver.Parse(version)
+	for _, vuln := range a.Vulnerabilities {
+		c, err := semver.ParseConstraint(vuln.VulnerableVersionRange)
+		if err != nil {
+			continue
+		}
+		if c.Check(v) {
+			return vuln, true
+		}
+	}
+	return Vulnerability{}, false
+}
+
+// InvalidRanges returns the vulnerable version ranges of the advisory that
+// cannot be parsed, and so are never matched by Affects.
+func (a Advisory) InvalidRanges() []string {
+	var ranges []string
+	for _, vuln := range a.Vulnerabilities {
+		if _, err := semver.ParseConstraint(vuln.VulnerableVersionRange); err != nil {
+			ranges = append(ranges, vuln.VulnerableVersionRange)
+		}
+	}
+	return ranges
+}
+
+// ListAdvisories fetches the published security advisories of the vcluster
+// repository.
+func (c *Client) ListAdvisories() ([]Advisory, error) {
+	var all []Advisory
+	url := fmt.Sprintf("%s/repos/loft-sh/vcluster/security-advisories?state=published&per_page=100", c.BaseURL)
+	for url != "" {
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("User-Agent", "vc-env")
+
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch security advisories: %w", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusForbidden {
+			return nil, fmt.Errorf("GitHub API rate limit exceeded. Please try again later")
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+
+		var page []Advisory
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse security advisories: %w", err)
+		}
+		all = append(all, page...)
+		url = parseNextPageURL(resp.Header.Get("Link"))
+	}
+	for i := range all {
+		all[i].Severity = strings.ToLower(all[i].Severity)
+	}
+	return all, nil
+}
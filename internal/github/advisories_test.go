@@ -0,0 +1,79 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdvisoryAffects(t *testing.T) {
+	a := Advisory{
+		GHSAID: "GHSA-aaaa-bbbb-cccc",
+		Vulnerabilities: []Vulnerability{
+			{VulnerableVersionRange: ">= 0.19.0, < 0.19.5", PatchedVersions: "0.19.5"},
+			{VulnerableVersionRange: "= 0.20.0", PatchedVersions: "0.20.1"},
+		},
+	}
+	tests := []struct {
+		version string
+		patched string
+	}{
+		{"0.19.2", "0.19.5"},
+		{"0.19.5", ""},
+		{"0.20.0", "0.20.1"},
+		{"0.18.9", ""},
+		{"dev-1a2b3c", ""},
+	}
+	for _, tt := range tests {
+		vuln, ok := a.Affects(tt.version)
+		if ok != (tt.patched != "") || vuln.PatchedVersions != tt.patched {
+			t.Errorf("Affects(%s) = %+v, %v; want patched %q", tt.version, vuln, ok, tt.patched)
+		}
+	}
+	if a.ID() != "GHSA-aaaa-bbbb-cccc" {
+		t.Fatalf("ID() = %s", a.ID())
+	}
+	a.CVEID = "CVE-2026-0001"
+	if a.ID() != "CVE-2026-0001" {
+		t.Fatalf("ID() = %s", a.ID())
+	}
+	if ranges := a.InvalidRanges(); len(ranges) != 0 {
+		t.Fatalf("InvalidRanges() = %v", ranges)
+	}
+
+	a.Vulnerabilities = append(a.Vulnerabilities, Vulnerability{VulnerableVersionRange: "~> 0.21", PatchedVersions: "0.21.4"})
+	if _, ok := a.Affects("0.21.1"); ok {
+		t.Fatal("expected an unparseable range not to match")
+	}
+	if ranges := a.InvalidRanges(); len(ranges) != 1 || ranges[0] != "~> 0.21" {
+		t.Fatalf("InvalidRanges() = %v", ranges)
+	}
+}
+
+func TestListAdvisories(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/loft-sh/vcluster/security-advisories" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		page := []Advisory{{GHSAID: "GHSA-2", Severity: "LOW"}}
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", `<`+server.URL+r.URL.Path+`?page=2>; rel="next"`)
+			page = []Advisory{{GHSAID: "GHSA-1", Severity: "High"}}
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	}))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, HTTPClient: server.Client()}
+	advisories, err := client.ListAdvisories()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(advisories) != 2 || advisories[0].GHSAID != "GHSA-1" || advisories[1].Severity != "low" {
+		t.Fatalf("unexpected advisories %+v", advisories)
+	}
+}
@@ -5,9 +5,12 @@ package github
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"os"
 	"regexp"
 	"strings"
 	"time"
@@ -17,11 +20,44 @@ import (
 
 // Release represents a GitHub release.
 type Release struct {
-	TagName    string `json:"tag_name"`
-	Prerelease bool   `json:"prerelease"`
-	Draft      bool   `json:"draft"`
+	TagName    string  `json:"tag_name"`
+	Prerelease bool    `json:"prerelease"`
+	Draft      bool    `json:"draft"`
+	Assets     []Asset `json:"assets"`
 }
 
+// Asset represents a file attached to a GitHub release.  Digest is
+// "sha256:<hex>" when GitHub has computed one, and empty otherwise.
+type Asset struct {
+	Name               string `json:"name"`
+	Size               int64  `json:"size"`
+	Digest             string `json:"digest"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// AssetNames returns the names of the release's assets.
+func (r *Release) AssetNames() []string {
+	names := make([]string, 0, len(r.Assets))
+	for _, a := range r.Assets {
+		names = append(names, a.Name)
+	}
+	return names
+}
+
+// Asset returns the asset with the given name.
+func (r *Release) Asset(name string) (Asset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
+
+// ErrReleaseNotFound is returned by GetRelease when the release does not
+// exist.
+var ErrReleaseNotFound = errors.New("release not found")
+
 // Client is a GitHub API client for fetching vcluster releases.
 type Client struct {
 	BaseURL         string
@@ -29,17 +65,78 @@ type Client struct {
 	HTTPClient      *http.Client
 }
 
+// connectTimeout bounds how long establishing a connection may take.  It is
+// much shorter than the overall request timeout so that an unreachable
+// network is detected quickly instead of after the full 30 s.
+const connectTimeout = 5 * time.Second
+
+// Default base URLs of the GitHub API and of release downloads.
+const (
+	DefaultBaseURL         = "https://api.github.com"
+	DefaultDownloadBaseURL = "https://github.com"
+)
+
 // NewClient creates a new GitHub API client with default settings.
+// VCENV_API_BASE_URL and VCENV_DOWNLOAD_BASE_URL override the base URLs,
+// e.g. to point at a release mirror.
 func NewClient() *Client {
+	baseURL := DefaultBaseURL
+	if u := os.Getenv("VCENV_API_BASE_URL"); u != "" {
+		baseURL = strings.TrimSuffix(u, "/")
+	}
+	downloadBaseURL := DefaultDownloadBaseURL
+	if u := os.Getenv("VCENV_DOWNLOAD_BASE_URL"); u != "" {
+		downloadBaseURL = strings.TrimSuffix(u, "/")
+	}
 	return &Client{
-		BaseURL:         "https://api.github.com",
-		DownloadBaseURL: "https://github.com",
+		BaseURL:         baseURL,
+		DownloadBaseURL: downloadBaseURL,
 		HTTPClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: newTransport(),
 		},
 	}
 }
 
+// newTransport returns a copy of the default transport with short dial and
+// TLS handshake timeouts.
+func newTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DialContext = (&net.Dialer{
+		Timeout:   connectTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	t.TLSHandshakeTimeout = connectTimeout
+	return t
+}
+
+// downloadClient returns an HTTP client for binary downloads.  It shares the
+// API client's transport but uses a longer timeout to accommodate large files.
+func (c *Client) downloadClient() *http.Client {
+	var transport http.RoundTripper
+	if c.HTTPClient != nil {
+		transport = c.HTTPClient.Transport
+	}
+	return &http.Client{Timeout: 10 * time.Minute, Transport: transport}
+}
+
+// IsConnectionError reports whether err was caused by the network being
+// unreachable (DNS failure, failed dial, timeout).  Other transport errors,
+// such as an untrusted TLS certificate, and HTTP-level error responses prove
+// that the network is reachable and are not connection errors.
+func IsConnectionError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
 // DownloadURL returns the full download URL given a path.
 func (c *Client) DownloadURL(path string) string {
 	return fmt.Sprintf("%s/%s", c.DownloadBaseURL, path)
@@ -172,6 +269,41 @@ func (c *Client) GetLatestRelease() (string, error) {
 	return strings.TrimPrefix(release.TagName, "v"), nil
 }
 
+// GetRelease fetches the vcluster release for version, including its asset
+// list.
+func (c *Client) GetRelease(version string) (*Release, error) {
+	url := fmt.Sprintf("%s/repos/loft-sh/vcluster/releases/tags/v%s", c.BaseURL, strings.TrimPrefix(version, "v"))
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "vc-env")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch release: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("GitHub API rate limit exceeded. Please try again later")
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("vcluster v%s: %w", strings.TrimPrefix(version, "v"), ErrReleaseNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	}
+
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to parse release: %w", err)
+	}
+	return &release, nil
+}
+
 // GetLatestReleaseFor fetches the latest stable release for the given
 // GitHub owner/repo (e.g. "mmpyro/vc-env").
 func (c *Client) GetLatestReleaseFor(ownerRepo string) (string, error) {
@@ -266,8 +398,7 @@ func (c *Client) DownloadBinary(url string) ([]byte, error) {
 	req.Header.Set("User-Agent", "vc-env")
 
 	// Use a dedicated client with a longer timeout for binary downloads.
-	downloadClient := &http.Client{Timeout: 10 * time.Minute}
-	resp, err := downloadClient.Do(req)
+	resp, err := c.downloadClient().Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download binary: %w", err)
 	}
@@ -296,8 +427,7 @@ func (c *Client) DownloadWithProgress(url string, onProgress func(total, current
 	}
 	req.Header.Set("User-Agent", "vc-env")
 
-	downloadClient := &http.Client{Timeout: 10 * time.Minute}
-	resp, err := downloadClient.Do(req)
+	resp, err := c.downloadClient().Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download: %w", err)
 	}
@@ -335,3 +465,16 @@ func (c *Client) DownloadWithProgress(url string, onProgress func(total, current
 
 	return buf.Bytes(), nil
 }
+
+// FindChecksum returns the SHA-256 hex digest listed for filename in the
+// content of a checksums.txt release asset ("<hex>  <filename>" per line).
+func FindChecksum(checksums, filename string) (string, error) {
+	lines := strings.Split(checksums, "\n")
+	for _, line := range lines {
+		parts := strings.Fields(line)
+		if len(parts) >= 2 && strings.TrimPrefix(parts[1], "*") == filename {
+			return parts[0], nil
+		}
+	}
+	return "", fmt.Errorf("checksum not found for %s", filename)
+}
@@ -3,9 +3,13 @@ package github
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestListReleases(t *testing.T) {
@@ -136,6 +140,44 @@ func TestGetLatestRelease(t *testing.T) {
 	}
 }
 
+func TestGetRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/loft-sh/vcluster/releases/tags/v0.21.1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		release := Release{TagName: "v0.21.1", Assets: []Asset{
+			{Name: "vcluster-linux-amd64", Size: 3, Digest: "sha256:abc"},
+			{Name: "checksums.txt", Size: 1},
+		}}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(release); err != nil {
+			t.Fatalf("failed to encode: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{
+		BaseURL:    server.URL,
+		HTTPClient: server.Client(),
+	}
+
+	release, err := client.GetRelease("0.21.1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(release.AssetNames(), ","); got != "vcluster-linux-amd64,checksums.txt" {
+		t.Fatalf("unexpected assets %s", got)
+	}
+	if a, ok := release.Asset("vcluster-linux-amd64"); !ok || a.Digest != "sha256:abc" {
+		t.Fatalf("unexpected asset %+v", a)
+	}
+
+	if _, err := client.GetRelease("9.9.9"); err == nil {
+		t.Fatal("expected error for unknown release")
+	}
+}
+
 func TestGetLatestReleaseRateLimit(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusForbidden)
@@ -193,6 +235,93 @@ func TestDownloadBinaryNotFound(t *testing.T) {
 	}
 }
 
+func TestNewClient_BaseURLOverrides(t *testing.T) {
+	t.Setenv("VCENV_API_BASE_URL", "")
+	t.Setenv("VCENV_DOWNLOAD_BASE_URL", "http://mirror.local/vcluster/")
+	client := NewClient()
+	if client.DownloadBaseURL != "http://mirror.local/vcluster" {
+		t.Fatalf("unexpected DownloadBaseURL %q", client.DownloadBaseURL)
+	}
+	if client.BaseURL != DefaultBaseURL {
+		t.Fatalf("unexpected BaseURL %q", client.BaseURL)
+	}
+
+	t.Setenv("VCENV_API_BASE_URL", "http://mirror.local:8080")
+	if got := NewClient().BaseURL; got != "http://mirror.local:8080" {
+		t.Fatalf("unexpected BaseURL %q", got)
+	}
+}
+
+func TestIsConnectionError(t *testing.T) {
+	t.Run("unreachable host is a connection error", func(t *testing.T) {
+		client := &Client{
+			BaseURL:    "http://127.0.0.1:0",
+			HTTPClient: &http.Client{Timeout: 100 * time.Millisecond},
+		}
+		_, err := client.GetLatestRelease()
+		if err == nil {
+			t.Fatal("expected error for unreachable host")
+		}
+		if !IsConnectionError(err) {
+			t.Fatalf("expected connection error, got %v", err)
+		}
+	})
+
+	t.Run("HTTP error status is not a connection error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		client := &Client{
+			BaseURL:    server.URL,
+			HTTPClient: server.Client(),
+		}
+		_, err := client.GetLatestRelease()
+		if err == nil {
+			t.Fatal("expected error on 500")
+		}
+		if IsConnectionError(err) {
+			t.Fatalf("expected non-connection error, got %v", err)
+		}
+	})
+
+	t.Run("timeout is a connection error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer server.Close()
+
+		client := &Client{
+			BaseURL:    server.URL,
+			HTTPClient: &http.Client{Timeout: 20 * time.Millisecond},
+		}
+		_, err := client.GetLatestRelease()
+		if err == nil || !IsConnectionError(err) {
+			t.Fatalf("expected connection error, got %v", err)
+		}
+	})
+
+	t.Run("untrusted certificate is not a connection error", func(t *testing.T) {
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.Config.ErrorLog = log.New(io.Discard, "", 0)
+		server.StartTLS()
+		defer server.Close()
+
+		client := &Client{
+			BaseURL:    server.URL,
+			HTTPClient: &http.Client{Timeout: time.Second},
+		}
+		_, err := client.GetLatestRelease()
+		if err == nil || !strings.Contains(err.Error(), "certificate") {
+			t.Fatalf("expected certificate error, got %v", err)
+		}
+		if IsConnectionError(err) {
+			t.Fatalf("expected non-connection error, got %v", err)
+		}
+	})
+}
+
 func TestParseNextPageURL(t *testing.T) {
 	tests := []struct {
 		name     string
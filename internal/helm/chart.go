@@ -0,0 +1,187 @@
+// Package helm reads the vcluster chart version pinned by a Helm chart.
+//
+// Umbrella charts that deploy vcluster declare the loft-sh vcluster chart as
+// a dependency in Chart.yaml, and Chart.lock records the version that was
+// resolved for it.  Since the vcluster chart and CLI are released together,
+// that version is also the matching CLI version.
+//
+// Only the dependencies list is read, with a minimal parser for the block
+// style that "helm create" and "helm dependency update" produce.
+package helm
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Chart file names, in the order they are consulted.
+const (
+	LockFileName  = "Chart.lock"
+	ChartFileName = "Chart.yaml"
+)
+
+// chartNames are the names under which loft-sh published the vcluster
+// chart.  The distro specific charts were merged into "vcluster" in 0.20.
+var chartNames = []string{"vcluster", "vcluster-k8s", "vcluster-k0s", "vcluster-eks"}
+
+// exactVersionPattern matches a pinned chart version, as opposed to a range.
+var exactVersionPattern = regexp.MustCompile(`^v?\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?$`)
+
+// Dependency is an entry of the dependencies list of Chart.yaml or
+// Chart.lock.
+type Dependency struct {
+	Name       string
+	Version    string
+	Repository string
+}
+
+// ParseDependencies returns the dependencies listed in the content of a
+// Chart.yaml or Chart.lock file.
+func ParseDependencies(data []byte) []Dependency {
+	var deps []Dependency
+	var current *Dependency
+	inDependencies := false
+	itemIndent, keyIndent := -1, -1
+
+	for _, raw := range strings.Split(string(data), "\n") {
+		line := stripComment(strings.TrimRight(raw, " \t\r"))
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		text := strings.TrimSpace(line)
+
+		if indent == 0 && !strings.HasPrefix(text, "-") {
+			inDependencies = text == "dependencies:"
+			current = nil
+			itemIndent = -1
+			continue
+		}
+		if !inDependencies {
+			continue
+		}
+
+		if itemIndent == -1 && strings.HasPrefix(text, "-") {
+			itemIndent = indent
+		}
+		switch {
+		case indent == itemIndent && strings.HasPrefix(text, "-"):
+			// A new list item.  Its keys are aligned with the first one,
+			// which usually follows the dash on the same line.
+			deps = append(deps, Dependency{})
+			current = &deps[len(deps)-1]
+			rest := text[1:]
+			text = strings.TrimLeft(rest, " ")
+			keyIndent = indent + 1 + len(rest) - len(text)
+			if text == "" {
+				keyIndent = -1
+				continue
+			}
+		case indent <= itemIndent:
+			current = nil
+			continue
+		default:
+			if keyIndent == -1 {
+				keyIndent = indent
+			}
+			// Keys of nested mappings, e.g. import-values, are skipped.
+			if indent != keyIndent {
+				continue
+			}
+		}
+		if current == nil {
+			continue
+		}
+
+		key, value, ok := strings.Cut(text, ":")
+		if !ok {
+			continue
+		}
+		value = unquote(strings.TrimSpace(value))
+		switch strings.TrimSpace(key) {
+		case "name":
+			current.Name = value
+		case "version":
+			current.Version = value
+		case "repository":
+			current.Repository = value
+		}
+	}
+	return deps
+}
+
+// VClusterVersion returns the exact version of the loft-sh vcluster chart
+// among deps.  Dependencies with a version range are ignored.
+func VClusterVersion(deps []Dependency) (string, bool) {
+	for _, d := range deps {
+		if !isVClusterChart(d) || !exactVersionPattern.MatchString(d.Version) {
+			continue
+		}
+		return strings.TrimPrefix(d.Version, "v"), true
+	}
+	return "", false
+}
+
+// FindVClusterVersion returns the vcluster chart version pinned by the chart
+// in dir and the file it was read from.  Chart.lock is preferred, as it
+// records the version Helm resolved; Chart.yaml is used when it pins an
+// exact version.
+func FindVClusterVersion(dir string) (string, string, bool) {
+	for _, name := range []string{LockFileName, ChartFileName} {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if v, ok := VClusterVersion(ParseDependencies(data)); ok {
+			return v, path, true
+		}
+	}
+	return "", "", false
+}
+
+// isVClusterChart reports whether d refers to the vcluster chart published
+// by loft-sh, either through its chart repository, its OCI registry or a
+// local repository named "loft".
+func isVClusterChart(d Dependency) bool {
+	isName := false
+	for _, n := range chartNames {
+		if d.Name == n {
+			isName = true
+		}
+	}
+	if !isName {
+		return false
+	}
+	repo := strings.ToLower(d.Repository)
+	return strings.Contains(repo, "loft.sh") || strings.Contains(repo, "loft-sh") ||
+		repo == "@loft" || repo == "alias:loft"
+}
+
+// stripComment removes a trailing "#" comment that is not inside quotes.
+func stripComment(line string) string {
+	quote := byte(0)
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// unquote removes matching single or double quotes around a scalar.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,103 @@
+package helm
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const umbrellaChart = `apiVersion: v2
+name: platform
+version: 1.0.0
+dependencies:
+  - name: cert-manager
+    version: "~1.14.0"
+    repository: https://charts.jetstack.io
+  - name: vcluster   # the virtual cluster
+    version: "0.21.1"
+    repository: "https://charts.loft.sh"
+    alias: tenant-a
+    import-values:
+      - child: name
+        parent: version
+`
+
+const umbrellaLock = `dependencies:
+- name: cert-manager
+  repository: https://charts.jetstack.io
+  version: v1.14.4
+- name: vcluster
+  repository: https://charts.loft.sh
+  version: 0.21.2
+digest: sha256:0123
+generated: "2024-10-01T12:00:00Z"
+`
+
+func TestParseDependencies(t *testing.T) {
+	want := []Dependency{
+		{Name: "cert-manager", Version: "~1.14.0", Repository: "https://charts.jetstack.io"},
+		{Name: "vcluster", Version: "0.21.1", Repository: "https://charts.loft.sh"},
+	}
+	if got := ParseDependencies([]byte(umbrellaChart)); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Chart.yaml: got %+v, want %+v", got, want)
+	}
+
+	want = []Dependency{
+		{Name: "cert-manager", Version: "v1.14.4", Repository: "https://charts.jetstack.io"},
+		{Name: "vcluster", Version: "0.21.2", Repository: "https://charts.loft.sh"},
+	}
+	if got := ParseDependencies([]byte(umbrellaLock)); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Chart.lock: got %+v, want %+v", got, want)
+	}
+
+	if got := ParseDependencies([]byte("apiVersion: v2\nname: plain\nversion: 0.1.0\n")); len(got) != 0 {
+		t.Fatalf("expected no dependencies, got %+v", got)
+	}
+}
+
+func TestVClusterVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		deps []Dependency
+		want string
+		ok   bool
+	}{
+		{"chart repository", []Dependency{{Name: "vcluster", Version: "0.21.1", Repository: "https://charts.loft.sh"}}, "0.21.1", true},
+		{"oci registry", []Dependency{{Name: "vcluster", Version: "v0.22.0", Repository: "oci://ghcr.io/loft-sh/charts"}}, "0.22.0", true},
+		{"repository alias", []Dependency{{Name: "vcluster-k8s", Version: "0.19.7", Repository: "@loft"}}, "0.19.7", true},
+		{"version range", []Dependency{{Name: "vcluster", Version: "~0.21.0", Repository: "https://charts.loft.sh"}}, "", false},
+		{"other repository", []Dependency{{Name: "vcluster", Version: "0.21.1", Repository: "https://example.com/charts"}}, "", false},
+		{"other chart", []Dependency{{Name: "loft", Version: "3.4.0", Repository: "https://charts.loft.sh"}}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := VClusterVersion(tt.deps)
+			if got != tt.want || ok != tt.ok {
+				t.Fatalf("VClusterVersion = %q, %v; want %q, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestFindVClusterVersion(t *testing.T) {
+	dir := t.TempDir()
+	if _, _, ok := FindVClusterVersion(dir); ok {
+		t.Fatal("expected no version without chart files")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ChartFileName), []byte(umbrellaChart), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if v, file, ok := FindVClusterVersion(dir); !ok || v != "0.21.1" || file != filepath.Join(dir, ChartFileName) {
+		t.Fatalf("expected 0.21.1 from Chart.yaml, got %q from %q (%v)", v, file, ok)
+	}
+
+	// Chart.lock records the resolved version and wins over Chart.yaml.
+	if err := os.WriteFile(filepath.Join(dir, LockFileName), []byte(umbrellaLock), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if v, file, ok := FindVClusterVersion(dir); !ok || v != "0.21.2" || file != filepath.Join(dir, LockFileName) {
+		t.Fatalf("expected 0.21.2 from Chart.lock, got %q from %q (%v)", v, file, ok)
+	}
+}
@@ -0,0 +1,107 @@
+// Package mirror describes the on-disk layout of a self-hosted vcluster
+// release mirror.
+//
+// A mirror directory reproduces the GitHub download paths used by
+// platform.DownloadPath and platform.ChecksumPath, so that any static web
+// server can serve it as a client's DownloadBaseURL:
+//
+//	<dir>/loft-sh/vcluster/releases/download/v<version>/<asset>
+//	<dir>/loft-sh/vcluster/releases/download/v<version>/checksums.txt
+//	<dir>/index.json
+//
+// index.json lists every mirrored release and its assets with their sizes
+// and SHA-256 digests.
+package mirror
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/platform"
+	"github.com/user/vc-env/internal/semver"
+)
+
+// IndexFileName is the name of the release index at the mirror root.
+const IndexFileName = "index.json"
+
+// Index is the JSON release index of a mirror.
+type Index struct {
+	GeneratedAt time.Time      `json:"generated_at"`
+	Releases    []IndexRelease `json:"releases"`
+}
+
+// IndexRelease is one mirrored release.
+type IndexRelease struct {
+	TagName    string       `json:"tag_name"`
+	Prerelease bool         `json:"prerelease"`
+	Assets     []IndexAsset `json:"assets"`
+}
+
+// IndexAsset is one file of a mirrored release.
+type IndexAsset struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	Digest string `json:"digest"`
+}
+
+// Version returns the release version without the leading "v".
+func (r IndexRelease) Version() string {
+	return strings.TrimPrefix(r.TagName, "v")
+}
+
+// ReleaseDir returns the directory holding the assets of version.
+func ReleaseDir(dir, version string) string {
+	return filepath.Join(dir, filepath.FromSlash(filepath.Dir(platform.ChecksumPath(version))))
+}
+
+// AssetPath returns the path of an asset of version inside the mirror.
+func AssetPath(dir, version, name string) string {
+	return filepath.Join(ReleaseDir(dir, version), name)
+}
+
+// LoadIndex reads the index of the mirror at dir.  A missing index yields an
+// empty Index and no error.
+func LoadIndex(dir string) (*Index, error) {
+	data, err := os.ReadFile(filepath.Join(dir, IndexFileName))
+	if os.IsNotExist(err) {
+		return &Index{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mirror: failed to read index: %w", err)
+	}
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("mirror: invalid index %s: %w", filepath.Join(dir, IndexFileName), err)
+	}
+	return &idx, nil
+}
+
+// Upsert adds or replaces a release, keeping the index sorted newest-first.
+func (idx *Index) Upsert(r IndexRelease) {
+	for i := range idx.Releases {
+		if idx.Releases[i].TagName == r.TagName {
+			idx.Releases[i] = r
+			return
+		}
+	}
+	idx.Releases = append(idx.Releases, r)
+	sort.SliceStable(idx.Releases, func(i, j int) bool {
+		return semver.Less(semver.Parse(idx.Releases[j].TagName), semver.Parse(idx.Releases[i].TagName))
+	})
+}
+
+// Save writes the index to dir atomically.
+func (idx *Index) Save(dir string) error {
+	idx.GeneratedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("mirror: failed to marshal index: %w", err)
+	}
+	return cache.WriteFileAtomic(filepath.Join(dir, IndexFileName), data, 0o644)
+}
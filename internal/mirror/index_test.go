@@ -0,0 +1,78 @@
+package mirror
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIndexUpsert_SortsNewestFirst(t *testing.T) {
+	idx := &Index{}
+	idx.Upsert(IndexRelease{TagName: "v0.20.0"})
+	idx.Upsert(IndexRelease{TagName: "v0.22.0"})
+	idx.Upsert(IndexRelease{TagName: "v0.21.1"})
+	idx.Upsert(IndexRelease{TagName: "v0.21.1", Assets: []IndexAsset{{Name: "checksums.txt"}}})
+
+	want := []string{"v0.22.0", "v0.21.1", "v0.20.0"}
+	if len(idx.Releases) != len(want) {
+		t.Fatalf("expected %d releases, got %d", len(want), len(idx.Releases))
+	}
+	for i, tag := range want {
+		if idx.Releases[i].TagName != tag {
+			t.Fatalf("release %d: expected %s, got %s", i, tag, idx.Releases[i].TagName)
+		}
+	}
+	if len(idx.Releases[1].Assets) != 1 {
+		t.Fatal("expected Upsert to replace the existing release")
+	}
+}
+
+func TestIndexSaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	empty, err := LoadIndex(dir)
+	if err != nil {
+		t.Fatalf("LoadIndex on empty dir: %v", err)
+	}
+	if len(empty.Releases) != 0 {
+		t.Fatal("expected empty index")
+	}
+
+	idx := &Index{}
+	idx.Upsert(IndexRelease{TagName: "v0.21.1", Assets: []IndexAsset{{Name: "vcluster-linux-amd64", Size: 3, Digest: "sha256:abc"}}})
+	if err := idx.Save(dir); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := LoadIndex(dir)
+	if err != nil {
+		t.Fatalf("LoadIndex: %v", err)
+	}
+	if loaded.GeneratedAt.IsZero() {
+		t.Error("expected GeneratedAt to be set")
+	}
+	if len(loaded.Releases) != 1 || loaded.Releases[0].Version() != "0.21.1" {
+		t.Fatalf("unexpected releases: %+v", loaded.Releases)
+	}
+	if a := loaded.Releases[0].Assets[0]; a.Size != 3 || a.Digest != "sha256:abc" {
+		t.Fatalf("unexpected asset: %+v", a)
+	}
+}
+
+func TestAssetPath(t *testing.T) {
+	got := AssetPath("/srv/mirror", "0.21.1", "checksums.txt")
+	want := filepath.FromSlash("/srv/mirror/loft-sh/vcluster/releases/download/v0.21.1/checksums.txt")
+	if got != want {
+		t.Fatalf("AssetPath = %q, want %q", got, want)
+	}
+}
+
+func TestLoadIndex_Corrupt(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, IndexFileName), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadIndex(dir); err == nil {
+		t.Fatal("expected error for corrupt index")
+	}
+}
@@ -0,0 +1,395 @@
+package mirror
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/platform"
+	"github.com/user/vc-env/internal/semver"
+)
+
+// Source provides the releases and files served by a Handler.
+type Source interface {
+	// Releases returns the available releases, newest first.
+	Releases() ([]IndexRelease, error)
+	// Open opens an asset of a release.  It returns an error satisfying
+	// os.IsNotExist when the asset does not exist.
+	Open(version, name string) (io.ReadSeekCloser, error)
+}
+
+// dirSource serves a mirror directory created by "vc-env mirror sync".
+type dirSource struct {
+	dir string
+}
+
+// NewDirSource returns a Source backed by the mirror directory dir.
+func NewDirSource(dir string) Source {
+	return &dirSource{dir: dir}
+}
+
+func (s *dirSource) Releases() ([]IndexRelease, error) {
+	idx, err := LoadIndex(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	return idx.Releases, nil
+}
+
+func (s *dirSource) Open(version, name string) (io.ReadSeekCloser, error) {
+	if !validVersion(version) || !validAssetName(name) {
+		return nil, os.ErrNotExist
+	}
+	p := AssetPath(s.dir, version, name)
+	if !within(s.dir, p) {
+		return nil, os.ErrNotExist
+	}
+	return os.Open(p)
+}
+
+// versionsSource serves the binaries installed in $VCENV_ROOT/versions as
+// releases for a single platform, with checksums.txt generated on the fly.
+type versionsSource struct {
+	dir  string
+	info platform.Info
+
+	mu      sync.Mutex
+	digests map[string]digestEntry
+}
+
+// digestEntry memoises the SHA-256 of an installed binary so that listing
+// releases does not re-hash every binary on each request.
+type digestEntry struct {
+	size    int64
+	modTime time.Time
+	hex     string
+}
+
+// NewVersionsSource returns a Source backed by a vc-env versions directory
+// ($VCENV_ROOT/versions).  Installed binaries are published as the assets of
+// the given platform.
+func NewVersionsSource(dir string, info platform.Info) Source {
+	return &versionsSource{dir: dir, info: info, digests: map[string]digestEntry{}}
+}
+
+func (s *versionsSource) Releases() ([]IndexRelease, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mirror: failed to read %s: %w", s.dir, err)
+	}
+
+	var versions []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(s.dir, e.Name(), "vcluster")); err == nil {
+			versions = append(versions, e.Name())
+		}
+	}
+
+	var releases []IndexRelease
+	for _, v := range semver.SortDescending(versions) {
+		sum, size, err := s.digest(v)
+		if err != nil {
+			return nil, err
+		}
+		checksums := s.checksums(sum)
+		releases = append(releases, IndexRelease{
+			TagName:    "v" + v,
+			Prerelease: semver.Parse(v).PreRelease != "",
+			Assets: []IndexAsset{
+				{Name: platform.BinaryName(s.info), Size: size, Digest: cache.FormatDigest(sum)},
+				{Name: "checksums.txt", Size: int64(len(checksums)), Digest: cache.Digest(checksums)},
+			},
+		})
+	}
+	return releases, nil
+}
+
+func (s *versionsSource) Open(version, name string) (io.ReadSeekCloser, error) {
+	if !validVersion(version) {
+		return nil, os.ErrNotExist
+	}
+	switch name {
+	case platform.BinaryName(s.info):
+		p := filepath.Join(s.dir, version, "vcluster")
+		if !within(s.dir, p) {
+			return nil, os.ErrNotExist
+		}
+		return os.Open(p)
+	case "checksums.txt":
+		sum, _, err := s.digest(version)
+		if err != nil {
+			return nil, err
+		}
+		return nopCloser{bytes.NewReader(s.checksums(sum))}, nil
+	}
+	return nil, os.ErrNotExist
+}
+
+// digest returns the hex SHA-256 and size of the installed binary of version.
+func (s *versionsSource) digest(version string) (string, int64, error) {
+	binary := filepath.Join(s.dir, version, "vcluster")
+	fi, err := os.Stat(binary)
+	if err != nil {
+		return "", 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d, ok := s.digests[version]; ok && d.size == fi.Size() && d.modTime.Equal(fi.ModTime()) {
+		return d.hex, d.size, nil
+	}
+	data, err := os.ReadFile(binary)
+	if err != nil {
+		return "", 0, err
+	}
+	sum := cache.SHA256(data)
+	s.digests[version] = digestEntry{size: fi.Size(), modTime: fi.ModTime(), hex: sum}
+	return sum, fi.Size(), nil
+}
+
+// checksums renders a checksums.txt listing the single published binary.
+func (s *versionsSource) checksums(sum string) []byte {
+	return []byte(fmt.Sprintf("%s  %s\n", sum, platform.BinaryName(s.info)))
+}
+
+// nopCloser adds a no-op Close to an io.ReadSeeker.
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error { return nil }
+
+// validAssetName rejects names that could escape the release directory.
+func validAssetName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// versionPattern matches the version names that can be served: release
+// versions and the names of custom builds, as a single path element.
+var versionPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]*$`)
+
+// validVersion reports whether version can be used as a path element of a
+// release directory.  The tag of a download URL is percent-decoded, so it
+// may contain "/" or "..".
+func validVersion(version string) bool {
+	return versionPattern.MatchString(version) && !strings.Contains(version, "..")
+}
+
+// within reports whether path lies under dir.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// apiRelease is the subset of the GitHub release object served by Handler.
+type apiRelease struct {
+	TagName    string     `json:"tag_name"`
+	Name       string     `json:"name"`
+	Prerelease bool       `json:"prerelease"`
+	Draft      bool       `json:"draft"`
+	Assets     []apiAsset `json:"assets"`
+}
+
+// apiAsset is the subset of the GitHub release asset object served by
+// Handler.
+type apiAsset struct {
+	Name               string `json:"name"`
+	Size               int64  `json:"size"`
+	Digest             string `json:"digest,omitempty"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+const (
+	defaultPerPage = 30
+	maxPerPage     = 100
+)
+
+// handler implements the HTTP endpoints of the mirror server.
+type handler struct {
+	src Source
+}
+
+// NewHandler returns an http.Handler that serves src through the endpoints
+// consumed by github.Client:
+//
+//	GET /repos/loft-sh/vcluster/releases            (per_page/page, Link header)
+//	GET /repos/loft-sh/vcluster/releases/latest
+//	GET /repos/loft-sh/vcluster/releases/tags/{tag}
+//	GET /loft-sh/vcluster/releases/download/{tag}/{name}
+//
+// The same base URL can therefore be used as both the API and the download
+// base URL of a client.
+func NewHandler(src Source) http.Handler {
+	h := &handler{src: src}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/loft-sh/vcluster/releases", h.listReleases)
+	mux.HandleFunc("GET /repos/loft-sh/vcluster/releases/latest", h.latestRelease)
+	mux.HandleFunc("GET /repos/loft-sh/vcluster/releases/tags/{tag}", h.releaseByTag)
+	mux.HandleFunc("GET /loft-sh/vcluster/releases/download/{tag}/{name}", h.download)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound)
+	})
+	return mux
+}
+
+func (h *handler) listReleases(w http.ResponseWriter, r *http.Request) {
+	releases, err := h.src.Releases()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError)
+		return
+	}
+
+	perPage := queryInt(r, "per_page", defaultPerPage)
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+	page := queryInt(r, "page", 1)
+	lastPage := (len(releases) + perPage - 1) / perPage
+	if lastPage == 0 {
+		lastPage = 1
+	}
+
+	start := min((page-1)*perPage, len(releases))
+	end := min(start+perPage, len(releases))
+
+	var links []string
+	link := func(p int, rel string) {
+		links = append(links, fmt.Sprintf(`<%s%s?per_page=%d&page=%d>; rel="%s"`, baseURL(r), r.URL.Path, perPage, p, rel))
+	}
+	if page < lastPage {
+		link(page+1, "next")
+		link(lastPage, "last")
+	}
+	if page > 1 {
+		link(1, "first")
+		link(min(page-1, lastPage), "prev")
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+
+	out := make([]apiRelease, 0, end-start)
+	for _, rel := range releases[start:end] {
+		out = append(out, toAPIRelease(r, rel))
+	}
+	writeJSON(w, out)
+}
+
+func (h *handler) latestRelease(w http.ResponseWriter, r *http.Request) {
+	releases, err := h.src.Releases()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError)
+		return
+	}
+	for _, rel := range releases {
+		if !rel.Prerelease {
+			writeJSON(w, toAPIRelease(r, rel))
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound)
+}
+
+func (h *handler) releaseByTag(w http.ResponseWriter, r *http.Request) {
+	releases, err := h.src.Releases()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError)
+		return
+	}
+	tag := r.PathValue("tag")
+	for _, rel := range releases {
+		if rel.TagName == tag {
+			writeJSON(w, toAPIRelease(r, rel))
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound)
+}
+
+func (h *handler) download(w http.ResponseWriter, r *http.Request) {
+	tag, name := r.PathValue("tag"), r.PathValue("name")
+	if !strings.HasPrefix(tag, "v") || !validVersion(strings.TrimPrefix(tag, "v")) {
+		writeError(w, http.StatusNotFound)
+		return
+	}
+	f, err := h.src.Open(strings.TrimPrefix(tag, "v"), name)
+	if os.IsNotExist(err) {
+		writeError(w, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	http.ServeContent(w, r, name, time.Time{}, f)
+}
+
+// toAPIRelease converts an index entry into a GitHub release object whose
+// download URLs point back at the server handling r.
+func toAPIRelease(r *http.Request, rel IndexRelease) apiRelease {
+	out := apiRelease{
+		TagName:    rel.TagName,
+		Name:       rel.TagName,
+		Prerelease: rel.Prerelease,
+		Assets:     make([]apiAsset, 0, len(rel.Assets)),
+	}
+	for _, a := range rel.Assets {
+		out.Assets = append(out.Assets, apiAsset{
+			Name:               a.Name,
+			Size:               a.Size,
+			Digest:             a.Digest,
+			BrowserDownloadURL: baseURL(r) + "/" + path.Join(path.Dir(platform.ChecksumPath(rel.Version())), a.Name),
+		})
+	}
+	return out
+}
+
+// baseURL returns the scheme and host the request was addressed to.
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// queryInt returns a positive integer query parameter, or def.
+func queryInt(r *http.Request, key string, def int) int {
+	n, err := strconv.Atoi(r.URL.Query().Get(key))
+	if err != nil || n < 1 {
+		return def
+	}
+	return n
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a GitHub-style JSON error body.
+func writeError(w http.ResponseWriter, status int) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": http.StatusText(status)})
+}
@@ -0,0 +1,242 @@
+package mirror
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/github"
+	"github.com/user/vc-env/internal/platform"
+)
+
+// newTestMirror writes a mirror with n stable releases (0.1.0 … 0.n.0) and a
+// pre-release, each with a single linux-amd64 binary.
+func newTestMirror(t *testing.T, n int) string {
+	t.Helper()
+	dir := t.TempDir()
+	idx := &Index{}
+	add := func(version string, prerelease bool) {
+		data := []byte("binary " + version)
+		if err := cache.WriteFileAtomic(AssetPath(dir, version, "vcluster-linux-amd64"), data, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		idx.Upsert(IndexRelease{
+			TagName:    "v" + version,
+			Prerelease: prerelease,
+			Assets:     []IndexAsset{{Name: "vcluster-linux-amd64", Size: int64(len(data)), Digest: cache.Digest(data)}},
+		})
+	}
+	for i := 1; i <= n; i++ {
+		add(fmt.Sprintf("0.%d.0", i), false)
+	}
+	add(fmt.Sprintf("0.%d.0-beta.1", n+1), true)
+	if err := idx.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestHandler_ListReleasesPagination(t *testing.T) {
+	server := httptest.NewServer(NewHandler(NewDirSource(newTestMirror(t, 120))))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/repos/loft-sh/vcluster/releases?per_page=100&page=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var page []apiRelease
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 100 {
+		t.Fatalf("expected 100 releases on page 1, got %d", len(page))
+	}
+	if page[0].TagName != "v0.121.0-beta.1" {
+		t.Fatalf("expected newest release first, got %s", page[0].TagName)
+	}
+	if link := resp.Header.Get("Link"); !strings.Contains(link, `page=2>; rel="next"`) {
+		t.Fatalf("expected next link, got %q", link)
+	}
+
+	// The real client follows the Link header across pages.
+	client := &github.Client{BaseURL: server.URL, DownloadBaseURL: server.URL, HTTPClient: server.Client()}
+	stable, err := client.ListReleases(false)
+	if err != nil {
+		t.Fatalf("ListReleases: %v", err)
+	}
+	if len(stable) != 120 || stable[0] != "0.120.0" {
+		t.Fatalf("unexpected stable releases: %d, first %v", len(stable), stable[:1])
+	}
+	all, err := client.ListReleases(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 121 {
+		t.Fatalf("expected 121 releases including pre-release, got %d", len(all))
+	}
+}
+
+func TestHandler_LatestAndTag(t *testing.T) {
+	server := httptest.NewServer(NewHandler(NewDirSource(newTestMirror(t, 3))))
+	defer server.Close()
+	client := &github.Client{BaseURL: server.URL, DownloadBaseURL: server.URL, HTTPClient: server.Client()}
+
+	latest, err := client.GetLatestRelease()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest != "0.3.0" {
+		t.Fatalf("expected latest stable 0.3.0, got %s", latest)
+	}
+
+	resp, err := http.Get(server.URL + "/repos/loft-sh/vcluster/releases/tags/v0.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var rel apiRelease
+	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		t.Fatal(err)
+	}
+	want := server.URL + "/loft-sh/vcluster/releases/download/v0.2.0/vcluster-linux-amd64"
+	if len(rel.Assets) != 1 || rel.Assets[0].BrowserDownloadURL != want {
+		t.Fatalf("unexpected assets: %+v", rel.Assets)
+	}
+
+	resp, err = http.Get(server.URL + "/repos/loft-sh/vcluster/releases/tags/v9.9.9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown tag, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandler_Download(t *testing.T) {
+	server := httptest.NewServer(NewHandler(NewDirSource(newTestMirror(t, 2))))
+	defer server.Close()
+	client := &github.Client{BaseURL: server.URL, DownloadBaseURL: server.URL, HTTPClient: server.Client()}
+
+	data, err := client.DownloadBinary(client.DownloadURL(platform.DownloadPath("0.2.0", platform.Info{OS: "linux", Arch: "amd64"})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "binary 0.2.0" {
+		t.Fatalf("unexpected content %q", data)
+	}
+
+	for _, path := range []string{
+		"/loft-sh/vcluster/releases/download/v0.2.0/vcluster-darwin-arm64",
+		"/loft-sh/vcluster/releases/download/v0.2.0/..%2f..%2findex.json",
+		"/loft-sh/vcluster/releases/download/0.2.0/vcluster-linux-amd64",
+		"/loft-sh/vcluster/releases/download/vx%2F..%2F..%2F..%2F..%2F..%2F..%2F/secret.txt",
+		"/loft-sh/vcluster/releases/download/v..%2F..%2F/secret.txt",
+	} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestVersionsSource(t *testing.T) {
+	dir := t.TempDir()
+	for _, v := range []string{"0.20.0", "0.21.1"} {
+		if err := os.MkdirAll(filepath.Join(dir, v), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, v, "vcluster"), []byte("installed "+v), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A directory without a binary is not a release.
+	if err := os.MkdirAll(filepath.Join(dir, "0.22.0"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	info := platform.Info{OS: "linux", Arch: "arm64"}
+	server := httptest.NewServer(NewHandler(NewVersionsSource(dir, info)))
+	defer server.Close()
+	client := &github.Client{BaseURL: server.URL, DownloadBaseURL: server.URL, HTTPClient: server.Client()}
+
+	versions, err := client.ListReleases(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(versions, ",") != "0.21.1,0.20.0" {
+		t.Fatalf("unexpected versions %v", versions)
+	}
+
+	binary, err := client.DownloadBinary(client.DownloadURL(platform.DownloadPath("0.21.1", info)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checksums, err := client.DownloadBinary(client.DownloadURL(platform.ChecksumPath("0.21.1")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := github.FindChecksum(string(checksums), platform.BinaryName(info))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected != cache.SHA256(binary) {
+		t.Fatal("generated checksums.txt does not match the served binary")
+	}
+
+	resp, err := http.Get(server.URL + "/loft-sh/vcluster/releases/download/v0.21.1/vcluster-linux-amd64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for another platform, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandler_DownloadTraversal(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "mirror")
+	if err := cache.WriteFileAtomic(AssetPath(dir, "0.1.0", "vcluster-linux-amd64"), []byte("binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(parent, "secret.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, src := range map[string]Source{
+		"mirror directory": NewDirSource(dir),
+		"versions":         NewVersionsSource(dir, platform.Info{OS: "linux", Arch: "amd64"}),
+	} {
+		server := httptest.NewServer(NewHandler(src))
+		resp, err := http.Get(server.URL + "/loft-sh/vcluster/releases/download/vx%2F..%2F..%2F..%2F..%2F..%2F..%2F/secret.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		server.Close()
+		if resp.StatusCode != http.StatusNotFound || strings.Contains(string(body), "secret") {
+			t.Errorf("%s: expected 404, got %d %q", name, resp.StatusCode, body)
+		}
+	}
+
+	for _, version := range []string{"x/../..", `..\..`, "..", ""} {
+		if _, err := NewDirSource(dir).Open(version, "vcluster-linux-amd64"); !os.IsNotExist(err) {
+			t.Errorf("Open(%q): expected a not-exist error, got %v", version, err)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package pins
+
+import (
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestFindHonoursGitignore(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".gitignore"), "# build output\nbuild/\n/tmp\n*.bak/\n")
+	writeFile(t, filepath.Join(root, "build", ".vcluster-version"), "0.1.0\n")
+	writeFile(t, filepath.Join(root, "tmp", ".vcluster-version"), "0.1.0\n")
+	writeFile(t, filepath.Join(root, "app", "tmp", ".vcluster-version"), "0.21.0\n")
+	writeFile(t, filepath.Join(root, "app", ".gitignore"), "fixtures/*\n!fixtures/keep/\n")
+	writeFile(t, filepath.Join(root, "app", "fixtures", "old", ".vcluster-version"), "0.1.0\n")
+	writeFile(t, filepath.Join(root, "app", "fixtures", "keep", ".vcluster-version"), "0.20.0\n")
+	writeFile(t, filepath.Join(root, "venv", ".vcluster-version"), "0.1.0\n")
+
+	pins, err := Find([]string{root}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, p := range pins {
+		rel, _ := filepath.Rel(root, p.Path)
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	want := []string{"app/fixtures/keep/.vcluster-version", "app/tmp/.vcluster-version"}
+	if len(paths) != len(want) || paths[0] != want[0] || paths[1] != want[1] {
+		t.Fatalf("Find() = %v, want %v", paths, want)
+	}
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob, subject string
+		match         bool
+	}{
+		{"*.log", "debug.log", true},
+		{"*.log", "logs/debug.log", false},
+		{"**/out", "a/b/out", true},
+		{"**/out", "out", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"file?.txt", "file1.txt", true},
+		{"[!a]x", "ax", false},
+		{"[!a]x", "bx", true},
+	}
+	for _, tt := range tests {
+		re := regexp.MustCompile("^" + globToRegexp(tt.glob) + "$")
+		if got := re.MatchString(tt.subject); got != tt.match {
+			t.Errorf("glob %q on %q: got %v, want %v", tt.glob, tt.subject, got, tt.match)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Package pins finds the vcluster versions pinned by the .vcluster-version
+// and .tool-versions files of a directory tree.
+package pins
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/user/vc-env/internal/config"
+)
+
+// skippedDirs are dependency directories, which never hold pins of their
+// own project.
+var skippedDirs = map[string]bool{
+	"bower_components": true,
+	"jspm_packages":    true,
+	"node_modules":     true,
+	"vendor":           true,
+	"venv":             true,
+}
+
+// Pin is a version file and the versions it lists.
+type Pin struct {
+	// Path is the path of the version file.
+	Path string
+
+	// Versions lists the pinned versions in order of preference; versions
+	// after the first are fallbacks.
+	Versions []string
+}
+
+// IsToolVersions reports whether the pin is a vcluster line of a
+// .tool-versions file.
+func (p Pin) IsToolVersions() bool {
+	return filepath.Base(p.Path) == config.ToolVersionsFileName
+}
+
+// Read returns the pin of the version file at path.  ok is false when the
+// file pins no version, e.g. a .tool-versions file without a vcluster line.
+func Read(path string) (Pin, bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Pin{}, false, err
+	}
+	var versions []string
+	if filepath.Base(path) == config.ToolVersionsFileName {
+		versions = config.ToolVersions(data)
+	} else {
+		versions = config.ParseVersionFile(data)
+	}
+	return Pin{Path: path, Versions: versions}, len(versions) > 0, nil
+}
+
+// IsVersionFile reports whether name is the name of a version file.
+func IsVersionFile(name string) bool {
+	return name == config.LocalVersionFileName || name == config.ToolVersionsFileName
+}
+
+// Find returns the pins of the version files in paths, sorted by path.  A
+// path may name a version file or a directory.  Directories are searched
+// recursively when recursive is true, skipping hidden directories,
+// dependency directories such as node_modules, and files and directories
+// ignored by the .gitignore files met on the way; otherwise only the version
+// files directly in them are read.
+func Find(paths []string, recursive bool) ([]Pin, error) {
+	var pins []Pin
+	add := func(path string) error {
+		pin, ok, err := Read(path)
+		if err != nil {
+			return err
+		}
+		if ok {
+			pins = append(pins, pin)
+		}
+		return nil
+	}
+
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			if err := add(root); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// rules holds the .gitignore rules of each directory walked into.
+		rules := map[string][]ignoreRule{}
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			inherited := rules[filepath.Dir(path)]
+			if d.IsDir() {
+				if path != root && (!recursive || strings.HasPrefix(d.Name(), ".") || skippedDirs[d.Name()] || ignored(inherited, path, true)) {
+					return filepath.SkipDir
+				}
+				if recursive {
+					rules[path] = append(inherited[:len(inherited):len(inherited)], readGitignore(path)...)
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() || !IsVersionFile(d.Name()) || ignored(inherited, path, false) {
+				return nil
+			}
+			return add(path)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(pins, func(i, j int) bool { return pins[i].Path < pins[j].Path })
+	return pins, nil
+}
+
+// Replace returns the content of the pin's version file with its preferred
+// version, the first one listed, replaced by version.  Fallback versions,
+// comments, other tools of a .tool-versions file and the layout of the file
+// are kept.
+func (p Pin) Replace(data []byte, version string) []byte {
+	if len(p.Versions) == 0 {
+		return data
+	}
+	old := p.Versions[0]
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		entry, comment, hasComment := strings.Cut(line, "#")
+		fields := strings.Fields(entry)
+		if p.IsToolVersions() {
+			if len(fields) < 2 || fields[0] != "vcluster" {
+				continue
+			}
+		} else if len(fields) == 0 {
+			continue
+		}
+		// The preferred version is the first one on the line; for
+		// .tool-versions it follows the tool name.
+		prefix := ""
+		if p.IsToolVersions() {
+			idx := strings.Index(entry, fields[0]) + len(fields[0])
+			prefix, entry = entry[:idx], entry[idx:]
+		}
+		entry = strings.Replace(entry, old, version, 1)
+		lines[i] = prefix + entry
+		if hasComment {
+			lines[i] += "#" + comment
+		}
+		break
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,80 @@
+package pins
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".vcluster-version"), "0.21.0\n")
+	writeFile(t, filepath.Join(root, "a", ".vcluster-version"), "# pinned\n0.20.1\n0.20.0\n")
+	writeFile(t, filepath.Join(root, "b", ".tool-versions"), "golang 1.24.4\nvcluster 0.19.7\n")
+	writeFile(t, filepath.Join(root, "c", ".tool-versions"), "golang 1.24.4\n")
+	writeFile(t, filepath.Join(root, "node_modules", "x", ".vcluster-version"), "0.1.0\n")
+	writeFile(t, filepath.Join(root, ".cache", ".vcluster-version"), "0.1.0\n")
+
+	pins, err := Find([]string{root}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Pin{
+		{Path: filepath.Join(root, ".vcluster-version"), Versions: []string{"0.21.0"}},
+		{Path: filepath.Join(root, "a", ".vcluster-version"), Versions: []string{"0.20.1", "0.20.0"}},
+		{Path: filepath.Join(root, "b", ".tool-versions"), Versions: []string{"0.19.7"}},
+	}
+	if !reflect.DeepEqual(pins, want) {
+		t.Fatalf("Find() = %+v, want %+v", pins, want)
+	}
+	if pins[0].IsToolVersions() || !pins[2].IsToolVersions() {
+		t.Fatal("unexpected IsToolVersions")
+	}
+
+	pins, err = Find([]string{root}, false)
+	if err != nil || len(pins) != 1 {
+		t.Fatalf("expected only the top-level pin, got %+v (%v)", pins, err)
+	}
+
+	pins, err = Find([]string{filepath.Join(root, "b", ".tool-versions")}, false)
+	if err != nil || len(pins) != 1 {
+		t.Fatalf("expected the named file, got %+v (%v)", pins, err)
+	}
+
+	if _, err := Find([]string{filepath.Join(root, "missing")}, true); err == nil {
+		t.Fatal("expected an error for a missing path")
+	}
+}
+
+func TestReplace(t *testing.T) {
+	tests := []struct {
+		path, content, want string
+	}{
+		{".vcluster-version", "0.21.0\n", "0.21.3\n"},
+		{".vcluster-version", "# pinned for prod\n0.21.0  # keep\n0.20.0\n", "# pinned for prod\n0.21.3  # keep\n0.20.0\n"},
+		{".tool-versions", "golang 1.24.4\nvcluster 0.21.0 0.20.0\n", "golang 1.24.4\nvcluster 0.21.3 0.20.0\n"},
+		{".tool-versions", "vcluster 0.21.0 # 0.21.0 is required\n", "vcluster 0.21.3 # 0.21.0 is required\n"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), tt.path)
+		writeFile(t, path, tt.content)
+		pin, ok, err := Read(path)
+		if err != nil || !ok {
+			t.Fatalf("Read(%s): %v", tt.content, err)
+		}
+		if got := string(pin.Replace([]byte(tt.content), "0.21.3")); got != tt.want {
+			t.Errorf("Replace(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
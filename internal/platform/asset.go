@@ -0,0 +1,141 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultAssetPattern is the release asset naming scheme used by vcluster
+// releases: vcluster-<os>-<arch>, with ".exe" on Windows.
+const DefaultAssetPattern = "vcluster-{os}-{arch}{ext}"
+
+// AssetPatterns returns the patterns used to select the vcluster binary from
+// a release's assets, in order of preference.  VCENV_ASSET_PATTERN may hold
+// a comma-separated list of patterns; each may use the placeholders {os},
+// {arch}, {version} (without the leading "v") and {ext} (".exe" on Windows,
+// empty otherwise), and "*" as a wildcard.
+func AssetPatterns() []string {
+	var patterns []string
+	for _, p := range strings.Split(os.Getenv("VCENV_ASSET_PATTERN"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	if len(patterns) == 0 {
+		return []string{DefaultAssetPattern}
+	}
+	return patterns
+}
+
+// ReleaseAssetPath returns the path part of the download URL of a named
+// release asset.
+func ReleaseAssetPath(version, name string) string {
+	return fmt.Sprintf("loft-sh/vcluster/releases/download/v%s/%s", version, name)
+}
+
+// MatchAsset returns the first asset name matching one of the asset
+// patterns for version and info.  Patterns are tried in order.
+func MatchAsset(names []string, version string, info Info) (string, bool) {
+	for _, pattern := range AssetPatterns() {
+		re := assetRegexp(pattern, version, &info)
+		for _, name := range names {
+			if re.MatchString(name) {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// AvailablePlatforms returns the "<os>/<arch>" platforms for which the asset
+// patterns match one of names, sorted.
+func AvailablePlatforms(names []string, version string) []string {
+	seen := map[string]bool{}
+	for _, pattern := range AssetPatterns() {
+		re := assetRegexp(pattern, version, nil)
+		osIdx, archIdx := re.SubexpIndex("os"), re.SubexpIndex("arch")
+		if osIdx < 0 || archIdx < 0 {
+			continue
+		}
+		for _, name := range names {
+			if m := re.FindStringSubmatch(name); m != nil {
+				seen[m[osIdx]+"/"+m[archIdx]] = true
+			}
+		}
+	}
+	platforms := make([]string, 0, len(seen))
+	for p := range seen {
+		platforms = append(platforms, p)
+	}
+	sort.Strings(platforms)
+	return platforms
+}
+
+// MatchChecksumAsset returns the name of the checksums file among a
+// release's assets: "checksums.txt" if present, otherwise the first asset
+// whose name suggests a SHA-256 checksum list.
+func MatchChecksumAsset(names []string) (string, bool) {
+	for _, name := range names {
+		if name == "checksums.txt" {
+			return name, true
+		}
+	}
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		if strings.HasSuffix(lower, ".sig") || strings.HasSuffix(lower, ".pem") {
+			continue
+		}
+		if strings.Contains(lower, "checksums") || strings.Contains(lower, "sha256sums") {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// assetRegexp compiles an asset pattern.  With info set, {os} and {arch}
+// match only that platform; otherwise they are captured as named groups.
+func assetRegexp(pattern, version string, info *Info) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); {
+		rest := pattern[i:]
+		switch {
+		case strings.HasPrefix(rest, "{os}"):
+			if info != nil {
+				b.WriteString(regexp.QuoteMeta(info.OS))
+			} else {
+				b.WriteString(`(?P<os>[A-Za-z0-9]+)`)
+			}
+			i += len("{os}")
+		case strings.HasPrefix(rest, "{arch}"):
+			if info != nil {
+				b.WriteString(regexp.QuoteMeta(info.Arch))
+			} else {
+				b.WriteString(`(?P<arch>[A-Za-z0-9_]+)`)
+			}
+			i += len("{arch}")
+		case strings.HasPrefix(rest, "{version}"):
+			b.WriteString(regexp.QuoteMeta(version))
+			i += len("{version}")
+		case strings.HasPrefix(rest, "{ext}"):
+			switch {
+			case info == nil:
+				b.WriteString(`(?:\.exe)?`)
+			case info.OS == "windows":
+				b.WriteString(`\.exe`)
+			}
+			i += len("{ext}")
+		case rest[0] == '*':
+			b.WriteString(".*")
+			i++
+		default:
+			b.WriteString(regexp.QuoteMeta(rest[:1]))
+			i++
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
@@ -0,0 +1,67 @@
+package platform
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchAsset(t *testing.T) {
+	names := []string{
+		"checksums.txt",
+		"vcluster-darwin-arm64",
+		"vcluster-linux-amd64",
+		"vcluster-windows-amd64.exe",
+		"vcluster_0.21.1_linux_arm64.tar.gz",
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		info    Info
+		want    string
+		wantOK  bool
+	}{
+		{name: "default", info: Info{OS: "linux", Arch: "amd64"}, want: "vcluster-linux-amd64", wantOK: true},
+		{name: "default windows", info: Info{OS: "windows", Arch: "amd64"}, want: "vcluster-windows-amd64.exe", wantOK: true},
+		{name: "default missing", info: Info{OS: "linux", Arch: "arm64"}},
+		{name: "custom", pattern: "vcluster_{version}_{os}_{arch}.tar.gz", info: Info{OS: "linux", Arch: "arm64"}, want: "vcluster_0.21.1_linux_arm64.tar.gz", wantOK: true},
+		{name: "fallback list", pattern: "nope-{os}, vcluster-{os}-{arch}*", info: Info{OS: "darwin", Arch: "arm64"}, want: "vcluster-darwin-arm64", wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("VCENV_ASSET_PATTERN", tt.pattern)
+			got, ok := MatchAsset(names, "0.21.1", tt.info)
+			if ok != tt.wantOK || got != tt.want {
+				t.Fatalf("MatchAsset = %q, %v; want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestAvailablePlatforms(t *testing.T) {
+	t.Setenv("VCENV_ASSET_PATTERN", "")
+	names := []string{"checksums.txt", "vcluster-linux-amd64", "vcluster-darwin-arm64", "vcluster-windows-amd64.exe", "README.md"}
+	got := strings.Join(AvailablePlatforms(names, "0.21.1"), ",")
+	if got != "darwin/arm64,linux/amd64,windows/amd64" {
+		t.Fatalf("unexpected platforms %s", got)
+	}
+}
+
+func TestMatchChecksumAsset(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  string
+	}{
+		{[]string{"vcluster-linux-amd64", "checksums.txt"}, "checksums.txt"},
+		{[]string{"vcluster_0.21.1_checksums.txt.sig", "vcluster_0.21.1_checksums.txt"}, "vcluster_0.21.1_checksums.txt"},
+		{[]string{"SHA256SUMS"}, "SHA256SUMS"},
+		{[]string{"vcluster-linux-amd64"}, ""},
+	}
+	for _, tt := range tests {
+		got, _ := MatchChecksumAsset(tt.names)
+		if got != tt.want {
+			t.Errorf("MatchChecksumAsset(%v) = %q, want %q", tt.names, got, tt.want)
+		}
+	}
+}
@@ -5,6 +5,7 @@ package platform
 import (
 	"fmt"
 	"runtime"
+	"strings"
 )
 
 // Info holds the detected platform information.
@@ -50,12 +51,33 @@ func mapArch(goarch string) (string, error) {
 	}
 }
 
+// Parse parses a platform in "<os>/<arch>" form (e.g. "linux/amd64"), using
+// the same validation as Detect.
+func Parse(s string) (Info, error) {
+	parts := strings.Split(strings.TrimSpace(s), "/")
+	if len(parts) != 2 {
+		return Info{}, fmt.Errorf("invalid platform %q: expected <os>/<arch>", s)
+	}
+	osName, err := mapOS(parts[0])
+	if err != nil {
+		return Info{}, err
+	}
+	archName, err := mapArch(parts[1])
+	if err != nil {
+		return Info{}, err
+	}
+	return Info{OS: osName, Arch: archName}, nil
+}
+
 // DownloadPath returns the path part of the GitHub release download URL.
 func DownloadPath(version string, info Info) string {
-	return fmt.Sprintf(
-		"loft-sh/vcluster/releases/download/v%s/%s",
-		version, BinaryName(info),
-	)
+	return ReleaseAssetPath(version, BinaryName(info))
+}
+
+// Key returns the "<os>-<arch>" identifier used to key per-platform storage
+// such as the download cache.
+func Key(info Info) string {
+	return info.OS + "-" + info.Arch
 }
 
 // BinaryName returns the vcluster binary name for the given platform.
@@ -69,8 +91,5 @@ func BinaryName(info Info) string {
 
 // ChecksumPath returns the path part of the URL for the checksums.txt file.
 func ChecksumPath(version string) string {
-	return fmt.Sprintf(
-		"loft-sh/vcluster/releases/download/v%s/checksums.txt",
-		version,
-	)
+	return ReleaseAssetPath(version, "checksums.txt")
 }
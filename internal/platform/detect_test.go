@@ -67,3 +67,35 @@ func TestDownloadPath(t *testing.T) {
 		})
 	}
 }
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Info
+		wantErr  bool
+	}{
+		{input: "linux/amd64", expected: Info{OS: "linux", Arch: "amd64"}},
+		{input: "darwin/arm64", expected: Info{OS: "darwin", Arch: "arm64"}},
+		{input: "plan9/amd64", wantErr: true},
+		{input: "linux", wantErr: true},
+		{input: "linux-amd64", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			info, err := Parse(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if info != tt.expected {
+				t.Fatalf("expected %+v, got %+v", tt.expected, info)
+			}
+		})
+	}
+}
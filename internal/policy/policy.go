@@ -0,0 +1,264 @@
+// Package policy decides which vcluster versions may be used, according to a
+// policy file maintained by an organisation's security team.
+//
+// A policy is a JSON document:
+//
+//	{
+//	  "mode": "enforce",
+//	  "allowed": [">=0.20.0"],
+//	  "denied": [
+//	    {"version": "0.19.2", "reason": "CVE-XXXX"},
+//	    {"version": ">=0.21.0 <0.21.3", "reason": "leaks kubeconfig"}
+//	  ]
+//	}
+//
+// Both lists hold version constraints as accepted by semver.ParseConstraint,
+// so an entry may name one version or a range.  A version is denied when it
+// matches a denied entry, or when allowed is not empty and it matches none
+// of its entries.  Only release versions are checked; custom builds with
+// names such as "dev-1a2b3c" and "system" are not.
+//
+// In "enforce" mode (the default) denied versions are refused; in "warn"
+// mode they are reported but may still be used.
+//
+// The policy is read from a local file or fetched from an http(s) URL.
+// Fetched policies are cached in $VCENV_ROOT/cache/policy.json and fetched
+// again once older than VCENV_POLICY_TTL (default 1 h); when a fetch fails
+// the cached policy is used.
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/user/vc-env/internal/cache"
+	"github.com/user/vc-env/internal/semver"
+)
+
+// Policy modes.
+const (
+	ModeEnforce = "enforce"
+	ModeWarn    = "warn"
+)
+
+const (
+	// cacheFileName is the name of the JSON file stored under
+	// $VCENV_ROOT/cache/ that holds the last fetched policy.
+	cacheFileName = "policy.json"
+
+	// defaultTTL is how long a fetched policy is used before it is fetched
+	// again.
+	defaultTTL = time.Hour
+
+	// fetchTimeout keeps an unreachable policy URL from stalling the
+	// command or shim that checks the policy.
+	fetchTimeout = 5 * time.Second
+
+	// maxPolicySize bounds the response read from a policy URL.
+	maxPolicySize = 1 << 20
+)
+
+// Denial is a denied version constraint and the reason it is denied.
+type Denial struct {
+	Version string `json:"version"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Policy lists the allowed and denied versions.
+type Policy struct {
+	Mode    string   `json:"mode,omitempty"`
+	Allowed []string `json:"allowed,omitempty"`
+	Denied  []Denial `json:"denied,omitempty"`
+
+	// Source is the file or URL the policy was read from.
+	Source string `json:"-"`
+
+	allowed []semver.Constraint
+	denied  []semver.Constraint
+}
+
+// Violation describes why a version is not allowed by a policy.
+type Violation struct {
+	Version string
+	Reason  string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("version %s is denied by policy: %s", v.Version, v.Reason)
+}
+
+// Parse parses and validates the content of a policy file.
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	switch p.Mode {
+	case "":
+		p.Mode = ModeEnforce
+	case ModeEnforce, ModeWarn:
+	default:
+		return nil, fmt.Errorf("invalid policy: unknown mode %q (expected %q or %q)", p.Mode, ModeEnforce, ModeWarn)
+	}
+	for _, a := range p.Allowed {
+		c, err := semver.ParseConstraint(a)
+		if err != nil {
+			return nil, fmt.Errorf("invalid policy: %w", err)
+		}
+		p.allowed = append(p.allowed, c)
+	}
+	for _, d := range p.Denied {
+		c, err := semver.ParseConstraint(d.Version)
+		if err != nil {
+			return nil, fmt.Errorf("invalid policy: %w", err)
+		}
+		p.denied = append(p.denied, c)
+	}
+	return &p, nil
+}
+
+// Enforced reports whether denied versions must be refused rather than
+// only reported.
+func (p *Policy) Enforced() bool {
+	return p.Mode != ModeWarn
+}
+
+// Check returns a Violation if version is denied, or nil if it may be used.
+func (p *Policy) Check(version string) *Violation {
+	if !semver.IsValid(version) {
+		return nil
+	}
+	v := semver.Parse(version)
+	for i, c := range p.denied {
+		if c.Check(v) {
+			reason := p.Denied[i].Reason
+			if reason == "" {
+				reason = "denied"
+			}
+			return &Violation{Version: version, Reason: reason}
+		}
+	}
+	if len(p.allowed) == 0 {
+		return nil
+	}
+	for _, c := range p.allowed {
+		if c.Check(v) {
+			return nil
+		}
+	}
+	return &Violation{Version: version, Reason: "not in the allowed versions " + strings.Join(p.Allowed, ", ")}
+}
+
+// Load reads the policy at source, a file path or an http(s) URL.  Fetched
+// policies are cached in cacheDir; offline, only the cached policy is used.
+func Load(source, cacheDir string, offline bool) (*Policy, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy: %w", err)
+		}
+		return parseFrom(data, source)
+	}
+
+	cached, ok := loadCache(cacheDir, source)
+	if ok && (offline || time.Since(cached.FetchedAt) <= ttl()) {
+		return parseFrom(cached.Data, source)
+	}
+	if offline {
+		return nil, fmt.Errorf("policy %s has not been fetched yet and vc-env is offline", source)
+	}
+	data, err := fetch(source)
+	if err != nil {
+		if ok {
+			return parseFrom(cached.Data, source)
+		}
+		return nil, err
+	}
+	p, err := parseFrom(data, source)
+	if err != nil {
+		return nil, err
+	}
+	// The policy applies even if it cannot be cached.
+	_ = saveCache(cacheDir, cacheEntry{URL: source, Data: data, FetchedAt: time.Now().UTC()})
+	return p, nil
+}
+
+// parseFrom parses the policy read from source.
+func parseFrom(data []byte, source string) (*Policy, error) {
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("policy %s: %w", source, err)
+	}
+	p.Source = source
+	return p, nil
+}
+
+// ttl returns the maximum age of a fetched policy, read from
+// VCENV_POLICY_TTL.
+func ttl() time.Duration {
+	if raw := os.Getenv("VCENV_POLICY_TTL"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil {
+			return d
+		}
+	}
+	return defaultTTL
+}
+
+// fetch downloads the policy at url.
+func fetch(url string) ([]byte, error) {
+	client := &http.Client{Timeout: fetchTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch policy %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch policy %s: HTTP %d", url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPolicySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy %s: %w", url, err)
+	}
+	return data, nil
+}
+
+// cacheEntry is the on-disk JSON structure for the cache file.
+type cacheEntry struct {
+	URL       string          `json:"url"`
+	Data      json.RawMessage `json:"data"`
+	FetchedAt time.Time       `json:"fetched_at"`
+}
+
+// loadCache reads the cached policy fetched from url, regardless of its age.
+func loadCache(dir, url string) (cacheEntry, bool) {
+	var e cacheEntry
+	if dir == "" {
+		return e, false
+	}
+	data, err := os.ReadFile(filepath.Join(dir, cacheFileName))
+	if err != nil {
+		return e, false
+	}
+	if err := json.Unmarshal(data, &e); err != nil || e.URL != url {
+		return cacheEntry{}, false
+	}
+	return e, true
+}
+
+// saveCache writes e to the cache file atomically.
+func saveCache(dir string, e cacheEntry) error {
+	if dir == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	return cache.WriteFileAtomic(filepath.Join(dir, cacheFileName), data, 0o644)
+}
@@ -0,0 +1,113 @@
+package policy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testPolicy = `{
+  "allowed": [">=0.20.0"],
+  "denied": [
+    {"version": "0.21.1", "reason": "CVE-2026-0001"},
+    {"version": ">=0.22.0 <0.22.2"}
+  ]
+}`
+
+func TestParse(t *testing.T) {
+	p, err := Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !p.Enforced() {
+		t.Fatal("expected enforce mode by default")
+	}
+
+	for _, data := range []string{
+		`{"mode": "audit"}`,
+		`{"allowed": [">>0.20"]}`,
+		`{"denied": [{"version": ""}]}`,
+		`not json`,
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%s): expected an error", data)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	p, err := Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		version string
+		reason  string
+	}{
+		{"0.21.0", ""},
+		{"0.21.1", "CVE-2026-0001"},
+		{"0.22.1", "denied"},
+		{"0.22.2", ""},
+		{"0.19.5", "not in the allowed versions >=0.20.0"},
+		{"dev-1a2b3c", ""},
+		{"system", ""},
+	}
+	for _, tt := range tests {
+		v := p.Check(tt.version)
+		switch {
+		case tt.reason == "" && v != nil:
+			t.Errorf("Check(%s) = %v, want allowed", tt.version, v)
+		case tt.reason != "" && (v == nil || v.Reason != tt.reason):
+			t.Errorf("Check(%s) = %v, want reason %q", tt.version, v, tt.reason)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("reads a local file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "policy.json")
+		if err := os.WriteFile(path, []byte(`{"mode": "warn", "denied": [{"version": "0.19.2"}]}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		p, err := Load(path, "", false)
+		if err != nil || p.Enforced() || p.Source != path {
+			t.Fatalf("unexpected policy %+v (%v)", p, err)
+		}
+	})
+
+	t.Run("caches a fetched policy", func(t *testing.T) {
+		failing := false
+		hits := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits++
+			if failing {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(testPolicy))
+		}))
+		defer server.Close()
+		cacheDir := t.TempDir()
+		url := server.URL + "/policy.json"
+
+		if _, err := Load(url, cacheDir, true); err == nil {
+			t.Fatal("expected an error offline without a cached policy")
+		}
+		t.Setenv("VCENV_POLICY_TTL", "1h")
+		if p, err := Load(url, cacheDir, false); err != nil || p.Check("0.21.1") == nil {
+			t.Fatalf("unexpected policy %+v (%v)", p, err)
+		}
+		if _, err := Load(url, cacheDir, false); err != nil || hits != 1 {
+			t.Fatalf("expected the cached policy to be used, got %d fetches (%v)", hits, err)
+		}
+
+		// A stale policy is fetched again, and used when the fetch fails.
+		t.Setenv("VCENV_POLICY_TTL", "0s")
+		failing = true
+		if p, err := Load(url, cacheDir, false); err != nil || hits != 2 || p.Check("0.21.1") == nil {
+			t.Fatalf("expected the stale policy after a failed fetch, got %+v after %d fetches (%v)", p, hits, err)
+		}
+	})
+}
@@ -0,0 +1,239 @@
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// strictPattern matches MAJOR.MINOR.PATCH with an optional leading "v" and
+// an optional pre-release suffix.
+var strictPattern = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(-[0-9A-Za-z.-]+)?$`)
+
+// IsValid reports whether s is a well-formed semantic version.  Unlike
+// Parse, it rejects leading zeros, missing components and stray characters.
+func IsValid(s string) bool {
+	return strictPattern.MatchString(s)
+}
+
+// Compare returns -1, 0 or +1 depending on whether v is less than, equal to,
+// or greater than w in semver precedence.
+func Compare(v, w Version) int {
+	switch {
+	case Less(v, w):
+		return -1
+	case Less(w, v):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// comparison is a single "<op> <version>" term of a constraint.
+type comparison struct {
+	op      string
+	version Version
+}
+
+// matches reports whether v satisfies the comparison.
+func (c comparison) matches(v Version) bool {
+	cmp := Compare(v, c.version)
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	}
+	return false
+}
+
+// Constraint is a set of version ranges.  A version satisfies the
+// constraint if it satisfies every comparison of at least one range.
+type Constraint struct {
+	ranges   [][]comparison
+	original string
+}
+
+// String returns the constraint as it was written.
+func (c Constraint) String() string {
+	return c.original
+}
+
+// Check reports whether v satisfies the constraint.
+func (c Constraint) Check(v Version) bool {
+	for _, r := range c.ranges {
+		ok := true
+		for _, cmp := range r {
+			if !cmp.matches(v) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseConstraint parses a version constraint.  Supported forms:
+//
+//	1.2.3, =1.2.3, !=1.2.3       exact match / exclusion
+//	>1.2.3, >=1.2.3, <1.2.3, <=1.2.3
+//	~1.2.3, ~1.2                 >=1.2.3 <1.3.0
+//	^1.2.3                       >=1.2.3 <2.0.0 (<0.3.0 for 0.x versions)
+//	1.2, 1.2.x, 1.2.*            >=1.2.0 <1.3.0
+//	*                            any version
+//
+// Terms separated by commas or whitespace must all match; "||" separates
+// alternative ranges.  Whitespace between an operator and its version is
+// allowed (">= 0.19.0, < 0.19.5", as used by GitHub security advisories).
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{original: strings.TrimSpace(s)}
+	if c.original == "" {
+		return c, fmt.Errorf("empty version constraint")
+	}
+
+	for _, alt := range strings.Split(s, "||") {
+		var r []comparison
+		for _, term := range splitTerms(alt) {
+			cmps, err := parseTerm(term)
+			if err != nil {
+				return c, fmt.Errorf("invalid version constraint %q: %w", s, err)
+			}
+			r = append(r, cmps...)
+		}
+		if len(r) == 0 {
+			return c, fmt.Errorf("invalid version constraint %q: empty range", s)
+		}
+		c.ranges = append(c.ranges, r)
+	}
+	return c, nil
+}
+
+// splitTerms splits a range into terms, joining operators that are separated
+// from their version by whitespace.
+func splitTerms(s string) []string {
+	fields := strings.Fields(strings.ReplaceAll(s, ",", " "))
+	var terms []string
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		if strings.Trim(f, "<>=!~^") == "" && i+1 < len(fields) {
+			f += fields[i+1]
+			i++
+		}
+		terms = append(terms, f)
+	}
+	return terms
+}
+
+// parseTerm expands a single term into one or two comparisons.
+func parseTerm(term string) ([]comparison, error) {
+	if term == "*" || term == "x" || term == "X" {
+		return []comparison{{op: ">=", version: Version{}}}, nil
+	}
+
+	op := ""
+	for _, candidate := range []string{">=", "<=", "!=", ">", "<", "=", "~", "^"} {
+		if strings.HasPrefix(term, candidate) {
+			op = candidate
+			break
+		}
+	}
+	raw := strings.TrimPrefix(strings.TrimPrefix(term, op), "v")
+
+	major, minor, patch, pre, parts, err := parsePartial(raw)
+	if err != nil {
+		return nil, err
+	}
+	v := Version{Major: major, Minor: minor, Patch: patch, PreRelease: pre, Original: raw}
+
+	// upper returns the exclusive upper bound for a partial version.
+	upper := func() Version {
+		switch parts {
+		case 1:
+			return Version{Major: major + 1}
+		default:
+			return Version{Major: major, Minor: minor + 1}
+		}
+	}
+
+	switch op {
+	case "", "=":
+		if parts < 3 {
+			return []comparison{{">=", v}, {"<", upper()}}, nil
+		}
+		return []comparison{{"=", v}}, nil
+	case "~":
+		if parts == 1 {
+			return []comparison{{">=", v}, {"<", Version{Major: major + 1}}}, nil
+		}
+		return []comparison{{">=", v}, {"<", Version{Major: major, Minor: minor + 1}}}, nil
+	case "^":
+		switch {
+		case major > 0 || parts == 1:
+			return []comparison{{">=", v}, {"<", Version{Major: major + 1}}}, nil
+		case minor > 0 || parts == 2:
+			return []comparison{{">=", v}, {"<", Version{Minor: minor + 1}}}, nil
+		default:
+			return []comparison{{">=", v}, {"<", Version{Patch: patch + 1}}}, nil
+		}
+	case ">", "<=":
+		if parts < 3 {
+			// ">1.2" means ">=1.3.0"; "<=1.2" means "<1.3.0".
+			if op == ">" {
+				return []comparison{{">=", upper()}}, nil
+			}
+			return []comparison{{"<", upper()}}, nil
+		}
+	}
+	return []comparison{{op, v}}, nil
+}
+
+// parsePartial parses "1", "1.2", "1.2.x", "1.2.3" or "1.2.3-pre" and
+// returns the number of numeric components given.
+func parsePartial(s string) (major, minor, patch int, pre string, parts int, err error) {
+	if s == "" {
+		return 0, 0, 0, "", 0, fmt.Errorf("missing version")
+	}
+	core := s
+	if i := strings.Index(s, "-"); i >= 0 {
+		core, pre = s[:i], s[i+1:]
+	}
+
+	nums := strings.Split(core, ".")
+	if len(nums) > 3 {
+		return 0, 0, 0, "", 0, fmt.Errorf("invalid version %q", s)
+	}
+	values := [3]int{}
+	for i, n := range nums {
+		if n == "x" || n == "X" || n == "*" {
+			if pre != "" {
+				return 0, 0, 0, "", 0, fmt.Errorf("invalid version %q", s)
+			}
+			break
+		}
+		value, convErr := strconv.Atoi(n)
+		if convErr != nil || value < 0 {
+			return 0, 0, 0, "", 0, fmt.Errorf("invalid version %q", s)
+		}
+		values[i] = value
+		parts = i + 1
+	}
+	if parts == 0 {
+		return 0, 0, 0, "", 0, fmt.Errorf("invalid version %q", s)
+	}
+	if pre != "" && parts < 3 {
+		return 0, 0, 0, "", 0, fmt.Errorf("invalid version %q", s)
+	}
+	return values[0], values[1], values[2], pre, parts, nil
+}
@@ -0,0 +1,84 @@
+package semver
+
+import "testing"
+
+func TestIsValid(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"0.21.1", true},
+		{"v0.21.1", true},
+		{"0.22.0-beta.0", true},
+		{"0.21", false},
+		{"0.21.01", false},
+		{"0.21.1.2", false},
+		{"latest", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := IsValid(tt.input); got != tt.want {
+				t.Fatalf("IsValid(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{"0.21.1", "0.21.1", true},
+		{"0.21.1", "0.21.2", false},
+		{"=0.21.1", "0.21.1", true},
+		{"!=0.21.1", "0.21.1", false},
+		{">=0.19.0", "0.19.0", true},
+		{">=0.19.0", "0.18.9", false},
+		{">= 0.19.0, < 0.19.5", "0.19.4", true},
+		{">= 0.19.0, < 0.19.5", "0.19.5", false},
+		{">0.21", "0.21.9", false},
+		{">0.21", "0.22.0", true},
+		{"<=0.21", "0.21.9", true},
+		{"<=0.21", "0.22.0", false},
+		{"~0.21.1", "0.21.3", true},
+		{"~0.21.1", "0.22.0", false},
+		{"~0.21.1", "0.21.0", false},
+		{"^0.21.1", "0.21.9", true},
+		{"^0.21.1", "0.22.0", false},
+		{"^1.2.3", "1.9.0", true},
+		{"^1.2.3", "2.0.0", false},
+		{"0.21.x", "0.21.7", true},
+		{"0.21.*", "0.22.0", false},
+		{"0.21", "0.21.0", true},
+		{"*", "0.4.0", true},
+		{"<0.20.0 || >=0.22.0", "0.21.0", false},
+		{"<0.20.0 || >=0.22.0", "0.22.1", true},
+		{"<0.20.0 || >=0.22.0", "0.19.7", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint+"_"+tt.version, func(t *testing.T) {
+			c, err := ParseConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("ParseConstraint(%q): %v", tt.constraint, err)
+			}
+			if got := c.Check(Parse(tt.version)); got != tt.want {
+				t.Fatalf("%q.Check(%q) = %v, want %v", tt.constraint, tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConstraint_Invalid(t *testing.T) {
+	for _, input := range []string{"", ">=", "abc", "1.2.3.4", "~x", "1.2-beta"} {
+		t.Run(input, func(t *testing.T) {
+			if _, err := ParseConstraint(input); err == nil {
+				t.Fatalf("expected error for %q", input)
+			}
+		})
+	}
+}
@@ -9,7 +9,8 @@ import (
 )
 
 // GenerateShimScript creates the vcluster shim script at $VCENV_ROOT/shims/vcluster.
-// The shim resolves the correct vcluster version and executes it.
+// The shim asks "vc-env which --shim" for the binary of the active version
+// and executes it.
 func GenerateShimScript(vcenvRoot string) error {
 	shimsDir := filepath.Join(vcenvRoot, "shims")
 	if err := os.MkdirAll(shimsDir, 0o755); err != nil {
@@ -22,49 +23,16 @@ func GenerateShimScript(vcenvRoot string) error {
 set -e
 
 VCENV_ROOT="%s"
+export VCENV_ROOT
 
-# Resolve version: VCENV_VERSION > .vcluster-version (walk up) > $VCENV_ROOT/version
-resolve_version() {
-    # 1. Check VCENV_VERSION env var (shell version)
-    if [ -n "$VCENV_VERSION" ]; then
-        echo "$VCENV_VERSION"
-        return
-    fi
-
-    # 2. Walk up directories looking for .vcluster-version (local version)
-    local dir="$PWD"
-    while true; do
-        if [ -f "$dir/.vcluster-version" ]; then
-            cat "$dir/.vcluster-version"
-            return
-        fi
-        local parent
-        parent="$(dirname "$dir")"
-        if [ "$parent" = "$dir" ]; then
-            break
-        fi
-        dir="$parent"
-    done
-
-    # 3. Check global version
-    if [ -f "$VCENV_ROOT/version" ]; then
-        cat "$VCENV_ROOT/version"
-        return
-    fi
-
-    echo "vc-env: no vcluster version configured" >&2
-    echo "Set a version using 'vc-env shell', 'vc-env local', or 'vc-env global'" >&2
-    exit 1
-}
-
-VERSION="$(resolve_version)"
-BINARY="$VCENV_ROOT/versions/$VERSION/vcluster"
-
-if [ ! -x "$BINARY" ]; then
-    echo "vc-env: version $VERSION is not installed" >&2
-    echo "Install it with: vc-env install $VERSION" >&2
+# The version is resolved by vc-env itself, from VCENV_VERSION,
+# .vcluster-version and the other sources in the order set by
+# VCENV_RESOLVE_ORDER, so that the shim and "vc-env which" always agree.
+if ! command -v vc-env >/dev/null 2>&1; then
+    echo "vc-env: vc-env was not found in PATH" >&2
     exit 1
 fi
+BINARY="$(vc-env which --shim)"
 
 exec "$BINARY" "$@"
 `, vcenvRoot)
@@ -2,6 +2,7 @@ package shim
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -46,6 +47,9 @@ func TestGenerateShimScript(t *testing.T) {
 		if !strings.Contains(content, ".vcluster-version") {
 			t.Fatal("shim should check .vcluster-version")
 		}
+		if !strings.Contains(content, "vc-env which --shim") {
+			t.Fatal("shim should resolve the binary through vc-env")
+		}
 		if !strings.Contains(content, "exec") {
 			t.Fatal("shim should exec the binary")
 		}
@@ -102,3 +106,129 @@ func TestGenerateShellInit(t *testing.T) {
 		}
 	})
 }
+
+// setupShim generates a shim in a new VCENV_ROOT, installs the given fake
+// versions into it and builds vc-env, which the shim delegates to.  It
+// returns the root and a PATH with vc-env and the shims directory.
+func setupShim(t *testing.T, versions ...string) (string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not available")
+	}
+
+	binDir := t.TempDir()
+	build := exec.Command("go", "build", "-o", filepath.Join(binDir, "vc-env"), "github.com/user/vc-env/cmd/vc-env")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("failed to build vc-env: %v\n%s", err, out)
+	}
+
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "versions"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := GenerateShimScript(root); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range versions {
+		dir := filepath.Join(root, "versions", v)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		script := "#!/bin/sh\necho \"vcluster " + v + "\"\n"
+		if err := os.WriteFile(filepath.Join(dir, "vcluster"), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root, filepath.Join(root, "shims") + ":" + binDir + ":/usr/bin:/bin"
+}
+
+// runShim runs the vcluster shim of root in dir with the given environment.
+func runShim(root, dir string, env ...string) (string, error) {
+	cmd := exec.Command(filepath.Join(root, "shims", "vcluster"), "version")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "VCENV_VERSION=", "VCENV_RESOLVE_ORDER=", "VCENV_RESOLVE_HELM=")
+	cmd.Env = append(cmd.Env, env...)
+	out, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
+
+func TestShimScript_SystemVersion(t *testing.T) {
+	root, path := setupShim(t)
+
+	systemDir := t.TempDir()
+	script := "#!/bin/sh\necho \"system vcluster $*\"\n"
+	if err := os.WriteFile(filepath.Join(systemDir, "vcluster"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// The shims directory comes first on PATH, as after "vc-env init".
+	out, err := runShim(root, t.TempDir(), "VCENV_VERSION=system", "PATH="+path+":"+systemDir)
+	if err != nil {
+		t.Fatalf("shim failed: %v\n%s", err, out)
+	}
+	if out != "system vcluster version" {
+		t.Fatalf("expected the system binary to run, got %q", out)
+	}
+
+	out, err = runShim(root, t.TempDir(), "VCENV_VERSION=system", "PATH="+path)
+	if err == nil || !strings.Contains(out, "no vcluster was found in PATH") {
+		t.Fatalf("expected an error without a system binary, got %v: %q", err, out)
+	}
+}
+
+func TestShimScript_ToolVersions(t *testing.T) {
+	root, path := setupShim(t, "0.21.1", "0.22.0")
+
+	project := t.TempDir()
+	child := filepath.Join(project, "child")
+	if err := os.MkdirAll(child, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, ".tool-versions"), []byte("golang 1.24.4\n# vcluster 0.20.0\nvcluster 0.21.1 0.20.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// An empty .vcluster-version and a .tool-versions without vcluster are
+	// skipped.
+	if err := os.WriteFile(filepath.Join(child, ".vcluster-version"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(child, ".tool-versions"), []byte("kubectl 1.30.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if out, err := runShim(root, child, "PATH="+path); err != nil || out != "vcluster 0.21.1" {
+		t.Fatalf("expected 0.21.1 from .tool-versions, got %q (%v)", out, err)
+	}
+
+	// .vcluster-version takes precedence in the same directory.
+	if err := os.WriteFile(filepath.Join(project, ".vcluster-version"), []byte("0.22.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := runShim(root, child, "PATH="+path); err != nil || out != "vcluster 0.22.0" {
+		t.Fatalf("expected 0.22.0 from .vcluster-version, got %q (%v)", out, err)
+	}
+}
+
+func TestShimScript_ResolveOrder(t *testing.T) {
+	root, path := setupShim(t, "0.21.1", "0.22.0")
+	if err := os.WriteFile(filepath.Join(root, "version"), []byte("0.21.1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	if out, err := runShim(root, dir, "PATH="+path, "VCENV_VERSION=0.22.0"); err != nil || out != "vcluster 0.22.0" {
+		t.Fatalf("expected VCENV_VERSION to win by default, got %q (%v)", out, err)
+	}
+	// CI can ignore the VCENV_VERSION inherited from a developer shell.
+	if out, err := runShim(root, dir, "PATH="+path, "VCENV_VERSION=0.22.0", "VCENV_RESOLVE_ORDER=version-file,global"); err != nil || out != "vcluster 0.21.1" {
+		t.Fatalf("expected the global version, got %q (%v)", out, err)
+	}
+
+	out, err := runShim(root, dir, "PATH="+path, "VCENV_VERSION=0.30.0")
+	if err == nil || !strings.Contains(out, "version 0.30.0 is not installed") {
+		t.Fatalf("expected a not-installed error, got %v: %q", err, out)
+	}
+}
@@ -0,0 +1,165 @@
+// Package support classifies vcluster versions against a support window of
+// minor release lines.
+//
+// The window is given by VCENV_SUPPORT_WINDOW, either as a number N, meaning
+// the latest N minor lines known to vc-env are supported (the default is 3),
+// or as the oldest supported minor line, e.g. "0.20".  The minor line just
+// below the window is deprecated, and older lines are end-of-life (EOL).
+package support
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/user/vc-env/internal/semver"
+)
+
+// Version statuses.
+const (
+	Supported  = "supported"
+	Deprecated = "deprecated"
+	EOL        = "eol"
+)
+
+// DefaultLines is the number of minor lines supported when no window is
+// configured.
+const DefaultLines = 3
+
+// Line is a minor release line, e.g. 0.21.
+type Line struct {
+	Major int
+	Minor int
+}
+
+// LineOf returns the minor line of version, and false if version is not a
+// semantic version.
+func LineOf(version string) (Line, bool) {
+	if !semver.IsValid(version) {
+		return Line{}, false
+	}
+	v := semver.Parse(version)
+	return Line{Major: v.Major, Minor: v.Minor}, true
+}
+
+// String returns the line as "MAJOR.MINOR".
+func (l Line) String() string {
+	return fmt.Sprintf("%d.%d", l.Major, l.Minor)
+}
+
+// less reports whether l is older than m.
+func (l Line) less(m Line) bool {
+	if l.Major != m.Major {
+		return l.Major < m.Major
+	}
+	return l.Minor < m.Minor
+}
+
+// Window is a support window computed from the known releases.
+type Window struct {
+	// Oldest is the oldest supported minor line.
+	Oldest Line
+
+	// Deprecated is the minor line below Oldest; it is only meaningful when
+	// HasDeprecated is true.
+	Deprecated    Line
+	HasDeprecated bool
+
+	// lines lists the known minor lines, newest first.
+	lines []Line
+
+	// newest maps each known minor line to its newest release.
+	newest map[Line]string
+}
+
+// NewWindow computes the support window described by spec, as in
+// VCENV_SUPPORT_WINDOW, from the known stable releases.  An empty spec
+// supports the latest DefaultLines minor lines.
+func NewWindow(spec string, releases []string) (*Window, error) {
+	w := &Window{newest: map[Line]string{}}
+	for _, r := range semver.SortDescending(releases) {
+		l, ok := LineOf(r)
+		if !ok || semver.Parse(r).PreRelease != "" {
+			continue
+		}
+		if _, seen := w.newest[l]; !seen {
+			w.newest[l] = r
+			w.lines = append(w.lines, l)
+		}
+	}
+
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		spec = strconv.Itoa(DefaultLines)
+	}
+	if n, err := strconv.Atoi(spec); err == nil {
+		if n < 1 {
+			return nil, fmt.Errorf("invalid support window %q: at least one minor line must be supported", spec)
+		}
+		if len(w.lines) == 0 {
+			return nil, fmt.Errorf("no known releases to derive the support window from")
+		}
+		w.Oldest = w.lines[min(n, len(w.lines))-1]
+	} else {
+		l, ok := LineOf(strings.TrimPrefix(spec, "v") + ".0")
+		if !ok {
+			return nil, fmt.Errorf("invalid support window %q: expected a number of minor lines or a minor version such as 0.20", spec)
+		}
+		w.Oldest = l
+	}
+
+	for _, l := range w.lines {
+		if l.less(w.Oldest) {
+			w.Deprecated, w.HasDeprecated = l, true
+			break
+		}
+	}
+	return w, nil
+}
+
+// Classify returns the status of version, and false if version is not a
+// semantic version, such as a custom build.
+func (w *Window) Classify(version string) (string, bool) {
+	l, ok := LineOf(version)
+	switch {
+	case !ok:
+		return "", false
+	case !l.less(w.Oldest):
+		return Supported, true
+	case w.HasDeprecated && l == w.Deprecated:
+		return Deprecated, true
+	default:
+		return EOL, true
+	}
+}
+
+// UpgradeTarget returns the version to upgrade version to: the newest
+// release of its own line while that line is supported, or else the newest
+// release of the next known line that is supported, which is the smallest
+// upgrade that is supported again.  It returns "" if version needs no
+// upgrade or no suitable release is known.
+func (w *Window) UpgradeTarget(version string) string {
+	status, ok := w.Classify(version)
+	if !ok {
+		return ""
+	}
+	l, _ := LineOf(version)
+	if status != Supported {
+		next, found := Line{}, false
+		for _, known := range w.lines {
+			if known.less(w.Oldest) {
+				break
+			}
+			next, found = known, true
+		}
+		if !found {
+			return ""
+		}
+		l = next
+	}
+	target, ok := w.newest[l]
+	if !ok || !semver.Less(semver.Parse(version), semver.Parse(target)) {
+		return ""
+	}
+	return target
+}
@@ -0,0 +1,91 @@
+package support
+
+import "testing"
+
+var releases = []string{"0.22.1", "0.22.0", "0.21.3", "0.21.0", "0.20.2", "0.19.7", "0.15.3", "0.23.0-alpha.1"}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		spec    string
+		version string
+		status  string
+	}{
+		{"", "0.22.0", Supported},
+		{"", "0.20.0", Supported},
+		{"", "0.19.7", Deprecated},
+		{"", "0.15.3", EOL},
+		{"", "0.23.0-alpha.1", Supported},
+		{"2", "0.20.2", Deprecated},
+		{"2", "0.19.7", EOL},
+		{"0.19", "0.19.7", Supported},
+		{"0.19", "0.15.3", Deprecated},
+		{"10", "0.15.3", Supported},
+	}
+	for _, tt := range tests {
+		w, err := NewWindow(tt.spec, releases)
+		if err != nil {
+			t.Fatalf("NewWindow(%q): %v", tt.spec, err)
+		}
+		if status, ok := w.Classify(tt.version); !ok || status != tt.status {
+			t.Errorf("window %q: Classify(%s) = %s, want %s", tt.spec, tt.version, status, tt.status)
+		}
+	}
+
+	w, _ := NewWindow("", releases)
+	if _, ok := w.Classify("dev-1a2b3c"); ok {
+		t.Error("expected custom builds not to be classified")
+	}
+}
+
+func TestNewWindowErrors(t *testing.T) {
+	for _, spec := range []string{"0", "-1", "latest", "0.x"} {
+		if _, err := NewWindow(spec, releases); err == nil {
+			t.Errorf("NewWindow(%q): expected an error", spec)
+		}
+	}
+	if _, err := NewWindow("", nil); err == nil {
+		t.Error("expected an error without known releases")
+	}
+	if _, err := NewWindow("0.20", nil); err != nil {
+		t.Errorf("an explicit window needs no releases: %v", err)
+	}
+}
+
+func TestUpgradeTarget(t *testing.T) {
+	w, err := NewWindow("", releases)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"0.21.0": "0.21.3",
+		"0.22.1": "",
+		"0.19.7": "0.20.2",
+		"0.15.3": "0.20.2",
+		"dev":    "",
+	}
+	for version, want := range tests {
+		if got := w.UpgradeTarget(version); got != want {
+			t.Errorf("UpgradeTarget(%s) = %q, want %q", version, got, want)
+		}
+	}
+}
+
+func TestUpgradeTarget_SkipsMinorLines(t *testing.T) {
+	// 0.18 and 0.19 were never released and the window starts at 0.20,
+	// which has no release either: 0.17.4 upgrades to the newest 0.21.
+	w, err := NewWindow("0.20", []string{"0.22.0", "0.21.2", "0.21.1", "0.17.4", "0.16.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"0.17.4": "0.21.2",
+		"0.16.0": "0.21.2",
+		"0.21.1": "0.21.2",
+		"0.22.0": "",
+	}
+	for version, want := range tests {
+		if got := w.UpgradeTarget(version); got != want {
+			t.Errorf("UpgradeTarget(%s) = %q, want %q", version, got, want)
+		}
+	}
+}